@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/controller"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/gateway"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/presenter"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/trigger"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/usecase"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/metrics"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/service"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/tracing"
+)
+
+const (
+	// outboxDrainTimeout bounds how long the job waits for queued status events before exiting
+	outboxDrainTimeout = 30 * time.Second
+
+	// metricsPushTimeout bounds how long the job waits for the Pushgateway
+	metricsPushTimeout = 10 * time.Second
+
+	// tracingFlushTimeout bounds how long the job waits for the trace exporter
+	tracingFlushTimeout = 10 * time.Second
+)
+
+// application holds the wired layers shared by every run mode
+type application struct {
+	cfg             *config.Config
+	logger          logger.Logger
+	videoGateway    port.VideoGateway
+	videoController port.VideoController
+	// diagnosticsController serves the probe, extract, verify and version commands
+	diagnosticsController port.DiagnosticsController
+	fileManager           port.FileManager
+	// workspaces is the file manager, which also sweeps the workspaces left behind by crashed runs
+	workspaces     *service.LocalFileService
+	videoProcessor port.VideoProcessor
+	presenter      port.Presenter
+	// baseInput is the template of single-video requests; the key and the IDs vary per video
+	baseInput dto.ProcessVideoInput
+	// outbox is nil when the durable outbox is disabled
+	outbox       *datasource.OutboxMessageBroker
+	statusBroker port.MessageBroker
+	// metrics is nil for the diagnostic commands
+	metrics *metrics.Metrics
+}
+
+// newApplication wires the infrastructure, adapter and core layers from the configuration
+func newApplication(ctx context.Context, cfg *config.Config, logger logger.Logger) (*application, error) {
+	awsCfg, err := loadAWSConfig(ctx, cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	m := metrics.New()
+	app := newLocalApplication(cfg, logger, m)
+	app.metrics = m
+	app.videoProcessor = metrics.InstrumentVideoProcessor(app.videoProcessor, m)
+	app.sweepWorkspaces()
+	sourceCache, err := newSourceCache(cfg, logger, m)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Environment configuration loaded",
+		"video_bucket", cfg.Video.Bucket,
+		"processed_bucket", cfg.Video.ProcessedBucket)
+
+	// Initialize infrastructure layer
+	logger.Info("Initializing infrastructure layer")
+	storageDataSource := tracing.TraceStorageDataSource(newStorageDataSource(awsCfg, cfg, logger))
+	messageBroker, err := datasource.NewMessageBroker(datasource.BrokerConfig{
+		Kind:        cfg.Status.Broker,
+		URL:         cfg.Status.BrokerURL,
+		SNSEndpoint: cfg.AWS.SNSEndpoint,
+		SQSEndpoint: cfg.AWS.SQSEndpoint,
+	}, awsCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create status broker: %w", err)
+	}
+	logger.Info("Status broker configured", "broker", cfg.Status.Broker)
+	app.statusBroker = messageBroker
+	messageBroker = tracing.TraceMessageBroker(messageBroker, cfg.Status.Broker)
+
+	// Status events go through a durable outbox so they survive broker outages and restarts
+	if cfg.Outbox.Path != "" {
+		app.outbox, err = datasource.NewOutboxMessageBroker(messageBroker, datasource.OutboxConfig{
+			Path:        cfg.Outbox.Path,
+			MaxAttempts: cfg.Outbox.MaxAttempts,
+		}, logger)
+		if err != nil {
+			app.closeStatusBroker()
+			return nil, fmt.Errorf("failed to open status outbox: %w", err)
+		}
+		messageBroker = tracing.TraceMessageBroker(app.outbox, "outbox")
+	}
+
+	// Initialize adapter layer
+	logger.Info("Initializing adapter layer")
+	callbackNotifier := datasource.NewHTTPCallbackNotifier(nil, datasource.CallbackConfig{
+		AllowedHosts:  cfg.Callback.AllowedHosts,
+		InsecureHosts: cfg.Callback.InsecureHosts,
+		MaxAttempts:   cfg.Callback.MaxAttempts,
+	})
+	videoGateway := gateway.NewVideoGateway(storageDataSource, messageBroker, callbackNotifier, gateway.StatusEventConfig{
+		Format: cfg.Status.EventFormat,
+		Source: cfg.Status.EventSource,
+	})
+	app.videoGateway = metrics.InstrumentVideoGateway(videoGateway, m)
+
+	// Initialize core layer
+	logger.Info("Initializing core layer")
+	videoUseCase := metrics.InstrumentVideoUseCase(
+		usecase.NewVideoUseCase(app.videoGateway, app.videoProcessor, app.fileManager, sourceCache, logger), m)
+	batchUseCase := usecase.NewBatchUseCase(app.videoGateway, videoUseCase, logger)
+	app.videoController = controller.NewVideoController(videoUseCase, batchUseCase, app.presenter, logger)
+
+	// Every single-video request shares the processing settings
+	app.baseInput = dto.ProcessVideoInput{
+		IdFormat: cfg.Video.IdFormat,
+		Configuration: &dto.ProcessingConfigInput{
+			FrameRate:    cfg.Video.ExportFPS,
+			OutputFormat: cfg.Video.ExportFormat,
+		},
+		RetentionPolicy: cfg.Video.RetentionPolicy,
+	}
+	if cfg.Callback.URL != "" {
+		app.baseInput.Callback = &dto.CallbackInput{URL: cfg.Callback.URL, Secret: cfg.Callback.Secret}
+	}
+	return app, nil
+}
+
+// loadAWSConfig loads the AWS config; explicit keys take precedence over the default credential chain
+// (environment, web identity/IRSA, SSO, shared config, container and instance roles)
+func loadAWSConfig(ctx context.Context, cfg *config.Config, logger logger.Logger) (aws.Config, error) {
+	loadOptions := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(cfg.AWS.Region)}
+	credentialsSource := "default chain"
+	if cfg.HasStaticCredentials() {
+		loadOptions = append(loadOptions, awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			cfg.AWS.AccessKey,
+			cfg.AWS.SecretAccessKey,
+			cfg.AWS.SessionToken,
+		)))
+		credentialsSource = "static keys"
+	}
+	logger.Info("Loading AWS configuration", "region", cfg.AWS.Region, "credentials", credentialsSource)
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, loadOptions...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return awsCfg, nil
+}
+
+// newStorageDataSource creates the data source of the video and processed buckets
+func newStorageDataSource(awsCfg aws.Config, cfg *config.Config, logger logger.Logger) port.StorageDataSource {
+	s3Client := datasource.NewS3Client(awsCfg, cfg.AWS.S3Endpoint, cfg.AWS.S3ForcePathStyle)
+	if cfg.AWS.S3Endpoint != "" {
+		logger.Info("Using S3 endpoint override", "endpoint", cfg.AWS.S3Endpoint, "path_style", cfg.AWS.S3ForcePathStyle)
+	}
+	return datasource.NewS3StorageDataSource(s3Client, cfg.Video.Bucket, cfg.Video.ProcessedBucket)
+}
+
+// newSourceCache creates the cache of downloaded videos; it is nil when no cache directory is configured
+func newSourceCache(cfg *config.Config, logger logger.Logger, m *metrics.Metrics) (port.SourceCache, error) {
+	if cfg.SourceCache.Dir == "" {
+		return nil, nil
+	}
+	cache, err := service.NewFileSourceCache(cfg.SourceCache.Dir, cfg.Video.Bucket, cfg.SourceCache.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Source cache enabled", "dir", cfg.SourceCache.Dir, "max_bytes", cfg.SourceCache.MaxBytes)
+	return metrics.InstrumentSourceCache(cache, m), nil
+}
+
+// newLocalApplication wires the layers that run without cloud access: the local files, ffmpeg and the presenter.
+// A nil m leaves the ffmpeg processes unobserved.
+func newLocalApplication(cfg *config.Config, logger logger.Logger, m *metrics.Metrics) *application {
+	fileManager := service.NewLocalFileService(cfg.Workspace.Dir)
+	ffmpegConfig := service.FFmpegConfig{
+		SegmentConcurrency: cfg.FFmpeg.SegmentConcurrency,
+		MinSegmentDuration: cfg.FFmpeg.MinSegmentDuration,
+	}
+	if m != nil {
+		ffmpegConfig.Observer = m
+	}
+	return &application{
+		cfg:            cfg,
+		logger:         logger,
+		fileManager:    fileManager,
+		workspaces:     fileManager,
+		videoProcessor: service.NewFFmpegService(fileManager, ffmpegConfig),
+		presenter:      presenter.NewVideoJsonPresenter(),
+	}
+}
+
+// newDiagnosticsApplication wires the diagnostic commands. They run locally unless withStorage is set, which adds
+// the video bucket; nothing is published, so no status broker is created.
+func newDiagnosticsApplication(ctx context.Context, cfg *config.Config, logger logger.Logger, withStorage bool) (*application, error) {
+	app := newLocalApplication(cfg, logger, nil)
+	if withStorage {
+		awsCfg, err := loadAWSConfig(ctx, cfg, logger)
+		if err != nil {
+			return nil, err
+		}
+		app.videoGateway = gateway.NewVideoGateway(newStorageDataSource(awsCfg, cfg, logger), nil, nil, gateway.StatusEventConfig{})
+	}
+
+	diagnosticsUseCase := usecase.NewDiagnosticsUseCase(app.videoGateway, app.videoProcessor, app.fileManager, logger)
+	app.diagnosticsController = controller.NewDiagnosticsController(diagnosticsUseCase, app.presenter, logger)
+	return app, nil
+}
+
+// newS3EventTrigger creates the trigger processing S3 notifications; a nil maxObjectSize is unlimited
+func (app *application) newS3EventTrigger(maxObjectSize func() int64) (*trigger.S3EventTrigger, error) {
+	triggerConfig := trigger.S3EventTriggerConfig{
+		Bucket:        app.cfg.Video.Bucket,
+		MaxObjectSize: maxObjectSize,
+		Input:         app.baseInput,
+	}
+	pattern, err := app.keyPattern()
+	if err != nil {
+		return nil, err
+	}
+	triggerConfig.KeyPattern = pattern
+	return trigger.NewS3EventTrigger(app.videoController, app.videoGateway, triggerConfig, app.logger), nil
+}
+
+// keyPattern compiles the pattern extracting the IDs from object keys; it is nil when none is configured
+func (app *application) keyPattern() (*regexp.Regexp, error) {
+	if app.cfg.Trigger.KeyPattern == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(app.cfg.Trigger.KeyPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3_EVENT_KEY_PATTERN: %w", err)
+	}
+	return pattern, nil
+}
+
+// sweepWorkspaces removes the workspaces left behind by runs that crashed; failures only leave them in place
+func (app *application) sweepWorkspaces() {
+	removed, err := app.workspaces.SweepOrphans()
+	if err != nil {
+		app.logger.Warn("Failed to remove orphaned workspaces", "error", err)
+	}
+	if removed > 0 {
+		app.logger.Info("Orphaned workspaces removed", "count", removed, "root", app.workspaces.Root())
+	}
+}
+
+// flush waits for the queued status events, keeping the outbox open for the next run
+func (app *application) flush(ctx context.Context) {
+	if app.outbox == nil {
+		return
+	}
+	if err := app.outbox.Flush(ctx); err != nil {
+		app.logger.Warn("Status events still pending in the outbox", "error", err)
+	}
+}
+
+// pushMetrics replaces the metrics of this instance on the Pushgateway, when one is configured
+func (app *application) pushMetrics(ctx context.Context) {
+	if app.metrics == nil || app.cfg.Metrics.PushgatewayURL == "" {
+		return
+	}
+	instance, _ := os.Hostname()
+	pushCtx, cancel := context.WithTimeout(ctx, metricsPushTimeout)
+	defer cancel()
+	err := app.metrics.Push(pushCtx, metrics.PushConfig{
+		URL:      app.cfg.Metrics.PushgatewayURL,
+		Job:      app.cfg.Metrics.PushJob,
+		Grouping: map[string]string{"instance": instance},
+	})
+	if err != nil {
+		app.logger.Warn("Failed to push metrics", "error", err)
+	}
+}
+
+// serveMetrics exposes /metrics on the configured address until the returned function is called
+func (app *application) serveMetrics() func(context.Context) {
+	if app.metrics == nil || app.cfg.Metrics.ListenAddress == "" {
+		return func(context.Context) {}
+	}
+	server := app.metrics.NewServer(app.cfg.Metrics.ListenAddress)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			app.logger.Warn("Metrics server stopped", "error", err)
+		}
+	}()
+	app.logger.Info("Serving metrics", "address", app.cfg.Metrics.ListenAddress)
+	return func(ctx context.Context) {
+		if err := server.Shutdown(ctx); err != nil {
+			app.logger.Warn("Failed to stop metrics server", "error", err)
+		}
+	}
+}
+
+// newLogger creates the logger of the log settings writing to w, scrubbing the configured secrets; settings the
+// configuration rejected fall back to the defaults
+func newLogger(cfg *config.Config, w io.Writer) logger.Logger {
+	l, err := logger.New(logger.Options{
+		Level:    cfg.Log.Level,
+		Format:   cfg.Log.Format,
+		Writer:   w,
+		Redactor: logger.NewRedactor(cfg.SecretValues()...),
+	})
+	if err != nil {
+		return logger.NewSlogLoggerWithWriter(w)
+	}
+	return l
+}
+
+// setupTracing installs the tracer provider exporting the spans as configured; w receives the spans of the stdout
+// exporter
+func setupTracing(ctx context.Context, cfg *config.Config, w io.Writer) (*tracing.Provider, error) {
+	return tracing.Setup(ctx, tracing.Config{
+		Exporter:       cfg.Tracing.Exporter,
+		Endpoint:       cfg.Tracing.Endpoint,
+		File:           cfg.Tracing.File,
+		Writer:         w,
+		ServiceName:    cfg.Tracing.ServiceName,
+		ServiceVersion: buildInfo().Version,
+	})
+}
+
+// shutdownTracing exports the pending spans before the job exits
+func shutdownTracing(ctx context.Context, provider *tracing.Provider, logger logger.Logger) {
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), tracingFlushTimeout)
+	defer cancel()
+	if err := provider.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("Failed to export traces", "error", err)
+	}
+}
+
+// close drains the outbox, closes the status broker and pushes the final metrics. Status events left undelivered
+// fail the job as retryable, so the next run replays them.
+func (app *application) close(ctx context.Context) error {
+	var err error
+	if app.outbox != nil {
+		drainCtx, cancel := context.WithTimeout(ctx, outboxDrainTimeout)
+		defer cancel()
+		if err = app.outbox.Close(drainCtx); err != nil {
+			app.logger.Error("Status outbox closed with pending events", "error", err)
+			err = domain.NewRetryableError(fmt.Errorf("status events left undelivered: %w", err))
+		}
+	}
+	app.closeStatusBroker()
+	app.pushMetrics(ctx)
+	return err
+}
+
+func (app *application) closeStatusBroker() {
+	if closer, ok := app.statusBroker.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			app.logger.Warn("Failed to close status broker", "error", err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/datasource"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+// unreachableBroker fails every publish
+type unreachableBroker struct{}
+
+func (unreachableBroker) PublishMessage(context.Context, entity.Message) error {
+	return errors.New("broker unreachable")
+}
+
+func TestApplicationClose(t *testing.T) {
+	open := func(t *testing.T) *application {
+		outbox, err := datasource.NewOutboxMessageBroker(unreachableBroker{}, datasource.OutboxConfig{
+			Path:        filepath.Join(t.TempDir(), "outbox.jsonl"),
+			MaxAttempts: 1,
+			Backoff:     time.Millisecond,
+		}, logger.NewSlogLogger())
+		require.NoError(t, err)
+		return &application{logger: logger.NewSlogLogger(), outbox: outbox, statusBroker: outbox}
+	}
+
+	t.Run("without_pending_events", func(t *testing.T) {
+		require.NoError(t, open(t).close(context.Background()))
+	})
+
+	t.Run("undelivered_events_are_retryable", func(t *testing.T) {
+		r := require.New(t)
+		app := open(t)
+		r.NoError(app.outbox.PublishMessage(context.Background(), entity.Message{ID: "a", Body: []byte(`{}`)}))
+
+		err := app.close(context.Background())
+		r.ErrorContains(err, "left undelivered")
+		r.Equal(exitRetryable, exitCode(err))
+	})
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/config"
+)
+
+// runConfigCommand runs "config print", which writes the effective configuration with the secrets redacted.
+// It accepts the same flags as the job, so the output reflects the defaults, the config file, the environment
+// and the flags; validation problems are reported after the configuration.
+func runConfigCommand(args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		log.Fatalf("Usage: video-processor-job config print [--output yaml|json] [flags]")
+	}
+
+	fs := flag.NewFlagSet("video-processor-job config print", flag.ContinueOnError)
+	output := fs.String("output", "yaml", "output format: yaml or json")
+	cfg, err := config.Load(fs, args[1:])
+	if cfg == nil {
+		exitOnFlagError(err)
+	}
+	if pErr := cfg.Print(os.Stdout, *output); pErr != nil {
+		log.Fatalf("Failed to print configuration: %v", pErr)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+}
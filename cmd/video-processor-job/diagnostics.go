@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/tracing"
+)
+
+// Build information, set with -ldflags "-X main.version=... -X main.commit=... -X main.buildDate=..."
+var (
+	version   = "dev"
+	commit    = ""
+	buildDate = ""
+)
+
+// runProbe runs "probe <file|key>", which prints the ffprobe metadata of a local file or, when no such file exists,
+// of a key in the video bucket
+func runProbe(args []string) {
+	fs := flag.NewFlagSet("video-processor-job probe", flag.ContinueOnError)
+	cfg, arguments := loadDiagnostics(fs, args, "<file|key>")
+
+	source := arguments[0]
+	_, statErr := os.Stat(source)
+	runDiagnostics(cfg, "probe", statErr != nil, func(ctx context.Context, c port.DiagnosticsController) ([]byte, error) {
+		return c.Probe(ctx, dto.ProbeInput{Source: source})
+	})
+}
+
+// runExtract runs "extract <file>", which extracts the frames of a local video into a zip without any cloud service
+func runExtract(args []string) {
+	fs := flag.NewFlagSet("video-processor-job extract", flag.ContinueOnError)
+	fps := fs.Float64("fps", 0, "frames extracted per second (default: video.export-fps)")
+	format := fs.String("format", "", "frame format: jpg or png (default: video.export-format)")
+	out := fs.String("out", "", "path of the frame archive (default: <video>_frames.zip)")
+	width := fs.Int("width", 0, "frame width in pixels; 0 keeps the source size or the aspect ratio")
+	height := fs.Int("height", 0, "frame height in pixels; 0 keeps the source size or the aspect ratio")
+	quality := fs.Int("jpeg-quality", 0, "jpg quality from 2 (best) to 31; 0 uses the default")
+	start := fs.Float64("start", 0, "first second extracted")
+	end := fs.Float64("end", 0, "last second extracted; 0 extracts until the end")
+	cfg, arguments := loadDiagnostics(fs, args, "<file>")
+
+	configuration := &dto.ProcessingConfigInput{
+		FrameRate:    cfg.Video.ExportFPS,
+		OutputFormat: cfg.Video.ExportFormat,
+		Width:        *width,
+		Height:       *height,
+		JPEGQuality:  *quality,
+		StartTime:    *start,
+		EndTime:      *end,
+	}
+	if *fps != 0 {
+		configuration.FrameRate = *fps
+	}
+	if *format != "" {
+		configuration.OutputFormat = *format
+	}
+	runDiagnostics(cfg, "extract", false, func(ctx context.Context, c port.DiagnosticsController) ([]byte, error) {
+		return c.Extract(ctx, dto.ExtractInput{VideoPath: arguments[0], OutputPath: *out, Configuration: configuration})
+	})
+}
+
+// runVerify runs "verify <zip>", which checks that every frame of an archive is intact, decodable and numbered
+// without gaps; an invalid archive exits with a non-zero status
+func runVerify(args []string) {
+	fs := flag.NewFlagSet("video-processor-job verify", flag.ContinueOnError)
+	cfg, arguments := loadDiagnostics(fs, args, "<zip>")
+
+	runDiagnostics(cfg, "verify", false, func(ctx context.Context, c port.DiagnosticsController) ([]byte, error) {
+		return c.Verify(ctx, dto.VerifyInput{ArchivePath: arguments[0]})
+	})
+}
+
+// runVersion runs "version", which prints the build information and the detected ffmpeg capabilities
+func runVersion(args []string) {
+	fs := flag.NewFlagSet("video-processor-job version", flag.ContinueOnError)
+	cfg, _ := loadDiagnostics(fs, args, "")
+
+	runDiagnostics(cfg, "version", false, func(ctx context.Context, c port.DiagnosticsController) ([]byte, error) {
+		return c.Version(ctx, buildInfo())
+	})
+}
+
+// loadDiagnostics loads the configuration of a diagnostic command and returns its positional arguments, exiting
+// with the usage when they do not match argsUsage (one argument, or none when empty)
+func loadDiagnostics(fs *flag.FlagSet, args []string, argsUsage string) (*config.Config, []string) {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s [flags] %s\n", fs.Name(), argsUsage)
+		fs.PrintDefaults()
+	}
+	cfg, err := config.LoadDiagnostics(fs, args)
+	if cfg == nil {
+		exitOnFlagError(err)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	wanted := 1
+	if argsUsage == "" {
+		wanted = 0
+	}
+	if fs.NArg() != wanted {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] %s\n", fs.Name(), argsUsage)
+		os.Exit(exitUsage)
+	}
+	return cfg, fs.Args()
+}
+
+// runDiagnostics wires the application and prints the JSON response of run under a span named after the command;
+// logs and the spans of the stdout exporter go to stderr so stdout only carries the response. A failed run exits
+// with the code of its error class after printing its error response.
+func runDiagnostics(cfg *config.Config, name string, withStorage bool, run func(ctx context.Context, c port.DiagnosticsController) ([]byte, error)) {
+	ctx := context.Background()
+	provider, err := setupTracing(ctx, cfg, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to set up tracing: %v\n", err)
+		os.Exit(exitCode(err))
+	}
+
+	// The logger adds the trace of the span to every record
+	ctx, span := tracing.Start(ctx, name)
+	logger := newLogger(cfg, os.Stderr).WithContext(ctx)
+
+	result, err := diagnose(ctx, cfg, logger, withStorage, run)
+	tracing.End(span, err)
+	shutdownTracing(ctx, provider, logger)
+
+	if result != nil {
+		fmt.Println(string(result))
+	}
+	if err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// diagnose wires the diagnostics application and runs run
+func diagnose(ctx context.Context, cfg *config.Config, logger logger.Logger, withStorage bool, run func(ctx context.Context, c port.DiagnosticsController) ([]byte, error)) ([]byte, error) {
+	app, err := newDiagnosticsApplication(ctx, cfg, logger, withStorage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize application: %v\n", err)
+		return nil, domain.NewInternalError(err)
+	}
+	return run(ctx, app.diagnosticsController)
+}
+
+// buildInfo returns the version set at link time, falling back to the module and VCS information Go embeds
+func buildInfo() dto.VersionInput {
+	info := dto.VersionInput{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+			info.Version = strings.TrimPrefix(build.Main.Version, "v")
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			}
+		}
+	}
+	return info
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/presenter"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/config"
+)
+
+// Exit codes of the process command, so a Kubernetes Job pod failure policy can fail fast on permanent errors and
+// only spend its backoffLimit on transient ones. 2 is kept for command line usage errors.
+const (
+	exitSuccess      = 0
+	exitInternal     = 1
+	exitUsage        = 2
+	exitInvalidInput = 3
+	exitNotFound     = 4
+	exitRetryable    = 5
+)
+
+// exitCode maps err to the exit code of its class; a transient failure wins over the others, as a new run may succeed
+func exitCode(err error) int {
+	if err == nil {
+		return exitSuccess
+	}
+	if domain.IsRetryable(err) {
+		return exitRetryable
+	}
+	switch domain.CodeOf(err) {
+	case domain.ErrorCodeInvalidInput, domain.ErrorCodeValidation:
+		return exitInvalidInput
+	case domain.ErrorCodeNotFound:
+		return exitNotFound
+	default:
+		return exitInternal
+	}
+}
+
+// finish prints the JSON result on stdout, writes its summary as the termination message and exits with the code of
+// err. Without a result, err is presented as the result.
+func finish(cfg *config.Config, result []byte, err error) {
+	if result == nil && err != nil {
+		result, _ = presenter.NewVideoJsonPresenter().PresentError(err)
+	}
+	if result != nil {
+		fmt.Println(string(result))
+		if cfg != nil {
+			writeTerminationMessage(cfg.Termination.MessagePath, terminationSummary(result, err))
+		}
+	}
+	os.Exit(exitCode(err))
+}
+
+// terminationResult holds the fields of a result, or of the items of an S3 event result, read by terminationSummary
+type terminationResult struct {
+	Success   bool   `json:"success"`
+	ErrorCode string `json:"error_code"`
+	ReportKey string `json:"report_key"`
+	Total     *int   `json:"total"`
+	Succeeded int    `json:"succeeded"`
+	Failed    int    `json:"failed"`
+}
+
+// terminationCounts counts the videos of a batch or an S3 event
+type terminationCounts struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// terminationMessage is the summary of a result written as the termination message
+type terminationMessage struct {
+	Success   bool   `json:"success"`
+	ErrorCode string `json:"error_code,omitempty"`
+	*terminationCounts
+	ReportKey string `json:"report_key,omitempty"`
+}
+
+// terminationSummary returns the summary of result written as the termination message, which Kubernetes truncates
+// to 4096 bytes while the result of a batch or an S3 event lists every video: the outcome, the error class, the
+// counts of a batch or an S3 event and the report key of a single result
+func terminationSummary(result []byte, err error) []byte {
+	var items []terminationResult
+	if json.Unmarshal(result, &items) != nil {
+		var single terminationResult
+		if json.Unmarshal(result, &single) == nil {
+			items = []terminationResult{single}
+		}
+	}
+
+	summary := terminationMessage{Success: err == nil, ErrorCode: string(domain.CodeOf(err))}
+	for _, item := range items {
+		summary.Success = summary.Success && item.Success
+		if summary.ErrorCode == "" {
+			summary.ErrorCode = item.ErrorCode
+		}
+	}
+	if len(items) == 1 {
+		summary.ReportKey = items[0].ReportKey
+	}
+	if len(items) > 1 || len(items) == 1 && items[0].Total != nil {
+		counts := &terminationCounts{}
+		for _, item := range items {
+			switch {
+			case item.Total != nil:
+				counts.Total += *item.Total
+				counts.Succeeded += item.Succeeded
+				counts.Failed += item.Failed
+			case item.Success:
+				counts.Total++
+				counts.Succeeded++
+			default:
+				counts.Total++
+				counts.Failed++
+			}
+		}
+		summary.terminationCounts = counts
+	}
+
+	b, _ := json.Marshal(summary)
+	return b
+}
+
+// writeTerminationMessage replaces the content of the termination message file, which Kubernetes creates in the
+// container and reports in the pod status. Outside Kubernetes the file does not exist and nothing is written.
+func writeTerminationMessage(path string, message []byte) {
+	if path == "" {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err == nil {
+		_, err = f.Write(message)
+		if cErr := f.Close(); err == nil {
+			err = cErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write termination message to %s: %v\n", path, err)
+	}
+}
+
+// joinResults presents the results of an S3 event, one per object, as a single JSON array
+func joinResults(results [][]byte) []byte {
+	messages := make([]json.RawMessage, 0, len(results))
+	for _, result := range results {
+		messages = append(messages, result)
+	}
+	b, _ := json.Marshal(messages)
+	return b
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/config"
+)
+
+func TestExitCode(t *testing.T) {
+	cases := map[string]struct {
+		err  error
+		code int
+	}{
+		"success":        {nil, exitSuccess},
+		"invalid_input":  {domain.NewInvalidInputError("bad spec"), exitInvalidInput},
+		"invalid_video":  {domain.NewValidationError(errors.New("not a video")), exitInvalidInput},
+		"invalid_config": {&config.ConfigValidationError{MissingFields: []string{"video.key"}}, exitInvalidInput},
+		"not_found":      {domain.NewNotFoundError(domain.ErrNotFound), exitNotFound},
+		"retryable":      {domain.NewRetryableError(errors.New("timeout")), exitRetryable},
+		"internal":       {domain.NewInternalError(errors.New("ffmpeg crashed")), exitInternal},
+		"unclassified":   {errors.New("boom"), exitInternal},
+		"joined_retryable_wins": {
+			errors.Join(
+				fmt.Errorf("s3://b/a.mp4: %w", domain.NewInvalidInputError("bad key")),
+				fmt.Errorf("s3://b/b.mp4: %w", domain.NewRetryableError(errors.New("timeout"))),
+			),
+			exitRetryable,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.code, exitCode(tc.err))
+		})
+	}
+}
+
+func TestWriteTerminationMessage(t *testing.T) {
+	t.Run("replaces_existing_file", func(t *testing.T) {
+		r := require.New(t)
+		path := filepath.Join(t.TempDir(), "termination-log")
+		r.NoError(os.WriteFile(path, []byte("previous run with a longer message"), 0o644))
+
+		writeTerminationMessage(path, []byte(`{"success":true}`))
+
+		b, err := os.ReadFile(path)
+		r.NoError(err)
+		r.Equal(`{"success":true}`, string(b))
+	})
+
+	t.Run("missing_file_is_not_created", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "termination-log")
+		writeTerminationMessage(path, []byte(`{"success":true}`))
+		_, err := os.Stat(path)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestTerminationSummary(t *testing.T) {
+	t.Run("single_video", func(t *testing.T) {
+		result := []byte(`{"success":true,"message":"ok","output_key":"processed/a.zip","frame_count":3,"report_key":"processed/a.report.json"}`)
+		require.JSONEq(t, `{"success":true,"report_key":"processed/a.report.json"}`, string(terminationSummary(result, nil)))
+	})
+
+	t.Run("failed_video", func(t *testing.T) {
+		result := []byte(`{"success":false,"message":"failed","error":"not a video","error_code":"VALIDATION_ERROR","stage":"validate"}`)
+		err := domain.NewValidationError(errors.New("not a video"))
+		require.JSONEq(t, `{"success":false,"error_code":"VALIDATION_ERROR"}`, string(terminationSummary(result, err)))
+	})
+
+	t.Run("batch_keeps_the_counts_only", func(t *testing.T) {
+		r := require.New(t)
+		items := make([]string, 0, 200)
+		for i := range 200 {
+			items = append(items, fmt.Sprintf(`{"video_key":"videos/%03d-with-a-rather-long-name.mp4","success":true,"output_key":"processed/%03d.zip","duration_ms":1}`, i, i))
+		}
+		result := []byte(`{"success":false,"total":201,"succeeded":200,"failed":1,"report_key":"reports/batch-1.json","results":[` +
+			strings.Join(items, ",") + `]}`)
+		r.Greater(len(result), 4096)
+
+		summary := terminationSummary(result, domain.NewNotFoundError(domain.ErrNotFound))
+		r.JSONEq(`{"success":false,"error_code":"NOT_FOUND","total":201,"succeeded":200,"failed":1,"report_key":"reports/batch-1.json"}`, string(summary))
+	})
+
+	t.Run("s3_event_counts_its_objects", func(t *testing.T) {
+		result := []byte(`[{"success":true,"report_key":"processed/a.report.json"},{"success":false,"error_code":"NOT_FOUND"}]`)
+		require.JSONEq(t, `{"success":false,"error_code":"NOT_FOUND","total":2,"succeeded":1,"failed":1}`, string(terminationSummary(result, nil)))
+	})
+}
+
+func TestJoinResults(t *testing.T) {
+	b := joinResults([][]byte{[]byte(`{"success":true}`), []byte(`{"success":false}`)})
+	require.JSONEq(t, `[{"success":true},{"success":false}]`, string(b))
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/trigger"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/config"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/lambda"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/service"
+)
+
+// lambdaTempSpaceFactor is the temp space a video needs relative to its size: the download, the frames and the archive
+const lambdaTempSpaceFactor = 3
+
+// runLambda serves Lambda invocations through the Runtime API until the execution environment is shut down.
+// Each invocation is a direct request ({"video_key", "video_id", "user_id"}) or an S3 notification.
+// A configuration error (cfgErr) is reported to Lambda as an init error.
+func runLambda(cfg *config.Config, cfgErr error) {
+	ctx := context.Background()
+	logger := newLogger(cfg, os.Stdout).With("function", os.Getenv("AWS_LAMBDA_FUNCTION_NAME"))
+	logger.Info("Starting Video Processor Lambda runtime")
+	runtime := lambda.NewRuntime(cfg.Lambda.RuntimeAPI, nil, logger)
+
+	initFailed := func(err error) {
+		logger.Error("Failed to initialize Lambda runtime", "error", err)
+		if rErr := runtime.InitError(ctx, err); rErr != nil {
+			logger.Error("Failed to report init error", "error", rErr)
+		}
+		log.Fatalf("Failed to initialize Lambda runtime: %v", err)
+	}
+
+	if cfgErr != nil {
+		initFailed(fmt.Errorf("configuration error: %w", cfgErr))
+	}
+	provider, err := setupTracing(ctx, cfg, os.Stdout)
+	if err != nil {
+		initFailed(fmt.Errorf("failed to set up tracing: %w", err))
+	}
+	app, err := newApplication(ctx, cfg, logger)
+	if err != nil {
+		initFailed(err)
+	}
+
+	// /tmp is the only writable space and is bounded by the function's ephemeral storage: reject videos that
+	// cannot fit instead of failing midway. The limit is measured per invocation, as /tmp persists across the
+	// invocations of a warm environment.
+	var maxObjectSize int64
+	s3Trigger, err := app.newS3EventTrigger(func() int64 { return maxObjectSize })
+	if err != nil {
+		_ = app.close(ctx)
+		initFailed(err)
+	}
+	handler := trigger.NewLambdaHandler(s3Trigger)
+	stopMetrics := app.serveMetrics()
+
+	err = runtime.Run(ctx, func(ctx context.Context, payload []byte) ([]byte, error) {
+		// A previous invocation that timed out or crashed leaves its workspace behind: reclaim it before measuring
+		app.sweepWorkspaces()
+		maxObjectSize = app.maxObjectSize(ctx)
+		response, err := handler.Handle(ctx, payload)
+		// The environment may be frozen or discarded once the response is posted: deliver status events first
+		app.flush(ctx)
+		app.pushMetrics(ctx)
+		flushCtx, cancel := context.WithTimeout(ctx, tracingFlushTimeout)
+		defer cancel()
+		if fErr := provider.Flush(flushCtx); fErr != nil {
+			logger.Warn("Failed to export traces", "error", fErr)
+		}
+		return response, err
+	})
+	stopMetrics(ctx)
+	if cErr := app.close(ctx); cErr != nil {
+		logger.Warn("Status events left for the next cold start", "error", cErr)
+	}
+	shutdownTracing(ctx, provider, logger)
+	if err != nil {
+		logger.Error("Lambda runtime stopped", "error", err)
+		log.Fatalf("Lambda runtime stopped: %v", err)
+	}
+}
+
+// maxObjectSize returns the size of the largest video the available temp space can process, 0 when it is unknown
+func (app *application) maxObjectSize(ctx context.Context) int64 {
+	log := app.logger.WithContext(ctx)
+	available, err := service.AvailableSpace(app.workspaces.Root())
+	if err != nil {
+		log.Warn("Failed to read available temp space, video size is not limited", "error", err)
+		return 0
+	}
+	maxObjectSize := available / lambdaTempSpaceFactor
+	log.Info("Temp space available", "bytes", available, "max_video_bytes", maxObjectSize)
+	return maxObjectSize
+}
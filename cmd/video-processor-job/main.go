@@ -2,120 +2,246 @@ package main
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"os"
+	"strings"
 
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/sns"
-
-	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/controller"
-	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/gateway"
-	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/presenter"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/trigger"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
-	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/usecase"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/config"
-	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/datasource"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
-	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/service"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/tracing"
 )
 
+// usage lists the commands; process is the default when the first argument is a flag or missing
+const usage = `Usage: video-processor-job [command] [flags] [arguments]
+
+Commands:
+  process              process the configured video, batch, S3 event or job spec (default)
+  probe <file|key>     print the ffprobe metadata of a local file or of a key in VIDEO_BUCKET
+  extract <file>       extract the frames of a local video into a zip, without any cloud service
+  verify <zip>         check the integrity of a frame archive
+  version              print the build information and the detected ffmpeg capabilities
+  config print         print the effective configuration with the secrets redacted
+
+Run "video-processor-job <command> -h" for the flags of a command.`
+
 func main() {
-	// Load configuration
-	cfg := config.LoadConfig()
+	args := os.Args[1:]
+	command := "process"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "process":
+		runProcess(args)
+	case "probe":
+		runProbe(args)
+	case "extract":
+		runExtract(args)
+	case "verify":
+		runVerify(args)
+	case "version":
+		runVersion(args)
+	case "config":
+		runConfigCommand(args)
+	case "help":
+		fmt.Println(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s\n", command, usage)
+		os.Exit(exitUsage)
+	}
+}
+
+// runProcess runs the job: a single video, a batch, an S3 event, a job spec or the Lambda runtime
+func runProcess(args []string) {
+	// Load configuration: defaults, config file, environment, then flags
+	fs := flag.NewFlagSet("video-processor-job process", flag.ContinueOnError)
+	cfg, err := config.Load(fs, args)
+	if cfg == nil {
+		exitOnFlagError(err)
+	}
+	if err == nil && fs.NArg() > 0 {
+		err = domain.NewInvalidInputError("unexpected arguments: " + strings.Join(fs.Args(), " "))
+	}
 
-	// Validate required fields
-	if err := cfg.ValidateRequiredFields(); err != nil {
-		log.Fatalf("Configuration error: %v", err)
+	// Lambda reports configuration and wiring failures through the Runtime API
+	if cfg.IsLambda() {
+		runLambda(cfg, err)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		finish(cfg, nil, err)
 	}
 
-	// Initialize context with trace id, then logger
+	// The job spec is read first, as its traceparent, like TRACEPARENT, names the trace the job continues
 	ctx := context.Background()
-	traceID, err := generateTraceID()
+	var spec []byte
+	var specErr error
+	traceParent := cfg.Tracing.TraceParent
+	if cfg.HasJobSpec() {
+		spec, specErr = readJobSpec(cfg)
+		if tp := jobSpecTraceParent(spec); tp != "" {
+			traceParent = tp
+		}
+	}
+
+	provider, err := setupTracing(ctx, cfg, os.Stdout)
 	if err != nil {
-		log.Fatalf("Failed to generate trace ID: %v", err)
+		fmt.Fprintf(os.Stderr, "Failed to set up tracing: %v\n", err)
+		finish(cfg, nil, err)
+	}
+
+	// Initialize the root span, then the logger adding its trace to every record
+	ctx, span := tracing.Start(tracing.WithTraceParent(ctx, traceParent), "process")
+	if cfg.Job.ID != "" {
+		ctx = logger.WithAttributes(ctx, "job_id", cfg.Job.ID)
 	}
-	ctx = logger.SetTraceIDOnContext(ctx, traceID)
 
-	logger := logger.NewSlogLogger().With("trace_id", traceID)
+	logger := newLogger(cfg, os.Stdout).WithContext(ctx)
 	logger.Info("Starting Video Processor standalone application")
 
-	// Initialize AWS config with explicit credentials
-	logger.Info("Loading AWS configuration", "region", cfg.AWS.Region)
-	awsCfg, err := awsconfig.LoadDefaultConfig(ctx,
-		awsconfig.WithRegion(cfg.AWS.Region),
-		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
-			cfg.AWS.AccessKey,
-			cfg.AWS.SecretAccessKey,
-			cfg.AWS.SessionToken,
-		)),
-	)
+	result, err := process(ctx, cfg, logger, spec, specErr)
+	tracing.End(span, err)
+	shutdownTracing(ctx, provider, logger)
+	finish(cfg, result, err)
+}
+
+// process runs the configured S3 event, batch or single video and returns its JSON result
+func process(ctx context.Context, cfg *config.Config, logger logger.Logger, spec []byte, specErr error) (_ []byte, err error) {
+	app, err := newApplication(ctx, cfg, logger)
 	if err != nil {
-		logger.Error("Failed to load AWS config", "error", err)
-		log.Fatalf("Failed to load AWS config: %v", err)
-	}
-
-	// Initialize S3 client
-	s3Client := s3.NewFromConfig(awsCfg)
-
-	logger.Info("Environment configuration loaded",
-		"video_bucket", cfg.Video.Bucket,
-		"processed_bucket", cfg.Video.ProcessedBucket)
-
-	// Initialize infrastructure layer
-	logger.Info("Initializing infrastructure layer")
-	storageDataSource := datasource.NewS3StorageDataSource(s3Client, cfg.Video.Bucket, cfg.Video.ProcessedBucket)
-	fileManager := service.NewLocalFileService()
-	videoProcessor := service.NewFFmpegService(fileManager)
-	snsClient := sns.NewFromConfig(awsCfg)
-	snsDataSource := datasource.NewSnsMessageBroker(snsClient, cfg.Video.SnsTopic)
-
-	// Initialize adapter layer
-	logger.Info("Initializing adapter layer")
-	videoGateway := gateway.NewVideoGateway(storageDataSource, snsDataSource)
-	videoPresenter := presenter.NewVideoJsonPresenter()
-
-	// Initialize core layer
-	logger.Info("Initializing core layer")
-	videoUseCase := usecase.NewVideoUseCase(videoGateway, videoProcessor, fileManager, logger)
-	videoController := controller.NewVideoController(videoUseCase, videoPresenter, logger)
-
-	// Create processing input using DTOs
-	logger.Info("Processing video",
-		"key", cfg.Video.Key,
-		"format", cfg.Video.ExportFormat,
-		"fps", cfg.Video.ExportFPS)
-
-	input := dto.ProcessVideoInput{
-		VideoKey: cfg.Video.Key,
-		VideoId:  cfg.Video.Id,
-		UserId:   cfg.Video.UserId,
-		Configuration: &dto.ProcessingConfigInput{
-			FrameRate:    cfg.Video.ExportFPS,
-			OutputFormat: cfg.Video.ExportFormat,
-		},
-	}
-
-	// Process the video
-	result, err := videoController.ProcessVideo(ctx, input)
+		logger.Error("Failed to initialize application", "error", err)
+		return nil, err
+	}
+	defer func() {
+		if cErr := app.close(ctx); cErr != nil {
+			err = errors.Join(err, cErr)
+		}
+	}()
+
+	if cfg.IsTriggered() {
+		event, err := readTriggerEvent(cfg)
+		if err != nil {
+			logger.Error("Failed to read S3 event", "error", err)
+			return nil, domain.NewInvalidInputError(fmt.Sprintf("failed to read S3 event: %v", err))
+		}
+		s3Trigger, err := app.newS3EventTrigger(nil)
+		if err != nil {
+			logger.Error("Configuration error", "error", err)
+			return nil, err
+		}
+
+		results, err := s3Trigger.Handle(ctx, event)
+		if err != nil {
+			logger.Error("S3 event processing finished with failures", "error", err)
+		}
+		var result []byte
+		if results != nil {
+			result = joinResults(results)
+		}
+		return result, err
+	}
+
+	if cfg.IsBatch() {
+		logger.Info("Processing batch",
+			"keys", len(cfg.Batch.Keys),
+			"prefix", cfg.Batch.Prefix,
+			"concurrency", cfg.Batch.Concurrency)
+
+		keyPattern, err := app.keyPattern()
+		if err != nil {
+			logger.Error("Configuration error", "error", err)
+			return nil, err
+		}
+		batchInput := dto.ProcessBatchInput{
+			VideoKeys:       cfg.Batch.Keys,
+			Prefix:          cfg.Batch.Prefix,
+			Concurrency:     cfg.Batch.Concurrency,
+			MaxTempBytes:    cfg.Batch.MaxTempBytes,
+			Configuration:   app.baseInput.Configuration,
+			RetentionPolicy: cfg.Video.RetentionPolicy,
+			IdFormat:        cfg.Video.IdFormat,
+			KeyPattern:      keyPattern,
+		}
+
+		result, err := app.videoController.ProcessBatch(ctx, batchInput)
+		if err != nil {
+			logger.Error("Batch processing finished with failures", "error", err)
+		}
+		return result, err
+	}
+
+	// Create processing input using DTOs: the job spec when given, otherwise the video settings
+	input := app.baseInput
+	if cfg.HasJobSpec() {
+		err := specErr
+		if err != nil {
+			err = domain.NewInvalidInputError(fmt.Sprintf("failed to read job spec: %v", err))
+		} else {
+			input, err = trigger.ParseJobSpec(spec, app.baseInput)
+		}
+		if err != nil {
+			logger.Error("Failed to read job spec", "error", err)
+			return nil, err
+		}
+	} else {
+		input.VideoKey = cfg.Video.Key
+		input.VideoId = cfg.Video.Id
+		input.UserId = cfg.Video.UserId
+	}
+	logger.Info("Processing video", "key", input.VideoKey, "job_spec", cfg.HasJobSpec())
+
+	// Process the video; the result is printed and written as the termination message
+	result, err := app.videoController.ProcessVideo(ctx, input)
 	if err != nil {
 		logger.Error("Failed to process video", "error", err)
-		log.Fatalf("Failed to process video: %v", err)
 	}
+	return result, err
+}
+
+// exitOnFlagError exits after a flag parsing failure, which the flag set already reported with the usage
+func exitOnFlagError(err error) {
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(exitSuccess)
+	}
+	os.Exit(exitUsage)
+}
 
-	// Print result
-	fmt.Println("Video processed successfully!")
-	fmt.Printf("Result: %s\n", string(result))
+// readTriggerEvent returns the S3 event given inline, or read from a file ("-" reads stdin)
+func readTriggerEvent(cfg *config.Config) ([]byte, error) {
+	if cfg.Trigger.Event != "" {
+		return []byte(cfg.Trigger.Event), nil
+	}
+	if cfg.Trigger.EventFile == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(cfg.Trigger.EventFile)
+}
+
+// readJobSpec returns the job spec given inline, or read from a file ("-" reads stdin)
+func readJobSpec(cfg *config.Config) ([]byte, error) {
+	if cfg.Job.Spec != "" {
+		return []byte(cfg.Job.Spec), nil
+	}
+	if cfg.Job.SpecFile == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(cfg.Job.SpecFile)
 }
 
-// generateTraceID creates a random 16-byte hex string for tracing
-func generateTraceID() (string, error) {
-	b := make([]byte, 16)
-	if _, err := rand.Read(b); err != nil {
-		return "", fmt.Errorf("failed to generate trace ID: %w", err)
+// jobSpecTraceParent returns the traceparent of a job spec, if any; a malformed spec is reported once it is parsed
+func jobSpecTraceParent(spec []byte) string {
+	var header struct {
+		TraceParent string `json:"traceparent"`
 	}
-	return hex.EncodeToString(b), nil
+	_ = json.Unmarshal(spec, &header)
+	return header.TraceParent
 }
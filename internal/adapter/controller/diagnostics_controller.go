@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+type diagnosticsController struct {
+	diagnosticsUseCase port.DiagnosticsUseCase
+	presenter          port.Presenter
+	logger             logger.Logger
+}
+
+func NewDiagnosticsController(diagnosticsUseCase port.DiagnosticsUseCase, presenter port.Presenter, logger logger.Logger) port.DiagnosticsController {
+	return &diagnosticsController{
+		diagnosticsUseCase: diagnosticsUseCase,
+		presenter:          presenter,
+		logger:             logger,
+	}
+}
+
+func (c *diagnosticsController) Probe(ctx context.Context, input dto.ProbeInput) ([]byte, error) {
+	output, err := c.diagnosticsUseCase.Probe(ctx, input)
+	if err != nil {
+		return c.presentError(ctx, "Probe failed", err)
+	}
+	return c.present(ctx, func() ([]byte, error) { return c.presenter.PresentProbeOutput(output) })
+}
+
+func (c *diagnosticsController) Extract(ctx context.Context, input dto.ExtractInput) ([]byte, error) {
+	output, err := c.diagnosticsUseCase.Extract(ctx, input)
+	if err != nil {
+		return c.presentError(ctx, "Extraction failed", err)
+	}
+	return c.present(ctx, func() ([]byte, error) { return c.presenter.PresentExtractOutput(output) })
+}
+
+func (c *diagnosticsController) Verify(ctx context.Context, input dto.VerifyInput) ([]byte, error) {
+	output, err := c.diagnosticsUseCase.Verify(ctx, input)
+	if err != nil {
+		return c.presentError(ctx, "Verification failed", err)
+	}
+	b, err := c.present(ctx, func() ([]byte, error) { return c.presenter.PresentVerifyOutput(output) })
+	if err == nil && !output.Valid {
+		return b, fmt.Errorf("archive %s is invalid: %d problems found", output.ArchivePath, len(output.Problems))
+	}
+	return b, err
+}
+
+func (c *diagnosticsController) Version(ctx context.Context, input dto.VersionInput) ([]byte, error) {
+	output, err := c.diagnosticsUseCase.Version(ctx, input)
+	if err != nil {
+		return c.presentError(ctx, "Version failed", err)
+	}
+	return c.present(ctx, func() ([]byte, error) { return c.presenter.PresentVersionOutput(output) })
+}
+
+// presentError renders err with the presenter and returns it, so the caller both prints the response and fails
+func (c *diagnosticsController) presentError(ctx context.Context, message string, err error) ([]byte, error) {
+	log := c.logger.WithContext(ctx)
+	log.Error(message, "error", err)
+	b, pErr := c.presenter.PresentError(err)
+	if pErr != nil {
+		log.Error("Failed to marshal error response", "error", pErr)
+		return nil, pErr
+	}
+	return b, err
+}
+
+func (c *diagnosticsController) present(ctx context.Context, present func() ([]byte, error)) ([]byte, error) {
+	b, err := present()
+	if err != nil {
+		c.logger.WithContext(ctx).Error("Failed to marshal response", "error", err)
+		return nil, err
+	}
+	return b, nil
+}
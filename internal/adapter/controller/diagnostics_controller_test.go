@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+func TestDiagnosticsController(t *testing.T) {
+	t.Run("Probe/success", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		uc := pmocks.NewMockDiagnosticsUseCase(ctrl)
+		pr := pmocks.NewMockPresenter(ctrl)
+		c := NewDiagnosticsController(uc, pr, logger.NewSlogLogger())
+
+		input := dto.ProbeInput{Source: "a.mp4"}
+		out := &dto.ProbeOutput{Source: "a.mp4"}
+		prBytes := []byte(`{"success":true}`)
+
+		uc.EXPECT().Probe(gomock.Any(), input).Return(out, nil)
+		pr.EXPECT().PresentProbeOutput(out).Return(prBytes, nil)
+
+		res, err := c.Probe(context.Background(), input)
+		r.NoError(err)
+		r.Equal(prBytes, res)
+	})
+
+	t.Run("Extract/error", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		uc := pmocks.NewMockDiagnosticsUseCase(ctrl)
+		pr := pmocks.NewMockPresenter(ctrl)
+		c := NewDiagnosticsController(uc, pr, logger.NewSlogLogger())
+
+		input := dto.ExtractInput{VideoPath: "a.mp4"}
+		errBoom := errors.New("boom")
+		prBytes := []byte(`{"success":false}`)
+
+		uc.EXPECT().Extract(gomock.Any(), input).Return(nil, errBoom)
+		pr.EXPECT().PresentError(errBoom).Return(prBytes, nil)
+
+		res, err := c.Extract(context.Background(), input)
+		r.ErrorIs(err, errBoom)
+		r.Equal(prBytes, res)
+	})
+
+	t.Run("Verify/invalid_archive_fails", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		uc := pmocks.NewMockDiagnosticsUseCase(ctrl)
+		pr := pmocks.NewMockPresenter(ctrl)
+		c := NewDiagnosticsController(uc, pr, logger.NewSlogLogger())
+
+		input := dto.VerifyInput{ArchivePath: "frames.zip"}
+		out := &dto.VerifyOutput{ArchivePath: "frames.zip", Problems: []string{"archive contains no frames"}}
+		prBytes := []byte(`{"success":false}`)
+
+		uc.EXPECT().Verify(gomock.Any(), input).Return(out, nil)
+		pr.EXPECT().PresentVerifyOutput(out).Return(prBytes, nil)
+
+		res, err := c.Verify(context.Background(), input)
+		r.ErrorContains(err, "archive frames.zip is invalid")
+		r.Equal(prBytes, res)
+	})
+
+	t.Run("Version/success", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		uc := pmocks.NewMockDiagnosticsUseCase(ctrl)
+		pr := pmocks.NewMockPresenter(ctrl)
+		c := NewDiagnosticsController(uc, pr, logger.NewSlogLogger())
+
+		input := dto.VersionInput{Version: "1.2.0"}
+		out := &dto.VersionOutput{VersionInput: input}
+		prBytes := []byte(`{"version":"1.2.0"}`)
+
+		uc.EXPECT().Version(gomock.Any(), input).Return(out, nil)
+		pr.EXPECT().PresentVersionOutput(out).Return(prBytes, nil)
+
+		res, err := c.Version(context.Background(), input)
+		r.NoError(err)
+		r.Equal(prBytes, res)
+	})
+}
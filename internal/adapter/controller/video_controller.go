@@ -2,28 +2,49 @@ package controller
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/tracing"
 )
 
 type videoController struct {
 	videoUseCase port.VideoUseCase
+	batchUseCase port.BatchUseCase
 	presenter    port.Presenter
 	logger       logger.Logger
 }
 
-func NewVideoController(videoUseCase port.VideoUseCase, presenter port.Presenter, logger logger.Logger) port.VideoController {
+func NewVideoController(videoUseCase port.VideoUseCase, batchUseCase port.BatchUseCase, presenter port.Presenter, logger logger.Logger) port.VideoController {
 	return &videoController{
 		videoUseCase: videoUseCase,
+		batchUseCase: batchUseCase,
 		presenter:    presenter,
 		logger:       logger,
 	}
 }
 
+// ProcessVideo continues the trace of input.TraceParent when the caller did not start one, e.g. on a Lambda invocation
 func (c *videoController) ProcessVideo(ctx context.Context, input dto.ProcessVideoInput) ([]byte, error) {
-	log := c.logger.WithContext(ctx).With("video_key", input.VideoKey)
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = tracing.WithTraceParent(ctx, input.TraceParent)
+	}
+	ctx, span := tracing.Start(ctx, "VideoController.ProcessVideo", attribute.String("video.key", input.VideoKey))
+	output, err := c.processVideo(ctx, input)
+	tracing.End(span, err)
+	return output, err
+}
+
+func (c *videoController) processVideo(ctx context.Context, input dto.ProcessVideoInput) ([]byte, error) {
+	ctx = logger.WithAttributes(ctx, "video_key", input.VideoKey, "video_id", input.VideoId, "user_id", input.UserId)
+	log := c.logger.WithContext(ctx)
 	log.Info("Controller received video processing request")
 
 	output, err := c.videoUseCase.ProcessVideo(ctx, input)
@@ -45,3 +66,69 @@ func (c *videoController) ProcessVideo(ctx context.Context, input dto.ProcessVid
 	}
 	return b, nil
 }
+
+func (c *videoController) ProcessBatch(ctx context.Context, input dto.ProcessBatchInput) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "VideoController.ProcessBatch", attribute.String("batch.prefix", input.Prefix))
+	output, err := c.processBatch(ctx, input)
+	tracing.End(span, err)
+	return output, err
+}
+
+func (c *videoController) processBatch(ctx context.Context, input dto.ProcessBatchInput) ([]byte, error) {
+	log := c.logger.WithContext(ctx).With("prefix", input.Prefix, "keys", len(input.VideoKeys))
+	log.Info("Controller received batch processing request")
+
+	output, err := c.batchUseCase.ProcessBatch(ctx, input)
+	if err != nil {
+		log.Error("Batch processing failed", "error", err)
+		b, pErr := c.presenter.PresentError(err)
+		if pErr != nil {
+			log.Error("Failed to marshal error response", "error", pErr)
+			return nil, pErr
+		}
+		return b, err
+	}
+
+	log.Info("Batch processing completed", "total", output.Total, "succeeded", output.Succeeded, "failed", output.Failed)
+	b, pErr := c.presenter.PresentProcessBatchOutput(output)
+	if pErr != nil {
+		log.Error("Failed to marshal batch response", "error", pErr)
+		return nil, pErr
+	}
+	return b, batchError(output)
+}
+
+// batchError classifies the failures of a batch like a single video failure: retryable when a new run may succeed
+// for any item, otherwise internal, invalid input or not found, in that order
+func batchError(output *dto.ProcessBatchOutput) error {
+	if output.Failed == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("%d of %d videos failed", output.Failed, output.Total)
+
+	var internal, invalid, notFound bool
+	for _, result := range output.Results {
+		if result.Success {
+			continue
+		}
+		if result.Retryable {
+			return domain.NewRetryableError(errors.New(message))
+		}
+		switch domain.ErrorCode(result.ErrorCode) {
+		case domain.ErrorCodeInvalidInput, domain.ErrorCodeValidation:
+			invalid = true
+		case domain.ErrorCodeNotFound:
+			notFound = true
+		default:
+			internal = true
+		}
+	}
+	switch {
+	case internal || !(invalid || notFound):
+		return domain.NewInternalError(errors.New(message))
+	case invalid:
+		return domain.NewInvalidInputError(message)
+	default:
+		return domain.NewNotFoundError(message)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
 	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
@@ -23,7 +24,7 @@ func TestVideoController(t *testing.T) {
 		pr := pmocks.NewMockPresenter(ctrl)
 		log := logger.NewSlogLogger()
 
-		c := NewVideoController(uc, pr, log)
+		c := NewVideoController(uc, nil, pr, log)
 
 		input := dto.ProcessVideoInput{VideoKey: "foo.mp4"}
 		out := &dto.ProcessVideoOutput{Success: true, Message: "ok", FrameCount: 5}
@@ -47,7 +48,7 @@ func TestVideoController(t *testing.T) {
 		pr := pmocks.NewMockPresenter(ctrl)
 		log := logger.NewSlogLogger()
 
-		c := NewVideoController(uc, pr, log)
+		c := NewVideoController(uc, nil, pr, log)
 
 		input := dto.ProcessVideoInput{VideoKey: "bar.mp4"}
 		errBoom := errors.New("boom")
@@ -70,7 +71,7 @@ func TestVideoController(t *testing.T) {
 		uc := pmocks.NewMockVideoUseCase(ctrl)
 		pr := pmocks.NewMockPresenter(ctrl)
 		log := logger.NewSlogLogger()
-		c := NewVideoController(uc, pr, log)
+		c := NewVideoController(uc, nil, pr, log)
 
 		input := dto.ProcessVideoInput{VideoKey: "foo.mp4"}
 		out := &dto.ProcessVideoOutput{Success: true, Message: "ok"}
@@ -92,7 +93,7 @@ func TestVideoController(t *testing.T) {
 		uc := pmocks.NewMockVideoUseCase(ctrl)
 		pr := pmocks.NewMockPresenter(ctrl)
 		log := logger.NewSlogLogger()
-		c := NewVideoController(uc, pr, log)
+		c := NewVideoController(uc, nil, pr, log)
 
 		input := dto.ProcessVideoInput{VideoKey: "bar.mp4"}
 		errBoom := errors.New("boom")
@@ -104,4 +105,112 @@ func TestVideoController(t *testing.T) {
 		r.Error(err)
 		r.Nil(res)
 	})
+
+	t.Run("ProcessBatch/success", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		bu := pmocks.NewMockBatchUseCase(ctrl)
+		pr := pmocks.NewMockPresenter(ctrl)
+		c := NewVideoController(nil, bu, pr, logger.NewSlogLogger())
+
+		input := dto.ProcessBatchInput{Prefix: "backfill/"}
+		out := &dto.ProcessBatchOutput{Total: 2, Succeeded: 2}
+		prBytes := []byte(`{"success":true}`)
+
+		bu.EXPECT().ProcessBatch(gomock.Any(), input).Return(out, nil)
+		pr.EXPECT().PresentProcessBatchOutput(out).Return(prBytes, nil)
+
+		res, err := c.ProcessBatch(context.Background(), input)
+		r.NoError(err)
+		r.Equal(prBytes, res)
+	})
+
+	t.Run("ProcessBatch/partial_failure_returns_summary_and_error", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		bu := pmocks.NewMockBatchUseCase(ctrl)
+		pr := pmocks.NewMockPresenter(ctrl)
+		c := NewVideoController(nil, bu, pr, logger.NewSlogLogger())
+
+		input := dto.ProcessBatchInput{VideoKeys: []string{"a.mp4", "b.mp4"}}
+		out := &dto.ProcessBatchOutput{Total: 2, Succeeded: 1, Failed: 1}
+		prBytes := []byte(`{"success":false}`)
+
+		bu.EXPECT().ProcessBatch(gomock.Any(), input).Return(out, nil)
+		pr.EXPECT().PresentProcessBatchOutput(out).Return(prBytes, nil)
+
+		res, err := c.ProcessBatch(context.Background(), input)
+		r.Error(err)
+		r.Equal(prBytes, res)
+	})
+
+	t.Run("ProcessBatch/partial_failure_is_classified", func(t *testing.T) {
+		failed := func(code domain.ErrorCode, retryable bool) dto.BatchItemResult {
+			return dto.BatchItemResult{ErrorCode: string(code), Retryable: retryable}
+		}
+		cases := map[string]struct {
+			results   []dto.BatchItemResult
+			code      domain.ErrorCode
+			retryable bool
+		}{
+			"retryable_wins": {
+				[]dto.BatchItemResult{failed(domain.ErrorCodeInvalidInput, false), failed(domain.ErrorCodeInternal, true)},
+				domain.ErrorCodeInternal, true,
+			},
+			"internal": {
+				[]dto.BatchItemResult{failed(domain.ErrorCodeNotFound, false), failed(domain.ErrorCodeConflict, false)},
+				domain.ErrorCodeInternal, false,
+			},
+			"invalid_input": {
+				[]dto.BatchItemResult{failed(domain.ErrorCodeNotFound, false), failed(domain.ErrorCodeValidation, false)},
+				domain.ErrorCodeInvalidInput, false,
+			},
+			"not_found": {
+				[]dto.BatchItemResult{{Success: true}, failed(domain.ErrorCodeNotFound, false)},
+				domain.ErrorCodeNotFound, false,
+			},
+		}
+		for name, tc := range cases {
+			t.Run(name, func(t *testing.T) {
+				r := require.New(t)
+				ctrl := gomock.NewController(t)
+				bu := pmocks.NewMockBatchUseCase(ctrl)
+				pr := pmocks.NewMockPresenter(ctrl)
+				c := NewVideoController(nil, bu, pr, logger.NewSlogLogger())
+
+				out := &dto.ProcessBatchOutput{Total: len(tc.results), Failed: 1, Results: tc.results}
+				bu.EXPECT().ProcessBatch(gomock.Any(), gomock.Any()).Return(out, nil)
+				pr.EXPECT().PresentProcessBatchOutput(out).Return([]byte(`{}`), nil)
+
+				_, err := c.ProcessBatch(context.Background(), dto.ProcessBatchInput{})
+				r.Equal(tc.code, domain.CodeOf(err))
+				r.Equal(tc.retryable, domain.IsRetryable(err))
+			})
+		}
+	})
+
+	t.Run("ProcessBatch/error", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		bu := pmocks.NewMockBatchUseCase(ctrl)
+		pr := pmocks.NewMockPresenter(ctrl)
+		c := NewVideoController(nil, bu, pr, logger.NewSlogLogger())
+
+		input := dto.ProcessBatchInput{Prefix: "x/"}
+		errBoom := errors.New("boom")
+		prBytes := []byte(`{"success":false}`)
+
+		bu.EXPECT().ProcessBatch(gomock.Any(), input).Return(nil, errBoom)
+		pr.EXPECT().PresentError(errBoom).Return(prBytes, nil)
+
+		res, err := c.ProcessBatch(context.Background(), input)
+		r.ErrorIs(err, errBoom)
+		r.Equal(prBytes, res)
+	})
 }
@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+)
+
+type reportStream struct {
+	Index     int     `json:"index"`
+	CodecType string  `json:"codec_type"`
+	CodecName string  `json:"codec_name"`
+	Width     int     `json:"width,omitempty"`
+	Height    int     `json:"height,omitempty"`
+	FrameRate float64 `json:"frame_rate,omitempty"`
+	Duration  float64 `json:"duration,omitempty"`
+}
+
+type reportMetadata struct {
+	FormatName string         `json:"format_name"`
+	Duration   float64        `json:"duration"`
+	Size       int64          `json:"size"`
+	BitRate    int64          `json:"bit_rate,omitempty"`
+	Streams    []reportStream `json:"streams"`
+}
+
+type reportStage struct {
+	Stage      string  `json:"stage"`
+	DurationMs float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+type reportFFmpeg struct {
+	Version  string   `json:"version,omitempty"`
+	Commands []string `json:"commands,omitempty"`
+}
+
+type processingReport struct {
+	GeneratedAt     time.Time                  `json:"generated_at"`
+	VideoKey        string                     `json:"video_key"`
+	VideoId         string                     `json:"video_id,omitempty"`
+	UserId          string                     `json:"user_id,omitempty"`
+	Hash            string                     `json:"hash,omitempty"`
+	SourceETag      string                     `json:"source_etag,omitempty"`
+	Status          string                     `json:"status"`
+	StartedAt       time.Time                  `json:"started_at"`
+	FinishedAt      time.Time                  `json:"finished_at"`
+	DurationMs      float64                    `json:"duration_ms"`
+	Configuration   *dto.ProcessingConfigInput `json:"configuration,omitempty"`
+	Metadata        *reportMetadata            `json:"metadata,omitempty"`
+	Stages          []reportStage              `json:"stages"`
+	SourceCache     string                     `json:"source_cache,omitempty"`
+	BytesDownloaded int64                      `json:"bytes_downloaded"`
+	BytesUploaded   int64                      `json:"bytes_uploaded"`
+	FFmpeg          *reportFFmpeg              `json:"ffmpeg,omitempty"`
+	FrameCount      int                        `json:"frame_count"`
+	OutputKey       string                     `json:"output_key,omitempty"`
+	Retention       *RetentionDataV2           `json:"retention,omitempty"`
+	Warnings        []string                   `json:"warnings"`
+	Error           *ErrorDataV2               `json:"error,omitempty"`
+}
+
+func newProcessingReport(report *dto.ProcessingReport, generatedAt time.Time) processingReport {
+	body := processingReport{
+		GeneratedAt:     generatedAt.UTC(),
+		VideoKey:        report.VideoKey,
+		VideoId:         report.VideoId,
+		UserId:          report.UserId,
+		Hash:            report.Hash,
+		SourceETag:      report.SourceETag,
+		Status:          report.Status,
+		StartedAt:       report.StartedAt.UTC(),
+		FinishedAt:      report.FinishedAt.UTC(),
+		DurationMs:      float64(report.FinishedAt.Sub(report.StartedAt)) / float64(time.Millisecond),
+		Configuration:   report.Configuration,
+		Stages:          make([]reportStage, 0, len(report.Stages)),
+		SourceCache:     report.SourceCache,
+		BytesDownloaded: report.BytesDownloaded,
+		BytesUploaded:   report.BytesUploaded,
+		FrameCount:      report.FrameCount,
+		OutputKey:       report.OutputKey,
+		Warnings:        append([]string{}, report.Warnings...),
+	}
+	if m := report.Metadata; m != nil {
+		body.Metadata = &reportMetadata{
+			FormatName: m.FormatName,
+			Duration:   m.Duration,
+			Size:       m.Size,
+			BitRate:    m.BitRate,
+			Streams:    make([]reportStream, 0, len(m.Streams)),
+		}
+		for _, s := range m.Streams {
+			body.Metadata.Streams = append(body.Metadata.Streams, reportStream(s))
+		}
+	}
+	for _, s := range report.Stages {
+		body.Stages = append(body.Stages, reportStage{
+			Stage:      s.Stage,
+			DurationMs: float64(s.Duration) / float64(time.Millisecond),
+			Error:      s.Error,
+		})
+	}
+	if report.FFmpegVersion != "" || len(report.FFmpegCommands) > 0 {
+		body.FFmpeg = &reportFFmpeg{Version: report.FFmpegVersion, Commands: report.FFmpegCommands}
+	}
+	if r := report.Retention; r != nil {
+		body.Retention = &RetentionDataV2{Action: r.Action, Status: r.Status, Detail: r.Detail}
+	}
+	if f := report.Failure; f != nil {
+		body.Error = &ErrorDataV2{Code: f.Code, Stage: f.Stage, Retryable: f.Retryable, Message: f.Message}
+	}
+	return body
+}
+
+func (g *videoGateway) UploadProcessingReport(ctx context.Context, key string, report *dto.ProcessingReport) error {
+	jsonBody, err := json.Marshal(newProcessingReport(report, g.now()))
+	if err != nil {
+		return fmt.Errorf("failed to marshal processing report: %w", err)
+	}
+
+	if _, err := g.storageDataSource.UploadProcessedFile(ctx, key, bytes.NewReader(jsonBody), "application/json", int64(len(jsonBody))); err != nil {
+		return fmt.Errorf("failed to upload processing report: %w", err)
+	}
+
+	return nil
+}
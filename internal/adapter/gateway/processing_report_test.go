@@ -0,0 +1,57 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+)
+
+func TestVideoGateway_UploadProcessingReport(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	ds := pmocks.NewMockStorageDataSource(ctrl)
+	g := NewVideoGateway(ds, nil, nil, StatusEventConfig{}).(*videoGateway)
+	g.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	started := time.Date(2026, 1, 2, 3, 4, 0, 0, time.UTC)
+	report := &dto.ProcessingReport{
+		VideoKey:      "uploads/vid.mp4",
+		Hash:          "abc123",
+		Status:        "FAILED",
+		StartedAt:     started,
+		FinishedAt:    started.Add(1500 * time.Millisecond),
+		Stages:        []dto.StageTiming{{Stage: "download", Duration: 250 * time.Millisecond, Error: "connection reset"}},
+		SourceCache:   dto.SourceCacheMiss,
+		FFmpegVersion: "ffmpeg version 7.1",
+		Failure:       &dto.FailureOutput{Code: "INTERNAL_ERROR", Stage: "download", Retryable: true, Message: "connection reset"},
+	}
+
+	var body map[string]any
+	ds.EXPECT().UploadProcessedFile(gomock.Any(), "processed/abc123.report.json", gomock.Any(), "application/json", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, data io.Reader, _ string, size int64) (string, error) {
+			raw, err := io.ReadAll(data)
+			r.NoError(err)
+			r.Equal(int64(len(raw)), size)
+			r.NoError(json.Unmarshal(raw, &body))
+			return "", nil
+		})
+
+	r.NoError(g.UploadProcessingReport(context.Background(), "processed/abc123.report.json", report))
+	r.Equal("2026-01-02T03:04:05Z", body["generated_at"])
+	r.Equal("FAILED", body["status"])
+	r.Equal(1500.0, body["duration_ms"])
+	r.Equal([]any{map[string]any{"stage": "download", "duration_ms": 250.0, "error": "connection reset"}}, body["stages"])
+	r.Equal(map[string]any{"version": "ffmpeg version 7.1"}, body["ffmpeg"])
+	r.Equal("miss", body["source_cache"])
+	r.Equal("download", body["error"].(map[string]any)["stage"])
+	r.Equal([]any{}, body["warnings"])
+	r.NotContains(body, "metadata")
+}
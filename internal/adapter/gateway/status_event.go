@@ -0,0 +1,185 @@
+package gateway
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+)
+
+const (
+	// StatusEventFormatCloudEvents wraps status events in a CloudEvents 1.0 JSON envelope
+	StatusEventFormatCloudEvents = "cloudevents"
+
+	// StatusEventFormatLegacy publishes the bare version 1 event data, as before the envelope was introduced
+	StatusEventFormatLegacy = "legacy"
+
+	// StatusEventType is the CloudEvents type of status events; its suffix is the major version of the data schema
+	StatusEventType = "com.fiap-soat-g20.video.status.v2"
+
+	// StatusEventDataSchema identifies the JSON Schema of status events, checked in under schemas/
+	StatusEventDataSchema = "https://github.com/FIAP-SOAT-G20/hackathon-video-processor-job/blob/main/schemas/video-status-event.v2.json"
+
+	// DefaultStatusEventSource is the CloudEvents source when none is configured
+	DefaultStatusEventSource = "/video-processor-job"
+
+	cloudEventsSpecVersion = "1.0"
+)
+
+// StatusEventConfig configures how status updates are published
+type StatusEventConfig struct {
+	// Format is StatusEventFormatCloudEvents (default) or StatusEventFormatLegacy
+	Format string
+	// Source is the CloudEvents source attribute
+	Source string
+}
+
+// CloudEvent is a CloudEvents 1.0 envelope in structured JSON mode
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject,omitempty"`
+	Time            time.Time `json:"time"`
+	DataContentType string    `json:"datacontenttype"`
+	DataSchema      string    `json:"dataschema"`
+	Data            any       `json:"data"`
+}
+
+// VideoStatusDataV1 is version 1 of the status event data, still published by the legacy format. It carries the IDs
+// as integers, so only numeric IDs can be published in it. Like in version 2, optional fields may be added to it.
+type VideoStatusDataV1 struct {
+	VideoId       uint64           `json:"video_id"`
+	UserId        uint64           `json:"user_id"`
+	Hash          string           `json:"hash"`
+	Status        string           `json:"status"`
+	Retention     *RetentionDataV1 `json:"retention,omitempty"`
+	SourceChanged bool             `json:"source_changed,omitempty"`
+	Error         *ErrorDataV1     `json:"error,omitempty"`
+	// ReportKey is the key of the report.json of the job in the processed bucket
+	ReportKey string `json:"report_key,omitempty"`
+}
+
+// RetentionDataV1 is the retention outcome reported in VideoStatusDataV1
+type RetentionDataV1 struct {
+	Action string `json:"action"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// ErrorDataV1 describes the failure reported in a FAILED VideoStatusDataV1
+type ErrorDataV1 struct {
+	Code      string `json:"code"`
+	Stage     string `json:"stage,omitempty"`
+	Retryable bool   `json:"retryable"`
+	Message   string `json:"message"`
+}
+
+// VideoStatusDataV2 is version 2 of the status event data; version 1 carried the IDs as integers.
+// Fields may be added to it; removing, renaming or retyping a field requires a new version with its own type and schema.
+type VideoStatusDataV2 struct {
+	VideoId       string           `json:"video_id"`
+	UserId        string           `json:"user_id"`
+	Hash          string           `json:"hash"`
+	Status        string           `json:"status"`
+	Retention     *RetentionDataV2 `json:"retention,omitempty"`
+	SourceChanged bool             `json:"source_changed,omitempty"`
+	Error         *ErrorDataV2     `json:"error,omitempty"`
+	// ReportKey is the key of the report.json of the job in the processed bucket
+	ReportKey string `json:"report_key,omitempty"`
+}
+
+// RetentionDataV2 is the retention outcome reported in VideoStatusDataV2
+type RetentionDataV2 struct {
+	Action string `json:"action"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// ErrorDataV2 describes the failure reported in a FAILED VideoStatusDataV2
+type ErrorDataV2 struct {
+	Code      string `json:"code"`
+	Stage     string `json:"stage,omitempty"`
+	Retryable bool   `json:"retryable"`
+	Message   string `json:"message"`
+}
+
+func newVideoStatusDataV1(update dto.VideoStatusUpdate) (VideoStatusDataV1, error) {
+	videoId, err := strconv.ParseUint(update.VideoId, 10, 64)
+	if err != nil {
+		return VideoStatusDataV1{}, fmt.Errorf("video id %q is not numeric: %w", update.VideoId, err)
+	}
+	userId, err := strconv.ParseUint(update.UserId, 10, 64)
+	if err != nil {
+		return VideoStatusDataV1{}, fmt.Errorf("user id %q is not numeric: %w", update.UserId, err)
+	}
+
+	data := VideoStatusDataV1{
+		VideoId:       videoId,
+		UserId:        userId,
+		Hash:          update.Hash,
+		Status:        update.Status,
+		SourceChanged: update.SourceChanged,
+		ReportKey:     update.ReportKey,
+	}
+	if update.Retention != nil {
+		data.Retention = &RetentionDataV1{
+			Action: update.Retention.Action,
+			Status: update.Retention.Status,
+			Detail: update.Retention.Detail,
+		}
+	}
+	if update.Failure != nil {
+		data.Error = &ErrorDataV1{
+			Code:      update.Failure.Code,
+			Stage:     update.Failure.Stage,
+			Retryable: update.Failure.Retryable,
+			Message:   update.Failure.Message,
+		}
+	}
+	return data, nil
+}
+
+func newVideoStatusDataV2(update dto.VideoStatusUpdate) VideoStatusDataV2 {
+	data := VideoStatusDataV2{
+		VideoId:       update.VideoId,
+		UserId:        update.UserId,
+		Hash:          update.Hash,
+		Status:        update.Status,
+		SourceChanged: update.SourceChanged,
+		ReportKey:     update.ReportKey,
+	}
+	if update.Retention != nil {
+		data.Retention = &RetentionDataV2{
+			Action: update.Retention.Action,
+			Status: update.Retention.Status,
+			Detail: update.Retention.Detail,
+		}
+	}
+	if update.Failure != nil {
+		data.Error = &ErrorDataV2{
+			Code:      update.Failure.Code,
+			Stage:     update.Failure.Stage,
+			Retryable: update.Failure.Retryable,
+			Message:   update.Failure.Message,
+		}
+	}
+	return data
+}
+
+// newStatusCloudEvent wraps status data in an envelope; id identifies the event for de-duplication
+func newStatusCloudEvent(config StatusEventConfig, id string, data VideoStatusDataV2, now time.Time) CloudEvent {
+	return CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              id,
+		Source:          config.Source,
+		Type:            StatusEventType,
+		Subject:         data.VideoId,
+		Time:            now.UTC(),
+		DataContentType: "application/json",
+		DataSchema:      StatusEventDataSchema,
+		Data:            data,
+	}
+}
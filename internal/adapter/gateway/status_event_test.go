@@ -0,0 +1,190 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+)
+
+var (
+	// statusEventSchema is the checked-in JSON Schema every published status event must satisfy
+	statusEventSchema = filepath.Join("..", "..", "..", "schemas", "video-status-event.v2.json")
+	// legacyStatusEventSchema describes the version 1 data published by the legacy format
+	legacyStatusEventSchema = filepath.Join("..", "..", "..", "schemas", "video-status-event.v1.json")
+)
+
+func compileSchema(t *testing.T, file, ref string) *jsonschema.Schema {
+	t.Helper()
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+	schema, err := compiler.Compile(file + ref)
+	require.NoError(t, err)
+	return schema
+}
+
+func TestVideoGateway_UpdateStatus(t *testing.T) {
+	update := dto.VideoStatusUpdate{
+		VideoId:       "42",
+		UserId:        "7",
+		Hash:          "abc123",
+		Status:        "FINISHED",
+		Retention:     &dto.RetentionOutput{Action: "delete", Status: dto.RetentionStatusScheduled},
+		SourceChanged: true,
+	}
+
+	// publish runs UpdateStatus with the given config and returns the published message
+	publish := func(t *testing.T, config StatusEventConfig, update dto.VideoStatusUpdate) entity.Message {
+		ctrl := gomock.NewController(t)
+		mb := pmocks.NewMockMessageBroker(ctrl)
+		g := NewVideoGateway(nil, mb, nil, config).(*videoGateway)
+		g.now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.FixedZone("BRT", -3*3600)) }
+
+		var published entity.Message
+		mb.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, m entity.Message) error {
+			published = m
+			return nil
+		})
+		require.NoError(t, g.UpdateStatus(context.Background(), update))
+		return published
+	}
+
+	t.Run("cloudevents_envelope_matches_schema", func(t *testing.T) {
+		r := require.New(t)
+		msg := publish(t, StatusEventConfig{}, update)
+
+		var event map[string]any
+		r.NoError(json.Unmarshal(msg.Body, &event))
+		r.NoError(compileSchema(t, statusEventSchema, "").Validate(event))
+
+		r.Equal("1.0", event["specversion"])
+		r.Equal(msg.ID, event["id"])
+		r.Equal(DefaultStatusEventSource, event["source"])
+		r.Equal(StatusEventType, event["type"])
+		r.Equal("42", event["subject"])
+		r.Equal("2026-01-02T06:04:05Z", event["time"])
+		r.Equal("application/json", event["datacontenttype"])
+		r.Equal(StatusEventDataSchema, event["dataschema"])
+
+		data := event["data"].(map[string]any)
+		r.Equal("42", data["video_id"])
+		r.Equal("7", data["user_id"])
+		r.Equal("FINISHED", data["status"])
+		r.Equal(true, data["source_changed"])
+		r.Equal("scheduled", data["retention"].(map[string]any)["status"])
+	})
+
+	t.Run("legacy_format_publishes_flat_v1_data", func(t *testing.T) {
+		r := require.New(t)
+		msg := publish(t, StatusEventConfig{Format: StatusEventFormatLegacy}, update)
+
+		r.JSONEq(`{"video_id":42,"user_id":7,"hash":"abc123","status":"FINISHED",
+			"retention":{"action":"delete","status":"scheduled","detail":""},"source_changed":true}`, string(msg.Body))
+		var data map[string]any
+		r.NoError(json.Unmarshal(msg.Body, &data))
+		r.NoError(compileSchema(t, legacyStatusEventSchema, "#/$defs/videoStatusData").Validate(data))
+	})
+
+	t.Run("legacy_format_matches_golden_v1_data", func(t *testing.T) {
+		finished := update
+		finished.ReportKey = "reports/42/report.json"
+		failed := dto.VideoStatusUpdate{
+			VideoId:   "42",
+			UserId:    "7",
+			Status:    "FAILED",
+			Failure:   &dto.FailureOutput{Code: "NOT_FOUND", Stage: "download", Message: "data not found"},
+			ReportKey: "reports/42/report.json",
+		}
+		for name, u := range map[string]dto.VideoStatusUpdate{"finished": finished, "failed": failed} {
+			t.Run(name, func(t *testing.T) {
+				r := require.New(t)
+				golden, err := os.ReadFile(filepath.Join("testdata", "status_event_v1_"+name+".json"))
+				r.NoError(err)
+				msg := publish(t, StatusEventConfig{Format: StatusEventFormatLegacy}, u)
+				r.Equal(strings.TrimSuffix(string(golden), "\n"), string(msg.Body))
+
+				var data map[string]any
+				r.NoError(json.Unmarshal(msg.Body, &data))
+				r.NoError(compileSchema(t, legacyStatusEventSchema, "#/$defs/videoStatusData").Validate(data))
+			})
+		}
+	})
+
+	t.Run("legacy_format_rejects_non_numeric_ids", func(t *testing.T) {
+		r := require.New(t)
+		g := NewVideoGateway(nil, pmocks.NewMockMessageBroker(gomock.NewController(t)), nil,
+			StatusEventConfig{Format: StatusEventFormatLegacy})
+		opaque := update
+		opaque.VideoId = "0b9e4c6e-1f0a-4d7b-9a52-3f1d2c8e7a10"
+		r.ErrorContains(g.UpdateStatus(context.Background(), opaque), "not numeric")
+	})
+
+	t.Run("event_id_comes_from_the_update", func(t *testing.T) {
+		r := require.New(t)
+		withId := update
+		withId.EventId = "0b9e4c6e-1f0a-4d7b-9a52-3f1d2c8e7a10"
+		r.Equal(withId.EventId, publish(t, StatusEventConfig{}, withId).ID)
+
+		// Two runs of one video publish the same data as distinct events
+		first := publish(t, StatusEventConfig{}, update)
+		again := publish(t, StatusEventConfig{}, update)
+		r.NotEmpty(first.ID)
+		r.NotEqual(first.ID, again.ID)
+	})
+
+	t.Run("schema_rejects_missing_fields", func(t *testing.T) {
+		r := require.New(t)
+		var event map[string]any
+		r.NoError(json.Unmarshal([]byte(`{"specversion":"1.0","id":"x","source":"/s","type":"com.fiap-soat-g20.video.status.v2",
+			"time":"2026-01-02T03:04:05Z","datacontenttype":"application/json","dataschema":"https://example.com/s.json",
+			"data":{"video_id":"1","hash":"h","status":"FINISHED"}}`), &event))
+		r.Error(compileSchema(t, statusEventSchema, "").Validate(event))
+	})
+
+	t.Run("message_has_attributes_and_group", func(t *testing.T) {
+		r := require.New(t)
+		msg := publish(t, StatusEventConfig{}, update)
+		r.Equal(map[string]string{"status": "FINISHED", "video_id": "42", "user_id": "7"}, msg.Attributes)
+		r.Equal("42", msg.GroupID)
+		r.False(msg.AwaitDelivery)
+
+		awaited := update
+		awaited.AwaitDelivery = true
+		r.True(publish(t, StatusEventConfig{}, awaited).AwaitDelivery)
+	})
+
+	t.Run("opaque_ids_are_published_verbatim", func(t *testing.T) {
+		r := require.New(t)
+		uuidUpdate := update
+		uuidUpdate.VideoId = "0b9e4c6e-1f0a-4d7b-9a52-3f1d2c8e7a10"
+		uuidUpdate.UserId = "01ARZ3NDEKTSV4RRFFQ69G5FAV"
+		msg := publish(t, StatusEventConfig{}, uuidUpdate)
+
+		var event map[string]any
+		r.NoError(json.Unmarshal(msg.Body, &event))
+		r.NoError(compileSchema(t, statusEventSchema, "").Validate(event))
+		r.Equal(uuidUpdate.VideoId, event["subject"])
+		data := event["data"].(map[string]any)
+		r.Equal(uuidUpdate.VideoId, data["video_id"])
+		r.Equal(uuidUpdate.UserId, data["user_id"])
+		r.Equal(uuidUpdate.VideoId, msg.GroupID)
+	})
+
+	t.Run("schema_rejects_numeric_ids", func(t *testing.T) {
+		r := require.New(t)
+		var data map[string]any
+		r.NoError(json.Unmarshal([]byte(`{"video_id":42,"user_id":7,"hash":"h","status":"FINISHED"}`), &data))
+		r.Error(compileSchema(t, statusEventSchema, "#/$defs/videoStatusData").Validate(data))
+	})
+}
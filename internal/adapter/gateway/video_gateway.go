@@ -1,64 +1,234 @@
 package gateway
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"strconv"
+	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
 )
 
 type videoGateway struct {
 	storageDataSource port.StorageDataSource
 	messageBroker     port.MessageBroker
+	callbackNotifier  port.CallbackNotifier
+	statusEvents      StatusEventConfig
+	now               func() time.Time
 }
 
-func NewVideoGateway(storageDataSource port.StorageDataSource, messageBroker port.MessageBroker) port.VideoGateway {
+func NewVideoGateway(
+	storageDataSource port.StorageDataSource,
+	messageBroker port.MessageBroker,
+	callbackNotifier port.CallbackNotifier,
+	statusEvents StatusEventConfig,
+) port.VideoGateway {
+	if statusEvents.Format == "" {
+		statusEvents.Format = StatusEventFormatCloudEvents
+	}
+	if statusEvents.Source == "" {
+		statusEvents.Source = DefaultStatusEventSource
+	}
 	return &videoGateway{
 		storageDataSource: storageDataSource,
 		messageBroker:     messageBroker,
+		callbackNotifier:  callbackNotifier,
+		statusEvents:      statusEvents,
+		now:               time.Now,
 	}
 }
 
-func (g *videoGateway) Download(ctx context.Context, key string) (io.ReadCloser, error) {
-	return g.storageDataSource.DownloadVideo(ctx, key)
+func (g *videoGateway) Download(ctx context.Context, key string, version entity.ObjectVersion) (io.ReadCloser, entity.ObjectVersion, error) {
+	return g.storageDataSource.DownloadVideo(ctx, key, version)
 }
 
 func (g *videoGateway) Upload(ctx context.Context, key string, data io.Reader, contentType string, size int64) (string, error) {
 	return g.storageDataSource.UploadProcessedFile(ctx, key, data, contentType, size)
 }
 
-func (g *videoGateway) Delete(ctx context.Context, key string) error {
-	return g.storageDataSource.DeleteVideo(ctx, key)
+func (g *videoGateway) StatOutput(ctx context.Context, key string) (int64, error) {
+	return g.storageDataSource.StatProcessedFile(ctx, key)
+}
+
+func (g *videoGateway) DeleteOutput(ctx context.Context, key string) error {
+	return g.storageDataSource.DeleteProcessedFile(ctx, key)
+}
+
+func (g *videoGateway) Delete(ctx context.Context, key string, version entity.ObjectVersion) error {
+	return g.storageDataSource.DeleteVideo(ctx, key, version)
+}
+
+func (g *videoGateway) Copy(ctx context.Context, key string, version entity.ObjectVersion, destinationBucket, destinationKey string) error {
+	return g.storageDataSource.CopyVideo(ctx, key, version, destinationBucket, destinationKey)
+}
+
+func (g *videoGateway) Tag(ctx context.Context, key string, version entity.ObjectVersion, tags map[string]string) error {
+	return g.storageDataSource.TagVideo(ctx, key, version, tags)
+}
+
+func (g *videoGateway) Stat(ctx context.Context, key string) (*entity.StorageObject, error) {
+	return g.storageDataSource.StatVideo(ctx, key)
+}
+
+func (g *videoGateway) List(ctx context.Context, prefix string) ([]entity.StorageObject, error) {
+	return g.storageDataSource.ListVideos(ctx, prefix)
 }
 
-func (g *videoGateway) UpdateStatus(ctx context.Context, videoId string, userId string, videoHash string, status string) error {
-	videoIdUint, err := strconv.ParseUint(videoId, 10, 64)
+func (g *videoGateway) UpdateStatus(ctx context.Context, update dto.VideoStatusUpdate) error {
+	id := update.EventId
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	var body any
+	if g.statusEvents.Format == StatusEventFormatLegacy {
+		data, err := newVideoStatusDataV1(update)
+		if err != nil {
+			return fmt.Errorf("failed to encode legacy status event: %w", err)
+		}
+		body = data
+	} else {
+		body = newStatusCloudEvent(g.statusEvents, id, newVideoStatusDataV2(update), g.now())
+	}
+	jsonBody, err := json.Marshal(body)
 	if err != nil {
-		return fmt.Errorf("failed to parse video id: %w", err)
+		return fmt.Errorf("failed to marshal body: %w", err)
 	}
-	userIdUint, err := strconv.ParseUint(userId, 10, 64)
+
+	err = g.messageBroker.PublishMessage(ctx, entity.Message{
+		ID:   id,
+		Body: jsonBody,
+		Attributes: map[string]string{
+			"status":   update.Status,
+			"video_id": update.VideoId,
+			"user_id":  update.UserId,
+		},
+		GroupID:       update.VideoId,
+		AwaitDelivery: update.AwaitDelivery,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to parse user id: %w", err)
+		return fmt.Errorf("failed to publish status update: %w", err)
 	}
-	body := map[string]any{
-		"video_id": videoIdUint,
-		"user_id":  userIdUint,
-		"hash":     videoHash,
-		"status":   status,
+
+	return nil
+}
+
+type batchReportItem struct {
+	VideoKey   string  `json:"video_key"`
+	Success    bool    `json:"success"`
+	OutputKey  string  `json:"output_key,omitempty"`
+	FrameCount int     `json:"frame_count,omitempty"`
+	Hash       string  `json:"hash,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	ErrorCode  string  `json:"error_code,omitempty"`
+	Stage      string  `json:"stage,omitempty"`
+	Retryable  bool    `json:"retryable,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+type batchReport struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	Total       int               `json:"total"`
+	Succeeded   int               `json:"succeeded"`
+	Failed      int               `json:"failed"`
+	Results     []batchReportItem `json:"results"`
+}
+
+func (g *videoGateway) UploadBatchReport(ctx context.Context, key string, report *dto.ProcessBatchOutput) error {
+	body := batchReport{
+		GeneratedAt: time.Now().UTC(),
+		Total:       report.Total,
+		Succeeded:   report.Succeeded,
+		Failed:      report.Failed,
+		Results:     make([]batchReportItem, 0, len(report.Results)),
+	}
+	for _, r := range report.Results {
+		body.Results = append(body.Results, batchReportItem{
+			VideoKey:   r.VideoKey,
+			Success:    r.Success,
+			OutputKey:  r.OutputKey,
+			FrameCount: r.FrameCount,
+			Hash:       r.Hash,
+			Error:      r.Error,
+			ErrorCode:  r.ErrorCode,
+			Stage:      r.Stage,
+			Retryable:  r.Retryable,
+			DurationMs: float64(r.Duration) / float64(time.Millisecond),
+		})
 	}
 
 	jsonBody, err := json.Marshal(body)
 	if err != nil {
-		return fmt.Errorf("failed to marshal body: %w", err)
+		return fmt.Errorf("failed to marshal batch report: %w", err)
 	}
 
-	err = g.messageBroker.PublishMessage(ctx, jsonBody)
-	if err != nil {
-		return fmt.Errorf("failed to publish status update: %w", err)
+	if _, err := g.storageDataSource.UploadProcessedFile(ctx, key, bytes.NewReader(jsonBody), "application/json", int64(len(jsonBody))); err != nil {
+		return fmt.Errorf("failed to upload batch report: %w", err)
 	}
 
 	return nil
 }
+
+type callbackRetention struct {
+	Action string `json:"action"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type callbackPayload struct {
+	VideoKey      string             `json:"video_key"`
+	VideoId       string             `json:"video_id,omitempty"`
+	UserId        string             `json:"user_id,omitempty"`
+	Success       bool               `json:"success"`
+	Message       string             `json:"message"`
+	OutputKey     string             `json:"output_key,omitempty"`
+	FrameCount    int                `json:"frame_count,omitempty"`
+	Hash          string             `json:"hash,omitempty"`
+	Error         string             `json:"error,omitempty"`
+	ErrorCode     string             `json:"error_code,omitempty"`
+	Stage         string             `json:"stage,omitempty"`
+	Retryable     bool               `json:"retryable,omitempty"`
+	Retention     *callbackRetention `json:"retention,omitempty"`
+	SourceChanged bool               `json:"source_changed,omitempty"`
+	ReportKey     string             `json:"report_key,omitempty"`
+}
+
+func (g *videoGateway) NotifyCallback(ctx context.Context, notification dto.CallbackNotification) (entity.CallbackDelivery, error) {
+	result := notification.Result
+	body := callbackPayload{
+		VideoKey:      notification.VideoKey,
+		VideoId:       notification.VideoId,
+		UserId:        notification.UserId,
+		Success:       result.Success,
+		Message:       result.Message,
+		OutputKey:     result.OutputKey,
+		FrameCount:    result.FrameCount,
+		Hash:          result.Hash,
+		Error:         result.Error,
+		ErrorCode:     result.ErrorCode,
+		Stage:         result.Stage,
+		Retryable:     result.Retryable,
+		SourceChanged: result.SourceChanged,
+		ReportKey:     result.ReportKey,
+	}
+	if result.Retention != nil {
+		body.Retention = &callbackRetention{
+			Action: result.Retention.Action,
+			Status: result.Retention.Status,
+			Detail: result.Retention.Detail,
+		}
+	}
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return entity.CallbackDelivery{}, fmt.Errorf("failed to marshal callback payload: %w", err)
+	}
+
+	return g.callbackNotifier.Notify(ctx, notification.Callback.URL, notification.Callback.Secret, jsonBody)
+}
@@ -2,7 +2,9 @@ package presenter
 
 import (
 	"encoding/json"
+	"time"
 
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
 )
@@ -15,22 +17,148 @@ func NewVideoJsonPresenter() port.Presenter {
 
 func (p *videoJsonPresenter) PresentProcessVideoOutput(output *dto.ProcessVideoOutput) ([]byte, error) {
 	response := VideoJsonResponse{
-		Success:    output.Success,
-		Message:    output.Message,
-		OutputKey:  output.OutputKey,
+		Success:       output.Success,
+		Message:       output.Message,
+		OutputKey:     output.OutputKey,
+		FrameCount:    output.FrameCount,
+		Hash:          output.Hash,
+		Error:         output.Error,
+		SourceChanged: output.SourceChanged,
+		ReportKey:     output.ReportKey,
+	}
+	if output.ErrorCode != "" {
+		response.ErrorCode = output.ErrorCode
+		response.Stage = output.Stage
+		response.Retryable = &output.Retryable
+	}
+	if output.Retention != nil {
+		response.Retention = &RetentionJsonResponse{
+			Action: output.Retention.Action,
+			Status: output.Retention.Status,
+			Detail: output.Retention.Detail,
+		}
+	}
+	if output.Callback != nil {
+		response.Callback = &CallbackJsonResponse{
+			URL:        output.Callback.URL,
+			Status:     output.Callback.Status,
+			Attempts:   output.Callback.Attempts,
+			StatusCode: output.Callback.StatusCode,
+			Error:      output.Callback.Error,
+		}
+	}
+
+	return json.Marshal(response)
+}
+
+func (p *videoJsonPresenter) PresentProcessBatchOutput(output *dto.ProcessBatchOutput) ([]byte, error) {
+	response := BatchJsonResponse{
+		Success:   output.Failed == 0,
+		Total:     output.Total,
+		Succeeded: output.Succeeded,
+		Failed:    output.Failed,
+		ReportKey: output.ReportKey,
+		Results:   make([]BatchItemJsonResponse, 0, len(output.Results)),
+	}
+	for _, r := range output.Results {
+		response.Results = append(response.Results, BatchItemJsonResponse{
+			VideoKey:   r.VideoKey,
+			Success:    r.Success,
+			OutputKey:  r.OutputKey,
+			FrameCount: r.FrameCount,
+			Hash:       r.Hash,
+			Error:      r.Error,
+			ErrorCode:  r.ErrorCode,
+			Stage:      r.Stage,
+			Retryable:  r.Retryable,
+			DurationMs: float64(r.Duration) / float64(time.Millisecond),
+		})
+	}
+
+	return json.Marshal(response)
+}
+
+func (p *videoJsonPresenter) PresentProbeOutput(output *dto.ProbeOutput) ([]byte, error) {
+	response := ProbeJsonResponse{
+		Success:     true,
+		Source:      output.Source,
+		FromStorage: output.FromStorage,
+		FormatName:  output.FormatName,
+		Duration:    output.Duration,
+		Size:        output.Size,
+		BitRate:     output.BitRate,
+		Streams:     make([]StreamJsonResponse, 0, len(output.Streams)),
+	}
+	for _, s := range output.Streams {
+		response.Streams = append(response.Streams, StreamJsonResponse{
+			Index:     s.Index,
+			CodecType: s.CodecType,
+			CodecName: s.CodecName,
+			Width:     s.Width,
+			Height:    s.Height,
+			FrameRate: s.FrameRate,
+			Duration:  s.Duration,
+		})
+	}
+
+	return json.Marshal(response)
+}
+
+func (p *videoJsonPresenter) PresentExtractOutput(output *dto.ExtractOutput) ([]byte, error) {
+	response := ExtractJsonResponse{
+		Success:    true,
+		VideoPath:  output.VideoPath,
+		OutputPath: output.OutputPath,
 		FrameCount: output.FrameCount,
-		Hash:       output.Hash,
-		Error:      output.Error,
+		Size:       output.Size,
+	}
+
+	return json.Marshal(response)
+}
+
+func (p *videoJsonPresenter) PresentVerifyOutput(output *dto.VerifyOutput) ([]byte, error) {
+	response := VerifyJsonResponse{
+		Success:     output.Valid,
+		ArchivePath: output.ArchivePath,
+		Size:        output.Size,
+		SHA256:      output.SHA256,
+		FrameCount:  output.FrameCount,
+		Format:      output.Format,
+		Width:       output.Width,
+		Height:      output.Height,
+		FirstFrame:  output.FirstFrame,
+		LastFrame:   output.LastFrame,
+		Problems:    output.Problems,
+	}
+
+	return json.Marshal(response)
+}
+
+func (p *videoJsonPresenter) PresentVersionOutput(output *dto.VersionOutput) ([]byte, error) {
+	response := VersionJsonResponse{
+		Version:           output.Version,
+		Commit:            output.Commit,
+		BuildDate:         output.BuildDate,
+		GoVersion:         output.GoVersion,
+		FFmpegVersion:     output.FFmpegVersion,
+		FFprobeVersion:    output.FFprobeVersion,
+		Encoders:          append([]string{}, output.Encoders...),
+		HWAccels:          append([]string{}, output.HWAccels...),
+		CapabilitiesError: output.CapabilitiesError,
 	}
 
 	return json.Marshal(response)
 }
 
 func (p *videoJsonPresenter) PresentError(err error) ([]byte, error) {
+	retryable := domain.IsRetryable(err)
 	response := VideoJsonResponse{
-		Success: false,
-		Message: "Processing failed",
-		Error:   err.Error(),
+		Success:   false,
+		Message:   "Processing failed",
+		Error:     err.Error(),
+		ErrorCode: string(domain.CodeOf(err)),
+		Stage:     string(domain.StageOf(err)),
+		Retryable: &retryable,
 	}
 
 	return json.Marshal(response)
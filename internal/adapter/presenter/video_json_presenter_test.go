@@ -4,9 +4,11 @@ import (
 	"encoding/json"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
 )
 
@@ -28,6 +30,48 @@ func TestVideoJsonPresenter(t *testing.T) {
 		r.Equal("ok", m["message"])
 		r.Equal("processed/foo_frames.zip", m["output_key"])
 		r.Equal(42, int(m["frame_count"].(float64)))
+		r.NotContains(m, "retention")
+	})
+
+	t.Run("PresentProcessVideoOutput_Retention", func(t *testing.T) {
+		r := require.New(t)
+		p := NewVideoJsonPresenter()
+		out := &dto.ProcessVideoOutput{
+			Success:       true,
+			Retention:     &dto.RetentionOutput{Action: "move:archive/", Status: dto.RetentionStatusApplied, Detail: "moved to archive/a.mp4"},
+			SourceChanged: true,
+			ReportKey:     "processed/abc.report.json",
+		}
+		b, err := p.PresentProcessVideoOutput(out)
+		r.NoError(err)
+		var m map[string]any
+		r.NoError(json.Unmarshal(b, &m))
+		retention := m["retention"].(map[string]any)
+		r.Equal("move:archive/", retention["action"])
+		r.Equal("applied", retention["status"])
+		r.Equal("moved to archive/a.mp4", retention["detail"])
+		r.Equal(true, m["source_changed"])
+		r.Equal("processed/abc.report.json", m["report_key"])
+	})
+
+	t.Run("PresentProcessVideoOutput_Callback", func(t *testing.T) {
+		r := require.New(t)
+		p := NewVideoJsonPresenter()
+		out := &dto.ProcessVideoOutput{
+			Success: true,
+			Callback: &dto.CallbackOutput{
+				URL: "https://partner.example.com/hook", Status: dto.CallbackStatusFailed, Attempts: 3, StatusCode: 502, Error: "callback responded with status 502",
+			},
+		}
+		b, err := p.PresentProcessVideoOutput(out)
+		r.NoError(err)
+		var m map[string]any
+		r.NoError(json.Unmarshal(b, &m))
+		callback := m["callback"].(map[string]any)
+		r.Equal("https://partner.example.com/hook", callback["url"])
+		r.Equal("failed", callback["status"])
+		r.Equal(3, int(callback["attempts"].(float64)))
+		r.Equal(502, int(callback["status_code"].(float64)))
 	})
 
 	t.Run("PresentError", func(t *testing.T) {
@@ -40,5 +84,119 @@ func TestVideoJsonPresenter(t *testing.T) {
 		r.Equal(false, m["success"])
 		r.Equal("Processing failed", m["message"])
 		r.NotEmpty(m["error"])
+		r.Equal("INTERNAL_ERROR", m["error_code"])
+		r.Equal(false, m["retryable"])
+		r.NotContains(m, "stage")
+	})
+
+	t.Run("PresentError_StageAndRetryable", func(t *testing.T) {
+		r := require.New(t)
+		p := NewVideoJsonPresenter()
+		b, err := p.PresentError(domain.WithStage(domain.StageUpload, domain.NewRetryableError(errors.New("timeout"))))
+		r.NoError(err)
+		var m map[string]any
+		r.NoError(json.Unmarshal(b, &m))
+		r.Equal("INTERNAL_ERROR", m["error_code"])
+		r.Equal("upload", m["stage"])
+		r.Equal(true, m["retryable"])
+		r.Equal("timeout", m["error"])
+	})
+
+	t.Run("PresentProcessVideoOutput_Failure", func(t *testing.T) {
+		r := require.New(t)
+		p := NewVideoJsonPresenter()
+		out := &dto.ProcessVideoOutput{
+			Message:   "failed to download video",
+			Error:     "video not found",
+			ErrorCode: string(domain.ErrorCodeNotFound),
+			Stage:     string(domain.StageDownload),
+		}
+		b, err := p.PresentProcessVideoOutput(out)
+		r.NoError(err)
+		var m map[string]any
+		r.NoError(json.Unmarshal(b, &m))
+		r.Equal(false, m["success"])
+		r.Equal("NOT_FOUND", m["error_code"])
+		r.Equal("download", m["stage"])
+		r.Equal(false, m["retryable"])
+	})
+
+	t.Run("PresentProcessBatchOutput", func(t *testing.T) {
+		r := require.New(t)
+		p := NewVideoJsonPresenter()
+		out := &dto.ProcessBatchOutput{
+			Total:     2,
+			Succeeded: 1,
+			Failed:    1,
+			ReportKey: "reports/batch-20250101T000000Z.json",
+			Results: []dto.BatchItemResult{
+				{VideoKey: "a.mp4", Success: true, OutputKey: "processed/a.zip", FrameCount: 3, Duration: 1500 * time.Millisecond},
+				{VideoKey: "b.mp4", Error: "boom"},
+			},
+		}
+		b, err := p.PresentProcessBatchOutput(out)
+		r.NoError(err)
+		var m map[string]any
+		r.NoError(json.Unmarshal(b, &m))
+		r.Equal(false, m["success"])
+		r.Equal(2, int(m["total"].(float64)))
+		r.Equal("reports/batch-20250101T000000Z.json", m["report_key"])
+		results := m["results"].([]any)
+		r.Len(results, 2)
+		first := results[0].(map[string]any)
+		r.Equal("a.mp4", first["video_key"])
+		r.Equal(1500.0, first["duration_ms"])
+		r.Equal("boom", results[1].(map[string]any)["error"])
+	})
+
+	t.Run("PresentProbeOutput", func(t *testing.T) {
+		r := require.New(t)
+		p := NewVideoJsonPresenter()
+		out := &dto.ProbeOutput{
+			Source:     "videos/a.mp4",
+			FormatName: "mov,mp4",
+			Duration:   12.5,
+			Streams:    []dto.StreamOutput{{Index: 0, CodecType: "video", CodecName: "h264", Width: 640, Height: 360, FrameRate: 25}},
+		}
+		b, err := p.PresentProbeOutput(out)
+		r.NoError(err)
+		var m map[string]any
+		r.NoError(json.Unmarshal(b, &m))
+		r.Equal(true, m["success"])
+		r.Equal("videos/a.mp4", m["source"])
+		r.Equal(12.5, m["duration"])
+		stream := m["streams"].([]any)[0].(map[string]any)
+		r.Equal("h264", stream["codec_name"])
+		r.Equal(25.0, stream["frame_rate"])
+	})
+
+	t.Run("PresentVerifyOutput_Invalid", func(t *testing.T) {
+		r := require.New(t)
+		p := NewVideoJsonPresenter()
+		out := &dto.VerifyOutput{ArchivePath: "frames.zip", FrameCount: 2, Problems: []string{"frames 2 to 3 are missing"}}
+		b, err := p.PresentVerifyOutput(out)
+		r.NoError(err)
+		var m map[string]any
+		r.NoError(json.Unmarshal(b, &m))
+		r.Equal(false, m["success"])
+		r.Equal([]any{"frames 2 to 3 are missing"}, m["problems"])
+	})
+
+	t.Run("PresentVersionOutput_WithoutFFmpeg", func(t *testing.T) {
+		r := require.New(t)
+		p := NewVideoJsonPresenter()
+		out := &dto.VersionOutput{
+			VersionInput:      dto.VersionInput{Version: "1.2.0", GoVersion: "go1.24"},
+			CapabilitiesError: "ffmpeg -version failed",
+		}
+		b, err := p.PresentVersionOutput(out)
+		r.NoError(err)
+		var m map[string]any
+		r.NoError(json.Unmarshal(b, &m))
+		r.Equal("1.2.0", m["version"])
+		r.Equal([]any{}, m["encoders"])
+		r.Equal([]any{}, m["hwaccels"])
+		r.Equal("ffmpeg -version failed", m["capabilities_error"])
+		r.NotContains(m, "ffmpeg_version")
 	})
 }
@@ -1,10 +1,108 @@
 package presenter
 
 type VideoJsonResponse struct {
-	Success    bool   `json:"success"`
-	Message    string `json:"message"`
-	OutputKey  string `json:"output_key,omitempty"`
-	FrameCount int    `json:"frame_count,omitempty"`
-	Hash       string `json:"hash,omitempty"`
+	Success       bool                   `json:"success"`
+	Message       string                 `json:"message"`
+	OutputKey     string                 `json:"output_key,omitempty"`
+	FrameCount    int                    `json:"frame_count,omitempty"`
+	Hash          string                 `json:"hash,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+	ErrorCode     string                 `json:"error_code,omitempty"`
+	Stage         string                 `json:"stage,omitempty"`
+	Retryable     *bool                  `json:"retryable,omitempty"`
+	Retention     *RetentionJsonResponse `json:"retention,omitempty"`
+	SourceChanged bool                   `json:"source_changed,omitempty"`
+	ReportKey     string                 `json:"report_key,omitempty"`
+	Callback      *CallbackJsonResponse  `json:"callback,omitempty"`
+}
+
+type CallbackJsonResponse struct {
+	URL        string `json:"url"`
+	Status     string `json:"status"`
+	Attempts   int    `json:"attempts"`
+	StatusCode int    `json:"status_code,omitempty"`
 	Error      string `json:"error,omitempty"`
 }
+
+type RetentionJsonResponse struct {
+	Action string `json:"action"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type BatchItemJsonResponse struct {
+	VideoKey   string  `json:"video_key"`
+	Success    bool    `json:"success"`
+	OutputKey  string  `json:"output_key,omitempty"`
+	FrameCount int     `json:"frame_count,omitempty"`
+	Hash       string  `json:"hash,omitempty"`
+	Error      string  `json:"error,omitempty"`
+	ErrorCode  string  `json:"error_code,omitempty"`
+	Stage      string  `json:"stage,omitempty"`
+	Retryable  bool    `json:"retryable,omitempty"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+type BatchJsonResponse struct {
+	Success   bool                    `json:"success"`
+	Total     int                     `json:"total"`
+	Succeeded int                     `json:"succeeded"`
+	Failed    int                     `json:"failed"`
+	ReportKey string                  `json:"report_key,omitempty"`
+	Results   []BatchItemJsonResponse `json:"results"`
+}
+
+type StreamJsonResponse struct {
+	Index     int     `json:"index"`
+	CodecType string  `json:"codec_type"`
+	CodecName string  `json:"codec_name"`
+	Width     int     `json:"width,omitempty"`
+	Height    int     `json:"height,omitempty"`
+	FrameRate float64 `json:"frame_rate,omitempty"`
+	Duration  float64 `json:"duration,omitempty"`
+}
+
+type ProbeJsonResponse struct {
+	Success     bool                 `json:"success"`
+	Source      string               `json:"source"`
+	FromStorage bool                 `json:"from_storage"`
+	FormatName  string               `json:"format_name"`
+	Duration    float64              `json:"duration"`
+	Size        int64                `json:"size"`
+	BitRate     int64                `json:"bit_rate,omitempty"`
+	Streams     []StreamJsonResponse `json:"streams"`
+}
+
+type ExtractJsonResponse struct {
+	Success    bool   `json:"success"`
+	VideoPath  string `json:"video_path"`
+	OutputPath string `json:"output_path"`
+	FrameCount int    `json:"frame_count"`
+	Size       int64  `json:"size"`
+}
+
+type VerifyJsonResponse struct {
+	Success     bool     `json:"success"`
+	ArchivePath string   `json:"archive_path"`
+	Size        int64    `json:"size"`
+	SHA256      string   `json:"sha256"`
+	FrameCount  int      `json:"frame_count"`
+	Format      string   `json:"format,omitempty"`
+	Width       int      `json:"width,omitempty"`
+	Height      int      `json:"height,omitempty"`
+	FirstFrame  int      `json:"first_frame"`
+	LastFrame   int      `json:"last_frame"`
+	Problems    []string `json:"problems,omitempty"`
+}
+
+type VersionJsonResponse struct {
+	Version           string   `json:"version"`
+	Commit            string   `json:"commit,omitempty"`
+	BuildDate         string   `json:"build_date,omitempty"`
+	GoVersion         string   `json:"go_version"`
+	FFmpegVersion     string   `json:"ffmpeg_version,omitempty"`
+	FFprobeVersion    string   `json:"ffprobe_version,omitempty"`
+	Encoders          []string `json:"encoders"`
+	HWAccels          []string `json:"hwaccels"`
+	CapabilitiesError string   `json:"capabilities_error,omitempty"`
+}
@@ -0,0 +1,114 @@
+package trigger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+)
+
+// JobSpecVersion is the only job spec version this build understands (see schemas/video-job-spec.v1.json)
+const JobSpecVersion = 1
+
+// JobSpec is a single JSON document describing the video to process and how to process it
+type JobSpec struct {
+	Version int `json:"version"`
+	dto.ProcessVideoInput
+}
+
+// ParseJobSpec decodes a job spec over template, so the settings the spec leaves out keep the configured values.
+// Unknown fields, an unsupported version and invalid settings are rejected; every problem is reported at once in a
+// domain.InvalidInputError.
+func ParseJobSpec(data []byte, template dto.ProcessVideoInput) (dto.ProcessVideoInput, error) {
+	spec := JobSpec{ProcessVideoInput: template}
+	// The template is shared, so its nested settings are copied before the spec is decoded over them
+	if template.Configuration != nil {
+		configuration := *template.Configuration
+		spec.Configuration = &configuration
+	}
+	if template.Callback != nil {
+		callback := *template.Callback
+		spec.Callback = &callback
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return dto.ProcessVideoInput{}, domain.NewInvalidInputError(fmt.Sprintf("invalid job spec: %v", err))
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return dto.ProcessVideoInput{}, domain.NewInvalidInputError("invalid job spec: unexpected data after the spec")
+	}
+
+	if err := spec.validate(); err != nil {
+		return dto.ProcessVideoInput{}, domain.NewInvalidInputError(fmt.Sprintf("invalid job spec: %v", err))
+	}
+	return spec.ProcessVideoInput, nil
+}
+
+func (s JobSpec) validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	switch s.Version {
+	case JobSpecVersion:
+	case 0:
+		add("version is required")
+	default:
+		add("unsupported version %d (supported: %d)", s.Version, JobSpecVersion)
+	}
+	if strings.TrimSpace(s.VideoKey) == "" {
+		add("video_key is required")
+	}
+	if format, err := entity.ParseIDFormat(s.IdFormat); err != nil {
+		add("%v", err)
+	} else {
+		for _, id := range []struct{ name, value string }{{"video_id", s.VideoId}, {"user_id", s.UserId}} {
+			if err := format.Validate(id.name, id.value); err != nil {
+				add("%v", err)
+			}
+		}
+	}
+	if s.Configuration != nil {
+		config := entity.ProcessingConfig{
+			FrameRate:    s.Configuration.FrameRate,
+			OutputFormat: strings.ToLower(strings.TrimSpace(s.Configuration.OutputFormat)),
+			Width:        s.Configuration.Width,
+			Height:       s.Configuration.Height,
+			JPEGQuality:  s.Configuration.JPEGQuality,
+			StartTime:    s.Configuration.StartTime,
+			EndTime:      s.Configuration.EndTime,
+		}
+		if config.OutputFormat == "jpeg" {
+			config.OutputFormat = "jpg"
+		}
+		if err := config.Validate(); err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				add("configuration: %s", line)
+			}
+		}
+	}
+	if s.RetentionPolicy != "" {
+		if _, err := entity.ParseRetentionPolicy(s.RetentionPolicy); err != nil {
+			add("%v", err)
+		}
+	}
+	if err := s.ProcessVideoInput.Validate(); err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			add("%s", line)
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
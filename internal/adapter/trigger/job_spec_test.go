@@ -0,0 +1,139 @@
+package trigger
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+)
+
+// jobSpecSchema is the checked-in JSON Schema of job specs
+var jobSpecSchema = filepath.Join("..", "..", "..", "schemas", "video-job-spec.v1.json")
+
+// exampleJobSpec uses every field of a v1 job spec
+const exampleJobSpec = `{
+	"version": 1,
+	"video_key": "uploads/u-7/video.mp4",
+	"video_id": "01HV6Z3Q8W9X0Y1Z2A3B4C5D6E",
+	"user_id": "u-7",
+	"id_format": "any",
+	"source_etag": "d41d8cd98f00b204e9800998ecf8427e",
+	"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	"retention_policy": "move:archive/",
+	"configuration": {
+		"frame_rate": 0.5,
+		"output_format": "png",
+		"width": 640,
+		"jpeg_quality": 0,
+		"start_time": 10,
+		"end_time": 70.5
+	},
+	"callback": {"url": "https://hooks.example.com/videos", "secret": "s3cr3t"}
+}`
+
+func TestParseJobSpec(t *testing.T) {
+	template := dto.ProcessVideoInput{
+		IdFormat:        "numeric",
+		Configuration:   &dto.ProcessingConfigInput{FrameRate: 1, OutputFormat: "jpg"},
+		RetentionPolicy: "delete",
+	}
+
+	t.Run("example_matches_schema", func(t *testing.T) {
+		r := require.New(t)
+		compiler := jsonschema.NewCompiler()
+		compiler.AssertFormat = true
+		schema, err := compiler.Compile(jobSpecSchema)
+		r.NoError(err)
+
+		var doc any
+		r.NoError(json.Unmarshal([]byte(exampleJobSpec), &doc))
+		r.NoError(schema.Validate(doc))
+
+		input, err := ParseJobSpec([]byte(exampleJobSpec), template)
+		r.NoError(err)
+		r.Equal(dto.ProcessVideoInput{
+			VideoKey:        "uploads/u-7/video.mp4",
+			VideoId:         "01HV6Z3Q8W9X0Y1Z2A3B4C5D6E",
+			UserId:          "u-7",
+			IdFormat:        "any",
+			SourceETag:      "d41d8cd98f00b204e9800998ecf8427e",
+			TraceParent:     "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			RetentionPolicy: "move:archive/",
+			Configuration: &dto.ProcessingConfigInput{
+				FrameRate:    0.5,
+				OutputFormat: "png",
+				Width:        640,
+				StartTime:    10,
+				EndTime:      70.5,
+			},
+			Callback: &dto.CallbackInput{URL: "https://hooks.example.com/videos", Secret: "s3cr3t"},
+		}, input)
+	})
+
+	t.Run("falls_back_to_the_template", func(t *testing.T) {
+		r := require.New(t)
+		input, err := ParseJobSpec([]byte(`{"version":1,"video_key":"a.mp4","video_id":"42","user_id":"7",
+			"configuration":{"width":320}}`), template)
+		r.NoError(err)
+		r.Equal("numeric", input.IdFormat)
+		r.Equal("delete", input.RetentionPolicy)
+		r.Equal(&dto.ProcessingConfigInput{FrameRate: 1, OutputFormat: "jpg", Width: 320}, input.Configuration)
+		r.Zero(template.Configuration.Width, "the template is not modified")
+	})
+
+	t.Run("rejects_invalid_specs", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			spec string
+			want []string
+		}{
+			"unknown_field": {
+				spec: `{"version":1,"video_key":"a.mp4","video_id":"42","user_id":"7","fps":2}`,
+				want: []string{`unknown field "fps"`},
+			},
+			"unknown_nested_field": {
+				spec: `{"version":1,"video_key":"a.mp4","video_id":"42","user_id":"7","configuration":{"scale":2}}`,
+				want: []string{`unknown field "scale"`},
+			},
+			"trailing_data": {
+				spec: `{"version":1,"video_key":"a.mp4","video_id":"42","user_id":"7"} {}`,
+				want: []string{"unexpected data after the spec"},
+			},
+			"missing_version_and_fields": {
+				spec: `{}`,
+				want: []string{"version is required", "video_key is required", "video_id is required", "user_id is required"},
+			},
+			"unsupported_version": {
+				spec: `{"version":2,"video_key":"a.mp4","video_id":"42","user_id":"7"}`,
+				want: []string{"unsupported version 2"},
+			},
+			"invalid_settings": {
+				spec: `{"version":1,"video_key":"a.mp4","video_id":"x","user_id":"7","retention_policy":"archive",
+					"configuration":{"output_format":"gif","jpeg_quality":40,"start_time":5,"end_time":2},
+					"callback":{"url":"ftp://hooks"}}`,
+				want: []string{
+					`video_id "x" is not a valid numeric id`,
+					`unsupported output_format: "gif"`,
+					"jpeg_quality must be between 2 and 31",
+					"end_time must be after start_time",
+					`unsupported retention policy "archive"`,
+					"callback.url must be an http(s) url",
+					"callback.secret is required",
+				},
+			},
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := ParseJobSpec([]byte(tc.spec), template)
+				var inv *domain.InvalidInputError
+				require.ErrorAs(t, err, &inv)
+				for _, want := range tc.want {
+					require.ErrorContains(t, err, want)
+				}
+			})
+		}
+	})
+}
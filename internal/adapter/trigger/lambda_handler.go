@@ -0,0 +1,45 @@
+package trigger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// InvocationRequest is the payload of a direct invocation naming the video to process
+type InvocationRequest struct {
+	VideoKey   string `json:"video_key"`
+	VideoId    string `json:"video_id"`
+	UserId     string `json:"user_id"`
+	SourceETag string `json:"source_etag,omitempty"`
+	// TraceParent is the W3C trace context of the invoker
+	TraceParent string `json:"traceparent,omitempty"`
+}
+
+// LambdaHandler processes the payload of a Lambda invocation: either an InvocationRequest or an S3 notification
+type LambdaHandler struct {
+	s3Trigger *S3EventTrigger
+}
+
+// NewLambdaHandler creates a handler sharing the controller and the request template of s3Trigger
+func NewLambdaHandler(s3Trigger *S3EventTrigger) *LambdaHandler {
+	return &LambdaHandler{s3Trigger: s3Trigger}
+}
+
+// Handle returns the controller response of a direct invocation, or a JSON array with the response of every
+// object of an S3 notification
+func (h *LambdaHandler) Handle(ctx context.Context, payload []byte) ([]byte, error) {
+	var request InvocationRequest
+	if err := json.Unmarshal(payload, &request); err == nil && request.VideoKey != "" {
+		input := h.s3Trigger.config.Input
+		input.VideoKey = request.VideoKey
+		input.VideoId = request.VideoId
+		input.UserId = request.UserId
+		input.SourceETag = request.SourceETag
+		input.TraceParent = request.TraceParent
+		return h.s3Trigger.controller.ProcessVideo(ctx, input)
+	}
+
+	results, err := h.s3Trigger.Handle(ctx, payload)
+	return append(append([]byte("["), bytes.Join(results, []byte(","))...), ']'), err
+}
@@ -0,0 +1,149 @@
+package trigger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// maxEventNesting bounds how many wrappers (e.g. SQS around SNS around S3) are unwrapped
+const maxEventNesting = 3
+
+// S3ObjectCreated is an object announced by an S3 ObjectCreated notification
+type S3ObjectCreated struct {
+	Bucket string
+	// Key is URL-decoded
+	Key  string
+	Size int64
+	// ETag is the revision announced by the event, unquoted as S3 sends it
+	ETag string
+}
+
+// s3Event covers the shapes an S3 notification can arrive in: the raw notification (or a Lambda batch of SNS or SQS
+// records), an SNS notification delivered over HTTP or SQS, an EventBridge event and the S3 test event
+type s3Event struct {
+	Records []s3EventRecord `json:"Records"`
+
+	Type    string `json:"Type"`
+	Message string `json:"Message"`
+
+	Source     string             `json:"source"`
+	DetailType string             `json:"detail-type"`
+	Detail     *eventBridgeDetail `json:"detail"`
+
+	Event string `json:"Event"`
+}
+
+type s3EventRecord struct {
+	// EventSource is aws:s3, aws:sns or aws:sqs; SNS spells the field EventSource, which decodes here too
+	EventSource string `json:"eventSource"`
+	EventName   string `json:"eventName"`
+	S3          *struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key  string `json:"key"`
+			Size int64  `json:"size"`
+			ETag string `json:"eTag"`
+		} `json:"object"`
+	} `json:"s3"`
+	Sns *struct {
+		Message string `json:"Message"`
+	} `json:"Sns"`
+	Body string `json:"body"`
+}
+
+type eventBridgeDetail struct {
+	Bucket struct {
+		Name string `json:"name"`
+	} `json:"bucket"`
+	Object struct {
+		Key  string `json:"key"`
+		Size int64  `json:"size"`
+		ETag string `json:"etag"`
+	} `json:"object"`
+}
+
+// ParseS3Event extracts the created objects from a raw, SNS-wrapped, SQS-wrapped or EventBridge S3 notification.
+// Events other than ObjectCreated, and the S3 test event, yield no objects.
+func ParseS3Event(body []byte) ([]S3ObjectCreated, error) {
+	return parseS3Event(body, 0)
+}
+
+func parseS3Event(body []byte, depth int) ([]S3ObjectCreated, error) {
+	if depth > maxEventNesting {
+		return nil, errors.New("event is nested too deeply")
+	}
+	var event s3Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid event: %w", err)
+	}
+
+	switch {
+	case event.Event == "s3:TestEvent":
+		return nil, nil
+	case event.DetailType != "":
+		if event.Source != "aws.s3" || event.DetailType != "Object Created" || event.Detail == nil {
+			return nil, nil
+		}
+		d := event.Detail
+		object, err := newS3ObjectCreated(d.Bucket.Name, d.Object.Key, d.Object.Size, d.Object.ETag)
+		if err != nil {
+			return nil, err
+		}
+		return []S3ObjectCreated{object}, nil
+	case event.Type == "Notification":
+		return parseS3Event([]byte(event.Message), depth+1)
+	case event.Records != nil:
+		var objects []S3ObjectCreated
+		for i, record := range event.Records {
+			found, err := parseS3EventRecord(record, depth)
+			if err != nil {
+				return nil, fmt.Errorf("record %d: %w", i, err)
+			}
+			objects = append(objects, found...)
+		}
+		return objects, nil
+	default:
+		return nil, errors.New("unsupported event: expected an S3, SNS, SQS or EventBridge notification")
+	}
+}
+
+func parseS3EventRecord(record s3EventRecord, depth int) ([]S3ObjectCreated, error) {
+	switch strings.ToLower(record.EventSource) {
+	case "aws:s3":
+		if !strings.HasPrefix(record.EventName, "ObjectCreated:") || record.S3 == nil {
+			return nil, nil
+		}
+		s3 := record.S3
+		object, err := newS3ObjectCreated(s3.Bucket.Name, s3.Object.Key, s3.Object.Size, s3.Object.ETag)
+		if err != nil {
+			return nil, err
+		}
+		return []S3ObjectCreated{object}, nil
+	case "aws:sns":
+		if record.Sns == nil {
+			return nil, errors.New("SNS record without a message")
+		}
+		return parseS3Event([]byte(record.Sns.Message), depth+1)
+	case "aws:sqs":
+		return parseS3Event([]byte(record.Body), depth+1)
+	default:
+		return nil, fmt.Errorf("unsupported event source %q", record.EventSource)
+	}
+}
+
+// newS3ObjectCreated decodes the key, which S3 notifications URL-encode with spaces as '+'
+func newS3ObjectCreated(bucket, key string, size int64, etag string) (S3ObjectCreated, error) {
+	decoded, err := url.QueryUnescape(key)
+	if err != nil {
+		return S3ObjectCreated{}, fmt.Errorf("invalid object key %q: %w", key, err)
+	}
+	if bucket == "" || decoded == "" {
+		return S3ObjectCreated{}, errors.New("notification is missing the bucket or the object key")
+	}
+	return S3ObjectCreated{Bucket: bucket, Key: decoded, Size: size, ETag: etag}, nil
+}
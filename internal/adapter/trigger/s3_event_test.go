@@ -0,0 +1,176 @@
+package trigger
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+// s3Notification is an S3 ObjectCreated notification as S3 sends it, with a URL-encoded key
+const s3Notification = `{"Records":[{"eventVersion":"2.1","eventSource":"aws:s3","awsRegion":"us-east-1",
+	"eventTime":"2026-01-02T03:04:05.000Z","eventName":"ObjectCreated:Put",
+	"s3":{"s3SchemaVersion":"1.0","bucket":{"name":"videos","arn":"arn:aws:s3:::videos"},
+	"object":{"key":"uploads/u-7/My+Video%C3%A9.mp4","size":1024,"eTag":"d41d8cd98f00b204e9800998ecf8427e","sequencer":"0A1B2C"}}}]}`
+
+// quoteJSON encodes v as a JSON string, the way SNS and SQS embed the inner message
+func quoteJSON(t *testing.T, v string) string {
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestParseS3Event(t *testing.T) {
+	want := []S3ObjectCreated{{Bucket: "videos", Key: "uploads/u-7/My Videoé.mp4", Size: 1024, ETag: "d41d8cd98f00b204e9800998ecf8427e"}}
+	snsNotification := `{"Type":"Notification","MessageId":"m-1","TopicArn":"arn:aws:sns:us-east-1:1:uploads","Message":` +
+		quoteJSON(t, s3Notification) + `}`
+
+	for name, event := range map[string]string{
+		"raw":                s3Notification,
+		"sns_notification":   snsNotification,
+		"sns_lambda_records": `{"Records":[{"EventSource":"aws:sns","Sns":{"Type":"Notification","Message":` + quoteJSON(t, s3Notification) + `}}]}`,
+		"sqs_records":        `{"Records":[{"eventSource":"aws:sqs","messageId":"q-1","body":` + quoteJSON(t, s3Notification) + `}]}`,
+		"sqs_around_sns":     `{"Records":[{"eventSource":"aws:sqs","body":` + quoteJSON(t, snsNotification) + `}]}`,
+		"eventbridge": `{"version":"0","id":"e-1","detail-type":"Object Created","source":"aws.s3","account":"1",
+			"time":"2026-01-02T03:04:05Z","region":"us-east-1","resources":["arn:aws:s3:::videos"],
+			"detail":{"version":"0","bucket":{"name":"videos"},"object":{"key":"uploads/u-7/My+Video%C3%A9.mp4",
+			"size":1024,"etag":"d41d8cd98f00b204e9800998ecf8427e","sequencer":"0A1B2C"},"reason":"PutObject"}}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			objects, err := ParseS3Event([]byte(event))
+			require.NoError(t, err)
+			require.Equal(t, want, objects)
+		})
+	}
+
+	t.Run("ignored_events", func(t *testing.T) {
+		for name, event := range map[string]string{
+			"test_event":          `{"Service":"Amazon S3","Event":"s3:TestEvent","Bucket":"videos"}`,
+			"object_removed":      `{"Records":[{"eventSource":"aws:s3","eventName":"ObjectRemoved:Delete","s3":{"bucket":{"name":"videos"},"object":{"key":"a.mp4"}}}]}`,
+			"eventbridge_deleted": `{"detail-type":"Object Deleted","source":"aws.s3","detail":{"bucket":{"name":"videos"},"object":{"key":"a.mp4"}}}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				objects, err := ParseS3Event([]byte(event))
+				require.NoError(t, err)
+				require.Empty(t, objects)
+			})
+		}
+	})
+
+	t.Run("invalid_events", func(t *testing.T) {
+		for name, event := range map[string]string{
+			"not_json":       `VIDEO_KEY=a.mp4`,
+			"unknown_shape":  `{"key":"a.mp4"}`,
+			"unknown_source": `{"Records":[{"eventSource":"aws:kinesis"}]}`,
+			"bad_escape":     `{"Records":[{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"videos"},"object":{"key":"a%zz.mp4"}}}]}`,
+			"missing_bucket": `{"Records":[{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{},"object":{"key":"a.mp4"}}}]}`,
+			"sns_not_s3":     `{"Type":"Notification","Message":"hello"}`,
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := ParseS3Event([]byte(event))
+				require.Error(t, err)
+			})
+		}
+	})
+}
+
+func TestS3EventTrigger(t *testing.T) {
+	template := dto.ProcessVideoInput{RetentionPolicy: "keep", IdFormat: "any"}
+	key := "uploads/u-7/My Videoé.mp4"
+
+	newTrigger := func(t *testing.T, pattern string) (*S3EventTrigger, *pmocks.MockVideoController, *pmocks.MockVideoGateway) {
+		ctrl := gomock.NewController(t)
+		vc := pmocks.NewMockVideoController(ctrl)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		config := S3EventTriggerConfig{Bucket: "videos", Input: template}
+		if pattern != "" {
+			config.KeyPattern = regexp.MustCompile(pattern)
+		}
+		return NewS3EventTrigger(vc, vg, config, logger.NewSlogLogger()), vc, vg
+	}
+
+	t.Run("ids_from_metadata", func(t *testing.T) {
+		r := require.New(t)
+		trg, vc, vg := newTrigger(t, "")
+		vg.EXPECT().Stat(gomock.Any(), key).Return(&entity.StorageObject{
+			Key:      key,
+			Metadata: map[string]string{VideoIdMetadata: "0b9e4c6e-1f0a-4d7b-9a52-3f1d2c8e7a10", UserIdMetadata: "u-7"},
+		}, nil)
+
+		want := template
+		want.VideoKey = key
+		want.VideoId = "0b9e4c6e-1f0a-4d7b-9a52-3f1d2c8e7a10"
+		want.UserId = "u-7"
+		want.SourceETag = `"d41d8cd98f00b204e9800998ecf8427e"`
+		vc.EXPECT().ProcessVideo(gomock.Any(), want).Return([]byte(`{"success":true}`), nil)
+
+		results, err := trg.Handle(context.Background(), []byte(s3Notification))
+		r.NoError(err)
+		r.Equal([][]byte{[]byte(`{"success":true}`)}, results)
+	})
+
+	t.Run("ids_from_key_pattern", func(t *testing.T) {
+		r := require.New(t)
+		trg, vc, vg := newTrigger(t, `^uploads/(?P<user_id>[^/]+)/(?P<video_id>[^/]+)\.mp4$`)
+		vg.EXPECT().Stat(gomock.Any(), key).Return(&entity.StorageObject{Key: key, Metadata: map[string]string{}}, nil)
+		vc.EXPECT().ProcessVideo(gomock.Any(), gomock.Cond(func(in dto.ProcessVideoInput) bool {
+			return in.VideoId == "My Videoé" && in.UserId == "u-7" && in.RetentionPolicy == "keep"
+		})).Return([]byte(`{}`), nil)
+
+		_, err := trg.Handle(context.Background(), []byte(s3Notification))
+		r.NoError(err)
+	})
+
+	t.Run("missing_ids_are_rejected", func(t *testing.T) {
+		trg, _, vg := newTrigger(t, `^other/(?P<video_id>.+)$`)
+		vg.EXPECT().Stat(gomock.Any(), key).Return(&entity.StorageObject{Key: key, Metadata: map[string]string{VideoIdMetadata: "v-1"}}, nil)
+
+		_, err := trg.Handle(context.Background(), []byte(s3Notification))
+		var inv *domain.InvalidInputError
+		require.ErrorAs(t, err, &inv)
+	})
+
+	t.Run("other_bucket_is_rejected", func(t *testing.T) {
+		trg, _, _ := newTrigger(t, "")
+		event := `{"detail-type":"Object Created","source":"aws.s3","detail":{"bucket":{"name":"elsewhere"},"object":{"key":"a.mp4"}}}`
+
+		_, err := trg.Handle(context.Background(), []byte(event))
+		require.ErrorContains(t, err, `bucket "elsewhere" is not the video bucket`)
+	})
+
+	t.Run("failures_do_not_stop_other_records", func(t *testing.T) {
+		r := require.New(t)
+		trg, vc, vg := newTrigger(t, "")
+		event := `{"Records":[
+			{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"videos"},"object":{"key":"a.mp4","eTag":"e1"}}},
+			{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"videos"},"object":{"key":"b.mp4","eTag":"e2"}}}]}`
+		ids := map[string]string{VideoIdMetadata: "1", UserIdMetadata: "2"}
+		vg.EXPECT().Stat(gomock.Any(), "a.mp4").Return(&entity.StorageObject{Metadata: ids}, nil)
+		vg.EXPECT().Stat(gomock.Any(), "b.mp4").Return(&entity.StorageObject{Metadata: ids}, nil)
+		vc.EXPECT().ProcessVideo(gomock.Any(), gomock.Cond(func(in dto.ProcessVideoInput) bool { return in.VideoKey == "a.mp4" })).
+			Return([]byte(`{"success":false}`), errors.New("boom"))
+		vc.EXPECT().ProcessVideo(gomock.Any(), gomock.Cond(func(in dto.ProcessVideoInput) bool { return in.VideoKey == "b.mp4" })).
+			Return([]byte(`{"success":true}`), nil)
+
+		results, err := trg.Handle(context.Background(), []byte(event))
+		r.ErrorContains(err, "s3://videos/a.mp4: boom")
+		r.Len(results, 2)
+	})
+
+	t.Run("invalid_event", func(t *testing.T) {
+		trg, _, _ := newTrigger(t, "")
+		_, err := trg.Handle(context.Background(), []byte(`{}`))
+		var inv *domain.InvalidInputError
+		require.ErrorAs(t, err, &inv)
+	})
+}
@@ -0,0 +1,135 @@
+package trigger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+const (
+	// VideoIdMetadata and UserIdMetadata are the object metadata (x-amz-meta-*) that carry the IDs
+	VideoIdMetadata = entity.VideoIdMetadata
+	UserIdMetadata  = entity.UserIdMetadata
+
+	// KeyPatternVideoId and KeyPatternUserId are the named groups read from the key pattern
+	KeyPatternVideoId = entity.KeyPatternVideoId
+	KeyPatternUserId  = entity.KeyPatternUserId
+)
+
+// S3EventTriggerConfig configures how S3 notifications become processing requests
+type S3EventTriggerConfig struct {
+	// Bucket is the video bucket; objects created in other buckets are rejected
+	Bucket string
+	// KeyPattern extracts the IDs from the key through the named groups video_id and user_id when the object
+	// metadata does not carry them, e.g. ^uploads/(?P<user_id>[^/]+)/(?P<video_id>[^/]+)/
+	KeyPattern *regexp.Regexp
+	// MaxObjectSize returns the size above which objects are rejected before they are downloaded, e.g. when temp
+	// space is bounded; it is read once per event, and nil or 0 is unlimited
+	MaxObjectSize func() int64
+	// Input is the template of every request; the key, the IDs and the ETag are set per object
+	Input dto.ProcessVideoInput
+}
+
+// S3EventTrigger processes the objects announced by S3 ObjectCreated notifications
+type S3EventTrigger struct {
+	controller   port.VideoController
+	videoGateway port.VideoGateway
+	config       S3EventTriggerConfig
+	logger       logger.Logger
+}
+
+// NewS3EventTrigger creates a trigger that runs controller once per created object; videoGateway reads object metadata
+func NewS3EventTrigger(controller port.VideoController, videoGateway port.VideoGateway, config S3EventTriggerConfig, logger logger.Logger) *S3EventTrigger {
+	return &S3EventTrigger{
+		controller:   controller,
+		videoGateway: videoGateway,
+		config:       config,
+		logger:       logger,
+	}
+}
+
+// Handle processes every object created by the event, one at a time, and returns the responses in order.
+// A failed object does not stop the others; the error joins the failure of every object.
+func (t *S3EventTrigger) Handle(ctx context.Context, event []byte) ([][]byte, error) {
+	objects, err := ParseS3Event(event)
+	if err != nil {
+		return nil, domain.NewInvalidInputError(err.Error())
+	}
+	t.logger.WithContext(ctx).Info("S3 event received", "objects", len(objects))
+
+	var maxObjectSize int64
+	if t.config.MaxObjectSize != nil {
+		maxObjectSize = t.config.MaxObjectSize()
+	}
+
+	var results [][]byte
+	var errs []error
+	for _, object := range objects {
+		result, err := t.process(ctx, object, maxObjectSize)
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("s3://%s/%s: %w", object.Bucket, object.Key, err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+func (t *S3EventTrigger) process(ctx context.Context, object S3ObjectCreated, maxObjectSize int64) ([]byte, error) {
+	log := t.logger.WithContext(ctx).With("bucket", object.Bucket, "video_key", object.Key, "size", object.Size, "etag", object.ETag)
+
+	if t.config.Bucket != "" && object.Bucket != t.config.Bucket {
+		log.Warn("Object created outside the video bucket, skipping")
+		return nil, domain.NewInvalidInputError(fmt.Sprintf("bucket %q is not the video bucket %q", object.Bucket, t.config.Bucket))
+	}
+	if maxObjectSize > 0 && object.Size > maxObjectSize {
+		log.Warn("Object too large for the available temp space, skipping", "max_size", maxObjectSize)
+		return nil, domain.NewInvalidInputError(fmt.Sprintf("object of %d bytes exceeds the limit of %d bytes", object.Size, maxObjectSize))
+	}
+
+	videoId, userId, err := t.resolveIDs(ctx, object.Key)
+	if err != nil {
+		log.Warn("Failed to resolve video and user IDs", "error", err)
+		return nil, err
+	}
+
+	input := t.config.Input
+	input.VideoKey = object.Key
+	input.VideoId = videoId
+	input.UserId = userId
+	input.SourceETag = quoteETag(object.ETag)
+
+	log.Info("Processing object from S3 event", "video_id", videoId, "user_id", userId)
+	return t.controller.ProcessVideo(ctx, input)
+}
+
+// resolveIDs reads the IDs from the object metadata, falling back to the key pattern for the missing ones
+func (t *S3EventTrigger) resolveIDs(ctx context.Context, key string) (string, string, error) {
+	object, err := t.videoGateway.Stat(ctx, key)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read object metadata: %w", err)
+	}
+	videoId, userId := object.IDs(t.config.KeyPattern)
+	if videoId == "" || userId == "" {
+		return "", "", domain.NewInvalidInputError(fmt.Sprintf(
+			"video and user IDs not found in the %s/%s metadata nor in the key", VideoIdMetadata, UserIdMetadata))
+	}
+	return videoId, userId, nil
+}
+
+// quoteETag returns the ETag in the quoted form S3 uses in headers; S3 notifications send it bare
+func quoteETag(etag string) string {
+	if etag == "" || strings.HasPrefix(etag, `"`) {
+		return etag
+	}
+	return `"` + etag + `"`
+}
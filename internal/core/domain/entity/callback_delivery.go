@@ -0,0 +1,8 @@
+package entity
+
+// CallbackDelivery describes how a callback request went
+type CallbackDelivery struct {
+	Attempts int
+	// StatusCode is the HTTP status of the last response, zero when no response was received
+	StatusCode int
+}
@@ -0,0 +1,23 @@
+package entity
+
+// FrameArchive describes a frame archive checked by VerifyArchive
+type FrameArchive struct {
+	Size int64
+	// SHA256 is the hex digest of the whole archive
+	SHA256     string
+	FrameCount int
+	// Format is the image format of the frames (jpg or png)
+	Format string
+	Width  int
+	Height int
+	// FirstFrame and LastFrame are the numbers in the frame names (frame_0000.jpg)
+	FirstFrame int
+	LastFrame  int
+	// Problems lists everything that makes the archive invalid; empty when the archive is intact
+	Problems []string
+}
+
+// Valid reports whether no problem was found in the archive
+func (a FrameArchive) Valid() bool {
+	return len(a.Problems) == 0
+}
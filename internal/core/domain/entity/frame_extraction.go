@@ -0,0 +1,12 @@
+package entity
+
+// FrameExtraction describes the frame archive produced by ProcessVideo and how it was produced
+type FrameExtraction struct {
+	FrameCount int
+	// ArchivePath is the local zip holding the frames
+	ArchivePath string
+	// Commands are the ffmpeg command lines that were run, one per segment
+	Commands []string
+	// FFmpegVersion is the first line of `ffmpeg -version`; empty when it could not be detected
+	FFmpegVersion string
+}
@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IDFormat is the shape video and user IDs are expected to have. IDs are opaque strings;
+// the format only adds validation for callers that use a known scheme.
+type IDFormat string
+
+const (
+	// IDFormatAny accepts any non-blank ID
+	IDFormatAny     IDFormat = "any"
+	IDFormatNumeric IDFormat = "numeric"
+	IDFormatUUID    IDFormat = "uuid"
+	IDFormatULID    IDFormat = "ulid"
+)
+
+var (
+	numericIDPattern = regexp.MustCompile(`^[0-9]+$`)
+	uuidPattern      = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	// ulidPattern is 26 Crockford base32 characters; the first one is at most 7 so the timestamp fits in 48 bits
+	ulidPattern = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Za-hjkmnp-tv-z]{25}$`)
+)
+
+// ParseIDFormat parses any, numeric, uuid or ulid. An empty value means any.
+func ParseIDFormat(value string) (IDFormat, error) {
+	switch format := IDFormat(strings.ToLower(strings.TrimSpace(value))); format {
+	case "":
+		return IDFormatAny, nil
+	case IDFormatAny, IDFormatNumeric, IDFormatUUID, IDFormatULID:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unsupported id format %q (allowed: any, numeric, uuid, ulid)", value)
+	}
+}
+
+// Validate checks that id matches the format; name identifies the ID in the error
+func (f IDFormat) Validate(name, id string) error {
+	if strings.TrimSpace(id) == "" {
+		return fmt.Errorf("%s is required", name)
+	}
+
+	var pattern *regexp.Regexp
+	switch f {
+	case IDFormatNumeric:
+		pattern = numericIDPattern
+	case IDFormatUUID:
+		pattern = uuidPattern
+	case IDFormatULID:
+		pattern = ulidPattern
+	default:
+		return nil
+	}
+	if !pattern.MatchString(id) {
+		return fmt.Errorf("%s %q is not a valid %s id", name, id, f)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package entity
+
+// Message is an event published to the message broker.
+// ID identifies the event itself, so retries and replays of the same event can be de-duplicated.
+type Message struct {
+	ID   string
+	Body []byte
+	// Attributes are exposed by brokers that support them (e.g. SNS filter policies) without parsing the body
+	Attributes map[string]string
+	// GroupID orders related messages (e.g. the updates of one video) on brokers that support ordered groups
+	GroupID string
+	// AwaitDelivery makes brokers that deliver asynchronously, like the outbox, return only once it was delivered
+	AwaitDelivery bool
+}
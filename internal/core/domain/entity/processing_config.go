@@ -1,7 +1,46 @@
 package entity
 
+import (
+	"errors"
+	"fmt"
+)
+
 // ProcessingConfig contains configuration for video processing
 type ProcessingConfig struct {
 	FrameRate    float64
 	OutputFormat string
+	// Width and Height scale the frames, in pixels; when only one is set the other keeps the aspect ratio.
+	// Zero keeps the source size.
+	Width  int
+	Height int
+	// JPEGQuality is the ffmpeg quality scale of jpg frames, from 2 (best) to 31; zero uses the default
+	JPEGQuality int
+	// StartTime and EndTime, in seconds, limit the extraction to a window of the video; a zero EndTime extracts
+	// until the end
+	StartTime float64
+	EndTime   float64
+}
+
+// Validate reports every invalid setting of the configuration
+func (c ProcessingConfig) Validate() error {
+	var errs []error
+	if c.FrameRate <= 0 {
+		errs = append(errs, fmt.Errorf("frame_rate must be greater than 0, got %v", c.FrameRate))
+	}
+	if c.OutputFormat != "jpg" && c.OutputFormat != "png" {
+		errs = append(errs, fmt.Errorf("unsupported output_format: %q (allowed: jpg, png)", c.OutputFormat))
+	}
+	if c.Width < 0 || c.Height < 0 {
+		errs = append(errs, fmt.Errorf("width and height must not be negative, got %dx%d", c.Width, c.Height))
+	}
+	if c.JPEGQuality != 0 && (c.JPEGQuality < 2 || c.JPEGQuality > 31) {
+		errs = append(errs, fmt.Errorf("jpeg_quality must be between 2 and 31, got %d", c.JPEGQuality))
+	}
+	if c.StartTime < 0 {
+		errs = append(errs, fmt.Errorf("start_time must not be negative, got %v", c.StartTime))
+	}
+	if c.EndTime != 0 && c.EndTime <= c.StartTime {
+		errs = append(errs, fmt.Errorf("end_time must be after start_time, got %v <= %v", c.EndTime, c.StartTime))
+	}
+	return errors.Join(errs...)
 }
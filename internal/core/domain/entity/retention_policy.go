@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RetentionAction is what happens to the original video once processing succeeds
+type RetentionAction string
+
+const (
+	RetentionDelete RetentionAction = "delete"
+	RetentionKeep   RetentionAction = "keep"
+	RetentionMove   RetentionAction = "move"
+	RetentionTag    RetentionAction = "tag"
+)
+
+// RetentionPolicy describes how the original video is retained after processing
+type RetentionPolicy struct {
+	Action RetentionAction
+	// Bucket is the destination bucket of a move; empty keeps the object in the video bucket
+	Bucket string
+	// Prefix is prepended to the original key on a move
+	Prefix   string
+	TagKey   string
+	TagValue string
+}
+
+// ParseRetentionPolicy parses a policy in the form delete, keep, move:<prefix>,
+// move:s3://<bucket>/<prefix> or tag:<key>=<value>. An empty value means delete.
+func ParseRetentionPolicy(value string) (RetentionPolicy, error) {
+	value = strings.TrimSpace(value)
+	action, arg, hasArg := strings.Cut(value, ":")
+
+	switch RetentionAction(strings.ToLower(action)) {
+	case "", RetentionDelete:
+		if hasArg {
+			return RetentionPolicy{}, fmt.Errorf("retention policy %q does not take an argument", value)
+		}
+		return RetentionPolicy{Action: RetentionDelete}, nil
+	case RetentionKeep:
+		if hasArg {
+			return RetentionPolicy{}, fmt.Errorf("retention policy %q does not take an argument", value)
+		}
+		return RetentionPolicy{Action: RetentionKeep}, nil
+	case RetentionMove:
+		policy := RetentionPolicy{Action: RetentionMove, Prefix: arg}
+		if rest, ok := strings.CutPrefix(arg, "s3://"); ok {
+			policy.Bucket, policy.Prefix, _ = strings.Cut(rest, "/")
+			if policy.Bucket == "" {
+				return RetentionPolicy{}, fmt.Errorf("retention policy %q is missing the destination bucket", value)
+			}
+		} else if policy.Prefix == "" {
+			return RetentionPolicy{}, fmt.Errorf("retention policy %q is missing the destination prefix", value)
+		}
+		return policy, nil
+	case RetentionTag:
+		key, val, ok := strings.Cut(arg, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return RetentionPolicy{}, fmt.Errorf("retention policy %q must be in the form tag:<key>=<value>", value)
+		}
+		return RetentionPolicy{Action: RetentionTag, TagKey: key, TagValue: strings.TrimSpace(val)}, nil
+	default:
+		return RetentionPolicy{}, fmt.Errorf("unsupported retention policy %q (allowed: delete, keep, move:<prefix>, tag:<key>=<value>)", value)
+	}
+}
+
+// DestinationKey returns the key the original video is moved to
+func (p RetentionPolicy) DestinationKey(key string) string {
+	return p.Prefix + key
+}
+
+// String renders the policy back into its configuration form
+func (p RetentionPolicy) String() string {
+	switch p.Action {
+	case RetentionMove:
+		if p.Bucket != "" {
+			return fmt.Sprintf("move:s3://%s/%s", p.Bucket, p.Prefix)
+		}
+		return "move:" + p.Prefix
+	case RetentionTag:
+		return fmt.Sprintf("tag:%s=%s", p.TagKey, p.TagValue)
+	default:
+		return string(p.Action)
+	}
+}
@@ -0,0 +1,59 @@
+package entity
+
+import "regexp"
+
+const (
+	// VideoIdMetadata and UserIdMetadata are the object metadata (x-amz-meta-*) that carry the IDs
+	VideoIdMetadata = "video-id"
+	UserIdMetadata  = "user-id"
+
+	// KeyPatternVideoId and KeyPatternUserId are the named groups read from a key pattern
+	KeyPatternVideoId = "video_id"
+	KeyPatternUserId  = "user_id"
+)
+
+// ObjectVersion identifies the exact revision of a stored object
+type ObjectVersion struct {
+	ETag      string
+	VersionId string
+}
+
+// IsZero reports whether no revision information is known, making operations unconditional
+func (v ObjectVersion) IsZero() bool {
+	return v.ETag == "" && v.VersionId == ""
+}
+
+// StorageObject describes an object stored in the video bucket
+type StorageObject struct {
+	Key     string
+	Size    int64
+	Version ObjectVersion
+	// Metadata is the user-defined metadata of the object, with lowercase keys; only filled by Stat
+	Metadata map[string]string
+}
+
+// IDs reads the video and user IDs from the metadata, falling back to the named groups of keyPattern, when given,
+// for the missing ones. An ID found nowhere is empty.
+func (o StorageObject) IDs(keyPattern *regexp.Regexp) (videoId, userId string) {
+	videoId = o.Metadata[VideoIdMetadata]
+	userId = o.Metadata[UserIdMetadata]
+
+	if (videoId == "" || userId == "") && keyPattern != nil {
+		if match := keyPattern.FindStringSubmatch(o.Key); match != nil {
+			if videoId == "" {
+				videoId = namedGroup(keyPattern, match, KeyPatternVideoId)
+			}
+			if userId == "" {
+				userId = namedGroup(keyPattern, match, KeyPatternUserId)
+			}
+		}
+	}
+	return videoId, userId
+}
+
+func namedGroup(pattern *regexp.Regexp, match []string, name string) string {
+	if i := pattern.SubexpIndex(name); i >= 0 {
+		return match[i]
+	}
+	return ""
+}
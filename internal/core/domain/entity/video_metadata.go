@@ -0,0 +1,33 @@
+package entity
+
+// VideoMetadata describes a media file as reported by ffprobe
+type VideoMetadata struct {
+	FormatName string
+	// Duration is in seconds; zero when the container does not declare it
+	Duration float64
+	Size     int64
+	BitRate  int64
+	Streams  []StreamMetadata
+}
+
+// StreamMetadata describes one stream of a media file
+type StreamMetadata struct {
+	Index     int
+	CodecType string
+	CodecName string
+	// Width, Height and FrameRate are only set for video streams
+	Width     int
+	Height    int
+	FrameRate float64
+	Duration  float64
+}
+
+// ProcessorCapabilities describes the ffmpeg installation used to extract frames
+type ProcessorCapabilities struct {
+	// FFmpegVersion and FFprobeVersion are the first line of -version; empty when the tool is missing
+	FFmpegVersion  string
+	FFprobeVersion string
+	// Encoders are the frame encoders (mjpeg, png) ffmpeg provides
+	Encoders []string
+	HWAccels []string
+}
@@ -1,7 +1,10 @@
 package domain
 
+import "errors"
+
 var (
 	ErrConflict           = "data conflicts with existing data"
+	ErrSourceChanged      = "source object changed since it was downloaded"
 	ErrNotFound           = "data not found"
 	ErrInvalidParam       = "invalid parameter"
 	ErrInvalidQueryParams = "invalid query parameters"
@@ -13,6 +16,37 @@ var (
 	ErrInvalidInput    = "invalid input"
 )
 
+// ErrorCode is a stable, machine-readable identifier of an error class, exposed in responses, callbacks and status
+// events. Codes are never renamed; new classes get new codes.
+type ErrorCode string
+
+const (
+	ErrorCodeInvalidInput ErrorCode = "INVALID_INPUT"
+	ErrorCodeValidation   ErrorCode = "VALIDATION_ERROR"
+	ErrorCodeNotFound     ErrorCode = "NOT_FOUND"
+	ErrorCodeConflict     ErrorCode = "CONFLICT"
+	ErrorCodeInternal     ErrorCode = "INTERNAL_ERROR"
+)
+
+// Error is implemented by every domain error
+type Error interface {
+	error
+	Code() ErrorCode
+}
+
+// Stage is the step of the processing that failed
+type Stage string
+
+const (
+	StageInput    Stage = "input"
+	StageDownload Stage = "download"
+	StageValidate Stage = "validate"
+	StageExtract  Stage = "extract"
+	StageUpload   Stage = "upload"
+	StageVerify   Stage = "verify"
+	StagePublish  Stage = "publish"
+)
+
 type ValidationError struct {
 	Message string
 	Err     error
@@ -25,6 +59,10 @@ func (e *ValidationError) Error() string {
 	return e.Message
 }
 
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+func (e *ValidationError) Code() ErrorCode { return ErrorCodeValidation }
+
 type NotFoundError struct {
 	Message string
 }
@@ -33,9 +71,14 @@ func (e *NotFoundError) Error() string {
 	return e.Message
 }
 
+func (e *NotFoundError) Code() ErrorCode { return ErrorCodeNotFound }
+
 type InternalError struct {
 	Message string
 	Err     error
+	// Retryable marks a transient infrastructure failure, e.g. an unreachable storage or broker, that a new run of the
+	// same job may not hit
+	Retryable bool
 }
 
 func (e *InternalError) Error() string {
@@ -45,12 +88,36 @@ func (e *InternalError) Error() string {
 	return e.Message
 }
 
+func (e *InternalError) Unwrap() error { return e.Err }
+
+func (e *InternalError) Code() ErrorCode { return ErrorCodeInternal }
+
+type ConflictError struct {
+	Message string
+}
+
+func (e *ConflictError) Error() string { return e.Message }
+
+func (e *ConflictError) Code() ErrorCode { return ErrorCodeConflict }
+
 type InvalidInputError struct {
 	Message string
 }
 
 func (e *InvalidInputError) Error() string { return e.Message }
 
+func (e *InvalidInputError) Code() ErrorCode { return ErrorCodeInvalidInput }
+
+// StageError records the stage an error happened in; the error itself keeps its class
+type StageError struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *StageError) Error() string { return e.Err.Error() }
+
+func (e *StageError) Unwrap() error { return e.Err }
+
 func NewValidationError(err error) *ValidationError {
 	return &ValidationError{Message: ErrValidationError, Err: err}
 }
@@ -63,6 +130,62 @@ func NewInternalError(err error) *InternalError {
 	return &InternalError{Message: ErrInternalError, Err: err}
 }
 
+// NewRetryableError returns an internal error marked as transient
+func NewRetryableError(err error) *InternalError {
+	return &InternalError{Message: ErrInternalError, Err: err, Retryable: true}
+}
+
+// WithStage records the stage err happened in; a nil err stays nil
+func WithStage(stage Stage, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &StageError{Stage: stage, Err: err}
+}
+
+// CodeOf returns the code of the outermost domain error in err's chain; any other error is internal
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	var dErr Error
+	if errors.As(err, &dErr) {
+		return dErr.Code()
+	}
+	return ErrorCodeInternal
+}
+
+// StageOf returns the stage recorded in err's chain, empty when unknown
+func StageOf(err error) Stage {
+	var sErr *StageError
+	if errors.As(err, &sErr) {
+		return sErr.Stage
+	}
+	return ""
+}
+
+// IsRetryable reports whether err, or any error it wraps or joins, is a transient internal error
+func IsRetryable(err error) bool {
+	if iErr, ok := err.(*InternalError); ok && iErr.Retryable {
+		return true
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return IsRetryable(e.Unwrap())
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if IsRetryable(inner) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func NewInvalidInputError(message string) *InvalidInputError {
 	return &InvalidInputError{Message: message}
 }
+
+func NewConflictError(message string) *ConflictError {
+	return &ConflictError{Message: message}
+}
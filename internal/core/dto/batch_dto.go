@@ -0,0 +1,45 @@
+package dto
+
+import (
+	"regexp"
+	"time"
+)
+
+// ProcessBatchInput represents the input for processing several videos in one job
+type ProcessBatchInput struct {
+	VideoKeys     []string
+	Prefix        string
+	Concurrency   int
+	MaxTempBytes  int64
+	Configuration *ProcessingConfigInput
+	// RetentionPolicy is applied to every original video of the batch
+	RetentionPolicy string
+	// IdFormat validates the IDs of every video, as in ProcessVideoInput
+	IdFormat string
+	// KeyPattern extracts the IDs from the key through the named groups video_id and user_id when the object
+	// metadata does not carry them
+	KeyPattern *regexp.Regexp
+}
+
+// BatchItemResult represents the outcome of a single video inside a batch
+type BatchItemResult struct {
+	VideoKey   string
+	Success    bool
+	OutputKey  string
+	FrameCount int
+	Hash       string
+	Error      string
+	ErrorCode  string
+	Stage      string
+	Retryable  bool
+	Duration   time.Duration
+}
+
+// ProcessBatchOutput represents the output of a batch run
+type ProcessBatchOutput struct {
+	Total     int
+	Succeeded int
+	Failed    int
+	ReportKey string
+	Results   []BatchItemResult
+}
@@ -0,0 +1,85 @@
+package dto
+
+// ProbeInput represents a request to inspect a video
+type ProbeInput struct {
+	// Source is a local file path or, when no such file exists, a key in the video bucket
+	Source string
+}
+
+// ProbeOutput represents the metadata of a probed video
+type ProbeOutput struct {
+	Source string
+	// FromStorage is set when Source was downloaded from the video bucket
+	FromStorage bool
+	FormatName  string
+	Duration    float64
+	Size        int64
+	BitRate     int64
+	Streams     []StreamOutput
+}
+
+// StreamOutput represents one stream of a probed video
+type StreamOutput struct {
+	Index     int
+	CodecType string
+	CodecName string
+	Width     int
+	Height    int
+	FrameRate float64
+	Duration  float64
+}
+
+// ExtractInput represents a request to extract the frames of a local video, without any cloud service
+type ExtractInput struct {
+	VideoPath string
+	// OutputPath is where the frame archive is written; next to the video when empty
+	OutputPath    string
+	Configuration *ProcessingConfigInput
+}
+
+// ExtractOutput represents the frame archive written by an extraction
+type ExtractOutput struct {
+	VideoPath  string
+	OutputPath string
+	FrameCount int
+	Size       int64
+}
+
+// VerifyInput represents a request to check the integrity of a local frame archive
+type VerifyInput struct {
+	ArchivePath string
+}
+
+// VerifyOutput represents the outcome of an archive verification
+type VerifyOutput struct {
+	ArchivePath string
+	Valid       bool
+	Size        int64
+	SHA256      string
+	FrameCount  int
+	Format      string
+	Width       int
+	Height      int
+	FirstFrame  int
+	LastFrame   int
+	Problems    []string
+}
+
+// VersionInput represents the build information of the binary
+type VersionInput struct {
+	Version   string
+	Commit    string
+	BuildDate string
+	GoVersion string
+}
+
+// VersionOutput represents the build information and the detected ffmpeg capabilities
+type VersionOutput struct {
+	VersionInput
+	FFmpegVersion  string
+	FFprobeVersion string
+	Encoders       []string
+	HWAccels       []string
+	// CapabilitiesError explains what could not be detected, e.g. a missing ffmpeg
+	CapabilitiesError string
+}
@@ -0,0 +1,48 @@
+package dto
+
+import "time"
+
+// ProcessingReport represents the report.json uploaded next to the result archive, describing how a job ran
+type ProcessingReport struct {
+	VideoKey string
+	VideoId  string
+	UserId   string
+	// Hash and SourceETag identify the processed revision; empty when the download failed
+	Hash       string
+	SourceETag string
+	// Status is FINISHED or FAILED, as published in the status event
+	Status     string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Configuration is the effective configuration, with the defaults filled in
+	Configuration *ProcessingConfigInput
+	// Metadata is the probe of the downloaded video; nil when it was not downloaded or could not be probed
+	Metadata *ProbeOutput
+	Stages   []StageTiming
+	// SourceCache is the outcome of the source cache lookup: hit or miss; empty when the cache was not used
+	SourceCache     string
+	BytesDownloaded int64
+	BytesUploaded   int64
+	FFmpegVersion   string
+	// FFmpegCommands are the command lines of the extraction, one per segment
+	FFmpegCommands []string
+	FrameCount     int
+	OutputKey      string
+	Retention      *RetentionOutput
+	// Warnings are the problems that did not fail the job, e.g. a video that could not be probed
+	Warnings []string
+	Failure  *FailureOutput
+}
+
+const (
+	// SourceCacheHit means the original video was read from the source cache instead of being downloaded
+	SourceCacheHit  = "hit"
+	SourceCacheMiss = "miss"
+)
+
+// StageTiming represents one run of a processing stage; a stage may run more than once, e.g. publish
+type StageTiming struct {
+	Stage    string
+	Duration time.Duration
+	Error    string
+}
@@ -1,17 +1,63 @@
 package dto
 
+import (
+	"errors"
+	"net/url"
+)
+
 // ProcessingConfigInput represents the input for video processing configuration
 type ProcessingConfigInput struct {
-	FrameRate    float64
-	OutputFormat string
+	FrameRate    float64 `json:"frame_rate"`
+	OutputFormat string  `json:"output_format"`
+	// Width and Height scale the frames, in pixels; zero keeps the source size (or the aspect ratio if one is set)
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// JPEGQuality is the ffmpeg quality scale of jpg frames, from 2 (best) to 31; zero uses the default
+	JPEGQuality int `json:"jpeg_quality,omitempty"`
+	// StartTime and EndTime, in seconds, limit the extraction to a window of the video; zero EndTime means the end
+	StartTime float64 `json:"start_time,omitempty"`
+	EndTime   float64 `json:"end_time,omitempty"`
 }
 
-// ProcessVideoInput represents the input for video processing
+// ProcessVideoInput represents the input for video processing; it is also the body of a JSON job spec
 type ProcessVideoInput struct {
-	VideoKey      string
-	UserId        string
-	VideoId       string
-	Configuration *ProcessingConfigInput
+	VideoKey string `json:"video_key"`
+	// UserId and VideoId are opaque identifiers echoed in status events and callbacks
+	UserId  string `json:"user_id"`
+	VideoId string `json:"video_id"`
+	// IdFormat validates UserId and VideoId (any, numeric, uuid, ulid); IDs are not validated when empty
+	IdFormat string `json:"id_format,omitempty"`
+	// SourceETag pins the revision to process (e.g. the ETag of an S3 event); the latest revision is used when empty
+	SourceETag    string                 `json:"source_etag,omitempty"`
+	Configuration *ProcessingConfigInput `json:"configuration,omitempty"`
+	// RetentionPolicy selects what happens to the original video after processing (delete, keep, move:<prefix>, tag:<key>=<value>)
+	RetentionPolicy string `json:"retention_policy,omitempty"`
+	// Callback is an optional endpoint notified with the final result
+	Callback *CallbackInput `json:"callback,omitempty"`
+	// TraceParent is the W3C trace context of the requester; the processing spans continue its trace
+	TraceParent string `json:"traceparent,omitempty"`
+}
+
+// CallbackInput represents an HTTP endpoint notified when the job finishes
+type CallbackInput struct {
+	URL string `json:"url"`
+	// Secret signs the payload with HMAC-SHA256; it is required, so the receiver can authenticate the result
+	Secret string `json:"secret,omitempty"`
+}
+
+// Validate checks the callback of the input, if any; every problem is reported on its own line
+func (in ProcessVideoInput) Validate() error {
+	if in.Callback == nil {
+		return nil
+	}
+	var problems []error
+	if u, err := url.Parse(in.Callback.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		problems = append(problems, errors.New("callback.url must be an http(s) url"))
+	}
+	if in.Callback.Secret == "" {
+		problems = append(problems, errors.New("callback.secret is required to sign the callback"))
+	}
+	return errors.Join(problems...)
 }
 
 // ProcessVideoOutput represents the output of video processing
@@ -22,4 +68,83 @@ type ProcessVideoOutput struct {
 	FrameCount int
 	Hash       string
 	Error      string
+	// ErrorCode, Stage and Retryable describe a failure: the domain error code, the stage that failed and whether a
+	// new run of the same job may succeed
+	ErrorCode string
+	Stage     string
+	Retryable bool
+	Retention *RetentionOutput
+	// SourceChanged is set when the original video was replaced while it was being processed
+	SourceChanged bool
+	// ReportKey is the key of the uploaded processing report; empty when it could not be uploaded
+	ReportKey string
+	Callback  *CallbackOutput
+}
+
+const (
+	// RetentionStatusScheduled is reported in the FINISHED event, before the retention policy runs
+	RetentionStatusScheduled = "scheduled"
+	RetentionStatusApplied   = "applied"
+	RetentionStatusFailed    = "failed"
+	// RetentionStatusSkipped means the original video changed during processing and was left untouched
+	RetentionStatusSkipped = "skipped"
+)
+
+// RetentionOutput represents the retention action applied to the original video and its outcome
+type RetentionOutput struct {
+	Action string
+	Status string
+	Detail string
+}
+
+const (
+	CallbackStatusDelivered = "delivered"
+	CallbackStatusFailed    = "failed"
+	// CallbackStatusRejected means the callback URL is invalid or its host is not allowed
+	CallbackStatusRejected = "rejected"
+)
+
+// CallbackOutput represents the delivery outcome of the result callback
+type CallbackOutput struct {
+	URL        string
+	Status     string
+	Attempts   int
+	StatusCode int
+	Error      string
+}
+
+// CallbackNotification represents the final result of a job sent to its callback endpoint
+type CallbackNotification struct {
+	Callback CallbackInput
+	VideoKey string
+	VideoId  string
+	UserId   string
+	Result   *ProcessVideoOutput
+}
+
+// VideoStatusUpdate represents a status change published for a video
+type VideoStatusUpdate struct {
+	// EventId identifies this update; retries and replays of it reuse the ID so consumers can de-duplicate them
+	EventId   string
+	VideoId   string
+	UserId    string
+	Hash      string
+	Status    string
+	Retention *RetentionOutput
+	// SourceChanged flags that the processed video is no longer the current object under its key
+	SourceChanged bool
+	// Failure describes why the job failed, in FAILED updates
+	Failure *FailureOutput
+	// ReportKey is the key of the uploaded processing report; empty when it could not be uploaded
+	ReportKey string
+	// AwaitDelivery publishes the update only once the broker acknowledged it, not once it is queued
+	AwaitDelivery bool
+}
+
+// FailureOutput represents the machine-readable description of a failed job
+type FailureOutput struct {
+	Code      string
+	Stage     string
+	Retryable bool
+	Message   string
 }
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+)
+
+type BatchUseCase interface {
+	ProcessBatch(ctx context.Context, input dto.ProcessBatchInput) (*dto.ProcessBatchOutput, error)
+}
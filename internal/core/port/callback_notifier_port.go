@@ -0,0 +1,13 @@
+package port
+
+import (
+	"context"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+)
+
+// CallbackNotifier defines the port for delivering a payload to a caller-provided HTTP endpoint.
+// A URL that may not be called is reported as a domain.InvalidInputError.
+type CallbackNotifier interface {
+	Notify(ctx context.Context, url, secret string, payload []byte) (entity.CallbackDelivery, error)
+}
@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+)
+
+type DiagnosticsController interface {
+	Probe(ctx context.Context, input dto.ProbeInput) ([]byte, error)
+	Extract(ctx context.Context, input dto.ExtractInput) ([]byte, error)
+	Verify(ctx context.Context, input dto.VerifyInput) ([]byte, error)
+	Version(ctx context.Context, input dto.VersionInput) ([]byte, error)
+}
@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+)
+
+// DiagnosticsUseCase inspects videos and frame archives to debug the processing outside of a job run
+type DiagnosticsUseCase interface {
+	Probe(ctx context.Context, input dto.ProbeInput) (*dto.ProbeOutput, error)
+	Extract(ctx context.Context, input dto.ExtractInput) (*dto.ExtractOutput, error)
+	Verify(ctx context.Context, input dto.VerifyInput) (*dto.VerifyOutput, error)
+	Version(ctx context.Context, input dto.VersionInput) (*dto.VersionOutput, error)
+}
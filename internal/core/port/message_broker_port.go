@@ -2,9 +2,11 @@ package port
 
 import (
 	"context"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
 )
 
 // MessageBroker defines the port for message broker operations, such as publishing messages.
 type MessageBroker interface {
-	PublishMessage(ctx context.Context, message []byte) error
+	PublishMessage(ctx context.Context, message entity.Message) error
 }
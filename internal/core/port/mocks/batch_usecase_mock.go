@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/port/batch_usecase_port.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/port/batch_usecase_port.go -destination=internal/core/port/mocks/batch_usecase_mock.go
+//
+
+// Package mock_port is a generated GoMock package.
+package mock_port
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockBatchUseCase is a mock of BatchUseCase interface.
+type MockBatchUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockBatchUseCaseMockRecorder
+	isgomock struct{}
+}
+
+// MockBatchUseCaseMockRecorder is the mock recorder for MockBatchUseCase.
+type MockBatchUseCaseMockRecorder struct {
+	mock *MockBatchUseCase
+}
+
+// NewMockBatchUseCase creates a new mock instance.
+func NewMockBatchUseCase(ctrl *gomock.Controller) *MockBatchUseCase {
+	mock := &MockBatchUseCase{ctrl: ctrl}
+	mock.recorder = &MockBatchUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBatchUseCase) EXPECT() *MockBatchUseCaseMockRecorder {
+	return m.recorder
+}
+
+// ProcessBatch mocks base method.
+func (m *MockBatchUseCase) ProcessBatch(ctx context.Context, input dto.ProcessBatchInput) (*dto.ProcessBatchOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessBatch", ctx, input)
+	ret0, _ := ret[0].(*dto.ProcessBatchOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessBatch indicates an expected call of ProcessBatch.
+func (mr *MockBatchUseCaseMockRecorder) ProcessBatch(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBatch", reflect.TypeOf((*MockBatchUseCase)(nil).ProcessBatch), ctx, input)
+}
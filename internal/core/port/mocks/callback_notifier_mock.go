@@ -0,0 +1,57 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/port/callback_notifier_port.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/port/callback_notifier_port.go -destination=internal/core/port/mocks/callback_notifier_mock.go
+//
+
+// Package mock_port is a generated GoMock package.
+package mock_port
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCallbackNotifier is a mock of CallbackNotifier interface.
+type MockCallbackNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockCallbackNotifierMockRecorder
+	isgomock struct{}
+}
+
+// MockCallbackNotifierMockRecorder is the mock recorder for MockCallbackNotifier.
+type MockCallbackNotifierMockRecorder struct {
+	mock *MockCallbackNotifier
+}
+
+// NewMockCallbackNotifier creates a new mock instance.
+func NewMockCallbackNotifier(ctrl *gomock.Controller) *MockCallbackNotifier {
+	mock := &MockCallbackNotifier{ctrl: ctrl}
+	mock.recorder = &MockCallbackNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCallbackNotifier) EXPECT() *MockCallbackNotifierMockRecorder {
+	return m.recorder
+}
+
+// Notify mocks base method.
+func (m *MockCallbackNotifier) Notify(ctx context.Context, url, secret string, payload []byte) (entity.CallbackDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notify", ctx, url, secret, payload)
+	ret0, _ := ret[0].(entity.CallbackDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Notify indicates an expected call of Notify.
+func (mr *MockCallbackNotifierMockRecorder) Notify(ctx, url, secret, payload any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notify", reflect.TypeOf((*MockCallbackNotifier)(nil).Notify), ctx, url, secret, payload)
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/port/diagnostics_controller_port.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/port/diagnostics_controller_port.go -destination=internal/core/port/mocks/diagnostics_controller_mock.go
+//
+
+// Package mock_port is a generated GoMock package.
+package mock_port
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDiagnosticsController is a mock of DiagnosticsController interface.
+type MockDiagnosticsController struct {
+	ctrl     *gomock.Controller
+	recorder *MockDiagnosticsControllerMockRecorder
+	isgomock struct{}
+}
+
+// MockDiagnosticsControllerMockRecorder is the mock recorder for MockDiagnosticsController.
+type MockDiagnosticsControllerMockRecorder struct {
+	mock *MockDiagnosticsController
+}
+
+// NewMockDiagnosticsController creates a new mock instance.
+func NewMockDiagnosticsController(ctrl *gomock.Controller) *MockDiagnosticsController {
+	mock := &MockDiagnosticsController{ctrl: ctrl}
+	mock.recorder = &MockDiagnosticsControllerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiagnosticsController) EXPECT() *MockDiagnosticsControllerMockRecorder {
+	return m.recorder
+}
+
+// Extract mocks base method.
+func (m *MockDiagnosticsController) Extract(ctx context.Context, input dto.ExtractInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Extract", ctx, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Extract indicates an expected call of Extract.
+func (mr *MockDiagnosticsControllerMockRecorder) Extract(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Extract", reflect.TypeOf((*MockDiagnosticsController)(nil).Extract), ctx, input)
+}
+
+// Probe mocks base method.
+func (m *MockDiagnosticsController) Probe(ctx context.Context, input dto.ProbeInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Probe", ctx, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Probe indicates an expected call of Probe.
+func (mr *MockDiagnosticsControllerMockRecorder) Probe(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Probe", reflect.TypeOf((*MockDiagnosticsController)(nil).Probe), ctx, input)
+}
+
+// Verify mocks base method.
+func (m *MockDiagnosticsController) Verify(ctx context.Context, input dto.VerifyInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockDiagnosticsControllerMockRecorder) Verify(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockDiagnosticsController)(nil).Verify), ctx, input)
+}
+
+// Version mocks base method.
+func (m *MockDiagnosticsController) Version(ctx context.Context, input dto.VersionInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version", ctx, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockDiagnosticsControllerMockRecorder) Version(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockDiagnosticsController)(nil).Version), ctx, input)
+}
@@ -0,0 +1,102 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/port/diagnostics_usecase_port.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/port/diagnostics_usecase_port.go -destination=internal/core/port/mocks/diagnostics_usecase_mock.go
+//
+
+// Package mock_port is a generated GoMock package.
+package mock_port
+
+import (
+	context "context"
+	reflect "reflect"
+
+	dto "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDiagnosticsUseCase is a mock of DiagnosticsUseCase interface.
+type MockDiagnosticsUseCase struct {
+	ctrl     *gomock.Controller
+	recorder *MockDiagnosticsUseCaseMockRecorder
+	isgomock struct{}
+}
+
+// MockDiagnosticsUseCaseMockRecorder is the mock recorder for MockDiagnosticsUseCase.
+type MockDiagnosticsUseCaseMockRecorder struct {
+	mock *MockDiagnosticsUseCase
+}
+
+// NewMockDiagnosticsUseCase creates a new mock instance.
+func NewMockDiagnosticsUseCase(ctrl *gomock.Controller) *MockDiagnosticsUseCase {
+	mock := &MockDiagnosticsUseCase{ctrl: ctrl}
+	mock.recorder = &MockDiagnosticsUseCaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiagnosticsUseCase) EXPECT() *MockDiagnosticsUseCaseMockRecorder {
+	return m.recorder
+}
+
+// Extract mocks base method.
+func (m *MockDiagnosticsUseCase) Extract(ctx context.Context, input dto.ExtractInput) (*dto.ExtractOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Extract", ctx, input)
+	ret0, _ := ret[0].(*dto.ExtractOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Extract indicates an expected call of Extract.
+func (mr *MockDiagnosticsUseCaseMockRecorder) Extract(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Extract", reflect.TypeOf((*MockDiagnosticsUseCase)(nil).Extract), ctx, input)
+}
+
+// Probe mocks base method.
+func (m *MockDiagnosticsUseCase) Probe(ctx context.Context, input dto.ProbeInput) (*dto.ProbeOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Probe", ctx, input)
+	ret0, _ := ret[0].(*dto.ProbeOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Probe indicates an expected call of Probe.
+func (mr *MockDiagnosticsUseCaseMockRecorder) Probe(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Probe", reflect.TypeOf((*MockDiagnosticsUseCase)(nil).Probe), ctx, input)
+}
+
+// Verify mocks base method.
+func (m *MockDiagnosticsUseCase) Verify(ctx context.Context, input dto.VerifyInput) (*dto.VerifyOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, input)
+	ret0, _ := ret[0].(*dto.VerifyOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockDiagnosticsUseCaseMockRecorder) Verify(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockDiagnosticsUseCase)(nil).Verify), ctx, input)
+}
+
+// Version mocks base method.
+func (m *MockDiagnosticsUseCase) Version(ctx context.Context, input dto.VersionInput) (*dto.VersionOutput, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Version", ctx, input)
+	ret0, _ := ret[0].(*dto.VersionOutput)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Version indicates an expected call of Version.
+func (mr *MockDiagnosticsUseCaseMockRecorder) Version(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockDiagnosticsUseCase)(nil).Version), ctx, input)
+}
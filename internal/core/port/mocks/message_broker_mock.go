@@ -13,6 +13,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	entity "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,7 +42,7 @@ func (m *MockMessageBroker) EXPECT() *MockMessageBrokerMockRecorder {
 }
 
 // PublishMessage mocks base method.
-func (m *MockMessageBroker) PublishMessage(ctx context.Context, message []byte) error {
+func (m *MockMessageBroker) PublishMessage(ctx context.Context, message entity.Message) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "PublishMessage", ctx, message)
 	ret0, _ := ret[0].(error)
@@ -55,6 +55,51 @@ func (mr *MockPresenterMockRecorder) PresentError(err any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresentError", reflect.TypeOf((*MockPresenter)(nil).PresentError), err)
 }
 
+// PresentExtractOutput mocks base method.
+func (m *MockPresenter) PresentExtractOutput(output *dto.ExtractOutput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresentExtractOutput", output)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresentExtractOutput indicates an expected call of PresentExtractOutput.
+func (mr *MockPresenterMockRecorder) PresentExtractOutput(output any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresentExtractOutput", reflect.TypeOf((*MockPresenter)(nil).PresentExtractOutput), output)
+}
+
+// PresentProbeOutput mocks base method.
+func (m *MockPresenter) PresentProbeOutput(output *dto.ProbeOutput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresentProbeOutput", output)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresentProbeOutput indicates an expected call of PresentProbeOutput.
+func (mr *MockPresenterMockRecorder) PresentProbeOutput(output any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresentProbeOutput", reflect.TypeOf((*MockPresenter)(nil).PresentProbeOutput), output)
+}
+
+// PresentProcessBatchOutput mocks base method.
+func (m *MockPresenter) PresentProcessBatchOutput(output *dto.ProcessBatchOutput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresentProcessBatchOutput", output)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresentProcessBatchOutput indicates an expected call of PresentProcessBatchOutput.
+func (mr *MockPresenterMockRecorder) PresentProcessBatchOutput(output any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresentProcessBatchOutput", reflect.TypeOf((*MockPresenter)(nil).PresentProcessBatchOutput), output)
+}
+
 // PresentProcessVideoOutput mocks base method.
 func (m *MockPresenter) PresentProcessVideoOutput(output *dto.ProcessVideoOutput) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -69,3 +114,33 @@ func (mr *MockPresenterMockRecorder) PresentProcessVideoOutput(output any) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresentProcessVideoOutput", reflect.TypeOf((*MockPresenter)(nil).PresentProcessVideoOutput), output)
 }
+
+// PresentVerifyOutput mocks base method.
+func (m *MockPresenter) PresentVerifyOutput(output *dto.VerifyOutput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresentVerifyOutput", output)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresentVerifyOutput indicates an expected call of PresentVerifyOutput.
+func (mr *MockPresenterMockRecorder) PresentVerifyOutput(output any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresentVerifyOutput", reflect.TypeOf((*MockPresenter)(nil).PresentVerifyOutput), output)
+}
+
+// PresentVersionOutput mocks base method.
+func (m *MockPresenter) PresentVersionOutput(output *dto.VersionOutput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PresentVersionOutput", output)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PresentVersionOutput indicates an expected call of PresentVersionOutput.
+func (mr *MockPresenterMockRecorder) PresentVersionOutput(output any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PresentVersionOutput", reflect.TypeOf((*MockPresenter)(nil).PresentVersionOutput), output)
+}
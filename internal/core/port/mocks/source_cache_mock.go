@@ -0,0 +1,72 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/core/port/source_cache_port.go
+//
+// Generated by this command:
+//
+//	mockgen -source=internal/core/port/source_cache_port.go -destination=internal/core/port/mocks/source_cache_mock.go
+//
+
+// Package mock_port is a generated GoMock package.
+package mock_port
+
+import (
+	context "context"
+	reflect "reflect"
+
+	entity "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockSourceCache is a mock of SourceCache interface.
+type MockSourceCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockSourceCacheMockRecorder
+	isgomock struct{}
+}
+
+// MockSourceCacheMockRecorder is the mock recorder for MockSourceCache.
+type MockSourceCacheMockRecorder struct {
+	mock *MockSourceCache
+}
+
+// NewMockSourceCache creates a new mock instance.
+func NewMockSourceCache(ctrl *gomock.Controller) *MockSourceCache {
+	mock := &MockSourceCache{ctrl: ctrl}
+	mock.recorder = &MockSourceCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSourceCache) EXPECT() *MockSourceCacheMockRecorder {
+	return m.recorder
+}
+
+// Fetch mocks base method.
+func (m *MockSourceCache) Fetch(ctx context.Context, key string, version entity.ObjectVersion, path string) (string, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fetch", ctx, key, version, path)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Fetch indicates an expected call of Fetch.
+func (mr *MockSourceCacheMockRecorder) Fetch(ctx, key, version, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fetch", reflect.TypeOf((*MockSourceCache)(nil).Fetch), ctx, key, version, path)
+}
+
+// Store mocks base method.
+func (m *MockSourceCache) Store(ctx context.Context, key string, version entity.ObjectVersion, path, hash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Store", ctx, key, version, path, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Store indicates an expected call of Store.
+func (mr *MockSourceCacheMockRecorder) Store(ctx, key, version, path, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Store", reflect.TypeOf((*MockSourceCache)(nil).Store), ctx, key, version, path, hash)
+}
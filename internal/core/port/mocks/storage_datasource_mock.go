@@ -14,6 +14,7 @@ import (
 	io "io"
 	reflect "reflect"
 
+	entity "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,33 +42,121 @@ func (m *MockStorageDataSource) EXPECT() *MockStorageDataSourceMockRecorder {
 	return m.recorder
 }
 
+// CopyVideo mocks base method.
+func (m *MockStorageDataSource) CopyVideo(ctx context.Context, key string, version entity.ObjectVersion, destinationBucket, destinationKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CopyVideo", ctx, key, version, destinationBucket, destinationKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CopyVideo indicates an expected call of CopyVideo.
+func (mr *MockStorageDataSourceMockRecorder) CopyVideo(ctx, key, version, destinationBucket, destinationKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CopyVideo", reflect.TypeOf((*MockStorageDataSource)(nil).CopyVideo), ctx, key, version, destinationBucket, destinationKey)
+}
+
+// DeleteProcessedFile mocks base method.
+func (m *MockStorageDataSource) DeleteProcessedFile(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteProcessedFile", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteProcessedFile indicates an expected call of DeleteProcessedFile.
+func (mr *MockStorageDataSourceMockRecorder) DeleteProcessedFile(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProcessedFile", reflect.TypeOf((*MockStorageDataSource)(nil).DeleteProcessedFile), ctx, key)
+}
+
 // DeleteVideo mocks base method.
-func (m *MockStorageDataSource) DeleteVideo(ctx context.Context, key string) error {
+func (m *MockStorageDataSource) DeleteVideo(ctx context.Context, key string, version entity.ObjectVersion) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteVideo", ctx, key)
+	ret := m.ctrl.Call(m, "DeleteVideo", ctx, key, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteVideo indicates an expected call of DeleteVideo.
-func (mr *MockStorageDataSourceMockRecorder) DeleteVideo(ctx, key any) *gomock.Call {
+func (mr *MockStorageDataSourceMockRecorder) DeleteVideo(ctx, key, version any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVideo", reflect.TypeOf((*MockStorageDataSource)(nil).DeleteVideo), ctx, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVideo", reflect.TypeOf((*MockStorageDataSource)(nil).DeleteVideo), ctx, key, version)
 }
 
 // DownloadVideo mocks base method.
-func (m *MockStorageDataSource) DownloadVideo(ctx context.Context, key string) (io.ReadCloser, error) {
+func (m *MockStorageDataSource) DownloadVideo(ctx context.Context, key string, version entity.ObjectVersion) (io.ReadCloser, entity.ObjectVersion, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DownloadVideo", ctx, key)
+	ret := m.ctrl.Call(m, "DownloadVideo", ctx, key, version)
 	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(entity.ObjectVersion)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// DownloadVideo indicates an expected call of DownloadVideo.
+func (mr *MockStorageDataSourceMockRecorder) DownloadVideo(ctx, key, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadVideo", reflect.TypeOf((*MockStorageDataSource)(nil).DownloadVideo), ctx, key, version)
+}
+
+// ListVideos mocks base method.
+func (m *MockStorageDataSource) ListVideos(ctx context.Context, prefix string) ([]entity.StorageObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVideos", ctx, prefix)
+	ret0, _ := ret[0].([]entity.StorageObject)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// DownloadVideo indicates an expected call of DownloadVideo.
-func (mr *MockStorageDataSourceMockRecorder) DownloadVideo(ctx, key any) *gomock.Call {
+// ListVideos indicates an expected call of ListVideos.
+func (mr *MockStorageDataSourceMockRecorder) ListVideos(ctx, prefix any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVideos", reflect.TypeOf((*MockStorageDataSource)(nil).ListVideos), ctx, prefix)
+}
+
+// StatProcessedFile mocks base method.
+func (m *MockStorageDataSource) StatProcessedFile(ctx context.Context, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatProcessedFile", ctx, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatProcessedFile indicates an expected call of StatProcessedFile.
+func (mr *MockStorageDataSourceMockRecorder) StatProcessedFile(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatProcessedFile", reflect.TypeOf((*MockStorageDataSource)(nil).StatProcessedFile), ctx, key)
+}
+
+// StatVideo mocks base method.
+func (m *MockStorageDataSource) StatVideo(ctx context.Context, key string) (*entity.StorageObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatVideo", ctx, key)
+	ret0, _ := ret[0].(*entity.StorageObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatVideo indicates an expected call of StatVideo.
+func (mr *MockStorageDataSourceMockRecorder) StatVideo(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatVideo", reflect.TypeOf((*MockStorageDataSource)(nil).StatVideo), ctx, key)
+}
+
+// TagVideo mocks base method.
+func (m *MockStorageDataSource) TagVideo(ctx context.Context, key string, version entity.ObjectVersion, tags map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TagVideo", ctx, key, version, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TagVideo indicates an expected call of TagVideo.
+func (mr *MockStorageDataSourceMockRecorder) TagVideo(ctx, key, version, tags any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadVideo", reflect.TypeOf((*MockStorageDataSource)(nil).DownloadVideo), ctx, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TagVideo", reflect.TypeOf((*MockStorageDataSource)(nil).TagVideo), ctx, key, version, tags)
 }
 
 // UploadProcessedFile mocks base method.
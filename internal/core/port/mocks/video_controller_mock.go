@@ -41,6 +41,21 @@ func (m *MockVideoController) EXPECT() *MockVideoControllerMockRecorder {
 	return m.recorder
 }
 
+// ProcessBatch mocks base method.
+func (m *MockVideoController) ProcessBatch(ctx context.Context, input dto.ProcessBatchInput) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProcessBatch", ctx, input)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProcessBatch indicates an expected call of ProcessBatch.
+func (mr *MockVideoControllerMockRecorder) ProcessBatch(ctx, input any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessBatch", reflect.TypeOf((*MockVideoController)(nil).ProcessBatch), ctx, input)
+}
+
 // ProcessVideo mocks base method.
 func (m *MockVideoController) ProcessVideo(ctx context.Context, input dto.ProcessVideoInput) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -14,6 +14,8 @@ import (
 	io "io"
 	reflect "reflect"
 
+	entity "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	dto "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -41,47 +43,150 @@ func (m *MockVideoGateway) EXPECT() *MockVideoGatewayMockRecorder {
 	return m.recorder
 }
 
+// Copy mocks base method.
+func (m *MockVideoGateway) Copy(ctx context.Context, key string, version entity.ObjectVersion, destinationBucket, destinationKey string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Copy", ctx, key, version, destinationBucket, destinationKey)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Copy indicates an expected call of Copy.
+func (mr *MockVideoGatewayMockRecorder) Copy(ctx, key, version, destinationBucket, destinationKey any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Copy", reflect.TypeOf((*MockVideoGateway)(nil).Copy), ctx, key, version, destinationBucket, destinationKey)
+}
+
 // Delete mocks base method.
-func (m *MockVideoGateway) Delete(ctx context.Context, key string) error {
+func (m *MockVideoGateway) Delete(ctx context.Context, key string, version entity.ObjectVersion) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret := m.ctrl.Call(m, "Delete", ctx, key, version)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockVideoGatewayMockRecorder) Delete(ctx, key any) *gomock.Call {
+func (mr *MockVideoGatewayMockRecorder) Delete(ctx, key, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockVideoGateway)(nil).Delete), ctx, key, version)
+}
+
+// DeleteOutput mocks base method.
+func (m *MockVideoGateway) DeleteOutput(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutput", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutput indicates an expected call of DeleteOutput.
+func (mr *MockVideoGatewayMockRecorder) DeleteOutput(ctx, key any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockVideoGateway)(nil).Delete), ctx, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutput", reflect.TypeOf((*MockVideoGateway)(nil).DeleteOutput), ctx, key)
 }
 
 // Download mocks base method.
-func (m *MockVideoGateway) Download(ctx context.Context, key string) (io.ReadCloser, error) {
+func (m *MockVideoGateway) Download(ctx context.Context, key string, version entity.ObjectVersion) (io.ReadCloser, entity.ObjectVersion, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Download", ctx, key)
+	ret := m.ctrl.Call(m, "Download", ctx, key, version)
 	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(entity.ObjectVersion)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Download indicates an expected call of Download.
+func (mr *MockVideoGatewayMockRecorder) Download(ctx, key, version any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Download", reflect.TypeOf((*MockVideoGateway)(nil).Download), ctx, key, version)
+}
+
+// List mocks base method.
+func (m *MockVideoGateway) List(ctx context.Context, prefix string) ([]entity.StorageObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, prefix)
+	ret0, _ := ret[0].([]entity.StorageObject)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Download indicates an expected call of Download.
-func (mr *MockVideoGatewayMockRecorder) Download(ctx, key any) *gomock.Call {
+// List indicates an expected call of List.
+func (mr *MockVideoGatewayMockRecorder) List(ctx, prefix any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockVideoGateway)(nil).List), ctx, prefix)
+}
+
+// NotifyCallback mocks base method.
+func (m *MockVideoGateway) NotifyCallback(ctx context.Context, notification dto.CallbackNotification) (entity.CallbackDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyCallback", ctx, notification)
+	ret0, _ := ret[0].(entity.CallbackDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NotifyCallback indicates an expected call of NotifyCallback.
+func (mr *MockVideoGatewayMockRecorder) NotifyCallback(ctx, notification any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyCallback", reflect.TypeOf((*MockVideoGateway)(nil).NotifyCallback), ctx, notification)
+}
+
+// Stat mocks base method.
+func (m *MockVideoGateway) Stat(ctx context.Context, key string) (*entity.StorageObject, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stat", ctx, key)
+	ret0, _ := ret[0].(*entity.StorageObject)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stat indicates an expected call of Stat.
+func (mr *MockVideoGatewayMockRecorder) Stat(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockVideoGateway)(nil).Stat), ctx, key)
+}
+
+// StatOutput mocks base method.
+func (m *MockVideoGateway) StatOutput(ctx context.Context, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatOutput", ctx, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatOutput indicates an expected call of StatOutput.
+func (mr *MockVideoGatewayMockRecorder) StatOutput(ctx, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatOutput", reflect.TypeOf((*MockVideoGateway)(nil).StatOutput), ctx, key)
+}
+
+// Tag mocks base method.
+func (m *MockVideoGateway) Tag(ctx context.Context, key string, version entity.ObjectVersion, tags map[string]string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Tag", ctx, key, version, tags)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Tag indicates an expected call of Tag.
+func (mr *MockVideoGatewayMockRecorder) Tag(ctx, key, version, tags any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Download", reflect.TypeOf((*MockVideoGateway)(nil).Download), ctx, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Tag", reflect.TypeOf((*MockVideoGateway)(nil).Tag), ctx, key, version, tags)
 }
 
 // UpdateStatus mocks base method.
-func (m *MockVideoGateway) UpdateStatus(ctx context.Context, videoId, userId, videoHash, status string) error {
+func (m *MockVideoGateway) UpdateStatus(ctx context.Context, update dto.VideoStatusUpdate) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateStatus", ctx, videoId, userId, videoHash, status)
+	ret := m.ctrl.Call(m, "UpdateStatus", ctx, update)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateStatus indicates an expected call of UpdateStatus.
-func (mr *MockVideoGatewayMockRecorder) UpdateStatus(ctx, videoId, userId, videoHash, status any) *gomock.Call {
+func (mr *MockVideoGatewayMockRecorder) UpdateStatus(ctx, update any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockVideoGateway)(nil).UpdateStatus), ctx, videoId, userId, videoHash, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatus", reflect.TypeOf((*MockVideoGateway)(nil).UpdateStatus), ctx, update)
 }
 
 // Upload mocks base method.
@@ -99,6 +204,34 @@ func (mr *MockVideoGatewayMockRecorder) Upload(ctx, key, data, contentType, size
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upload", reflect.TypeOf((*MockVideoGateway)(nil).Upload), ctx, key, data, contentType, size)
 }
 
+// UploadBatchReport mocks base method.
+func (m *MockVideoGateway) UploadBatchReport(ctx context.Context, key string, report *dto.ProcessBatchOutput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadBatchReport", ctx, key, report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadBatchReport indicates an expected call of UploadBatchReport.
+func (mr *MockVideoGatewayMockRecorder) UploadBatchReport(ctx, key, report any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadBatchReport", reflect.TypeOf((*MockVideoGateway)(nil).UploadBatchReport), ctx, key, report)
+}
+
+// UploadProcessingReport mocks base method.
+func (m *MockVideoGateway) UploadProcessingReport(ctx context.Context, key string, report *dto.ProcessingReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadProcessingReport", ctx, key, report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UploadProcessingReport indicates an expected call of UploadProcessingReport.
+func (mr *MockVideoGatewayMockRecorder) UploadProcessingReport(ctx, key, report any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadProcessingReport", reflect.TypeOf((*MockVideoGateway)(nil).UploadProcessingReport), ctx, key, report)
+}
+
 // MockVideoProcessor is a mock of VideoProcessor interface.
 type MockVideoProcessor struct {
 	ctrl     *gomock.Controller
@@ -123,20 +256,49 @@ func (m *MockVideoProcessor) EXPECT() *MockVideoProcessorMockRecorder {
 	return m.recorder
 }
 
+// Capabilities mocks base method.
+func (m *MockVideoProcessor) Capabilities(ctx context.Context) (*entity.ProcessorCapabilities, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Capabilities", ctx)
+	ret0, _ := ret[0].(*entity.ProcessorCapabilities)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Capabilities indicates an expected call of Capabilities.
+func (mr *MockVideoProcessorMockRecorder) Capabilities(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Capabilities", reflect.TypeOf((*MockVideoProcessor)(nil).Capabilities), ctx)
+}
+
+// ProbeVideo mocks base method.
+func (m *MockVideoProcessor) ProbeVideo(ctx context.Context, videoPath string) (*entity.VideoMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProbeVideo", ctx, videoPath)
+	ret0, _ := ret[0].(*entity.VideoMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProbeVideo indicates an expected call of ProbeVideo.
+func (mr *MockVideoProcessorMockRecorder) ProbeVideo(ctx, videoPath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProbeVideo", reflect.TypeOf((*MockVideoProcessor)(nil).ProbeVideo), ctx, videoPath)
+}
+
 // ProcessVideo mocks base method.
-func (m *MockVideoProcessor) ProcessVideo(ctx context.Context, videoPath string, frameRate float64, outputFormat string) (int, string, error) {
+func (m *MockVideoProcessor) ProcessVideo(ctx context.Context, videoPath string, config entity.ProcessingConfig) (*entity.FrameExtraction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ProcessVideo", ctx, videoPath, frameRate, outputFormat)
-	ret0, _ := ret[0].(int)
-	ret1, _ := ret[1].(string)
-	ret2, _ := ret[2].(error)
-	return ret0, ret1, ret2
+	ret := m.ctrl.Call(m, "ProcessVideo", ctx, videoPath, config)
+	ret0, _ := ret[0].(*entity.FrameExtraction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // ProcessVideo indicates an expected call of ProcessVideo.
-func (mr *MockVideoProcessorMockRecorder) ProcessVideo(ctx, videoPath, frameRate, outputFormat any) *gomock.Call {
+func (mr *MockVideoProcessorMockRecorder) ProcessVideo(ctx, videoPath, config any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessVideo", reflect.TypeOf((*MockVideoProcessor)(nil).ProcessVideo), ctx, videoPath, frameRate, outputFormat)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessVideo", reflect.TypeOf((*MockVideoProcessor)(nil).ProcessVideo), ctx, videoPath, config)
 }
 
 // ValidateVideo mocks base method.
@@ -153,6 +315,21 @@ func (mr *MockVideoProcessorMockRecorder) ValidateVideo(ctx, videoPath any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateVideo", reflect.TypeOf((*MockVideoProcessor)(nil).ValidateVideo), ctx, videoPath)
 }
 
+// VerifyArchive mocks base method.
+func (m *MockVideoProcessor) VerifyArchive(ctx context.Context, archivePath string) (*entity.FrameArchive, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyArchive", ctx, archivePath)
+	ret0, _ := ret[0].(*entity.FrameArchive)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyArchive indicates an expected call of VerifyArchive.
+func (mr *MockVideoProcessorMockRecorder) VerifyArchive(ctx, archivePath any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyArchive", reflect.TypeOf((*MockVideoProcessor)(nil).VerifyArchive), ctx, archivePath)
+}
+
 // MockFileManager is a mock of FileManager interface.
 type MockFileManager struct {
 	ctrl     *gomock.Controller
@@ -177,6 +354,21 @@ func (m *MockFileManager) EXPECT() *MockFileManagerMockRecorder {
 	return m.recorder
 }
 
+// CloseWorkspace mocks base method.
+func (m *MockFileManager) CloseWorkspace(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CloseWorkspace", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CloseWorkspace indicates an expected call of CloseWorkspace.
+func (mr *MockFileManagerMockRecorder) CloseWorkspace(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CloseWorkspace", reflect.TypeOf((*MockFileManager)(nil).CloseWorkspace), ctx)
+}
+
 // CreateTempDir mocks base method.
 func (m *MockFileManager) CreateTempDir(ctx context.Context, prefix string) (string, error) {
 	m.ctrl.T.Helper()
@@ -235,6 +427,20 @@ func (mr *MockFileManagerMockRecorder) DeleteFile(ctx, filePath any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFile", reflect.TypeOf((*MockFileManager)(nil).DeleteFile), ctx, filePath)
 }
 
+// EnsureSpace mocks base method.
+func (m *MockFileManager) EnsureSpace(ctx context.Context, required int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureSpace", ctx, required)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnsureSpace indicates an expected call of EnsureSpace.
+func (mr *MockFileManagerMockRecorder) EnsureSpace(ctx, required any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureSpace", reflect.TypeOf((*MockFileManager)(nil).EnsureSpace), ctx, required)
+}
+
 // GetFileSize mocks base method.
 func (m *MockFileManager) GetFileSize(ctx context.Context, filePath string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -265,6 +471,21 @@ func (mr *MockFileManagerMockRecorder) ListFiles(ctx, dirPath, pattern any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFiles", reflect.TypeOf((*MockFileManager)(nil).ListFiles), ctx, dirPath, pattern)
 }
 
+// OpenWorkspace mocks base method.
+func (m *MockFileManager) OpenWorkspace(ctx context.Context) (context.Context, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenWorkspace", ctx)
+	ret0, _ := ret[0].(context.Context)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenWorkspace indicates an expected call of OpenWorkspace.
+func (mr *MockFileManagerMockRecorder) OpenWorkspace(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenWorkspace", reflect.TypeOf((*MockFileManager)(nil).OpenWorkspace), ctx)
+}
+
 // ReadFile mocks base method.
 func (m *MockFileManager) ReadFile(ctx context.Context, filePath string) (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -4,5 +4,10 @@ import "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto
 
 type Presenter interface {
 	PresentProcessVideoOutput(output *dto.ProcessVideoOutput) ([]byte, error)
+	PresentProcessBatchOutput(output *dto.ProcessBatchOutput) ([]byte, error)
+	PresentProbeOutput(output *dto.ProbeOutput) ([]byte, error)
+	PresentExtractOutput(output *dto.ExtractOutput) ([]byte, error)
+	PresentVerifyOutput(output *dto.VerifyOutput) ([]byte, error)
+	PresentVersionOutput(output *dto.VersionOutput) ([]byte, error)
 	PresentError(err error) ([]byte, error)
 }
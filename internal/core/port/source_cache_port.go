@@ -0,0 +1,16 @@
+package port
+
+import (
+	"context"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+)
+
+// SourceCache defines the port for keeping local copies of original videos by storage revision, so a re-run of the
+// same revision skips the download and the hashing.
+type SourceCache interface {
+	// Fetch copies the cached revision of key to path, returning its SHA-256; found is false when it is not cached
+	Fetch(ctx context.Context, key string, version entity.ObjectVersion, path string) (hash string, found bool, err error)
+	// Store adds the video at path, whose SHA-256 is hash, as the given revision of key
+	Store(ctx context.Context, key string, version entity.ObjectVersion, path, hash string) error
+}
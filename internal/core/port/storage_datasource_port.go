@@ -3,11 +3,22 @@ package port
 import (
 	"context"
 	"io"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
 )
 
 // StorageDataSource defines the port for storage operations.
 type StorageDataSource interface {
-	DownloadVideo(ctx context.Context, key string) (io.ReadCloser, error)
+	// DownloadVideo returns the object body and the revision that was read. A non-zero version makes the read
+	// conditional, failing with a domain.ConflictError when the object changed.
+	DownloadVideo(ctx context.Context, key string, version entity.ObjectVersion) (io.ReadCloser, entity.ObjectVersion, error)
 	UploadProcessedFile(ctx context.Context, key string, data io.Reader, contentType string, size int64) (string, error)
-	DeleteVideo(ctx context.Context, key string) error
+	StatProcessedFile(ctx context.Context, key string) (int64, error)
+	DeleteProcessedFile(ctx context.Context, key string) error
+	// DeleteVideo, CopyVideo and TagVideo only act on the given revision and fail with a domain.ConflictError otherwise
+	DeleteVideo(ctx context.Context, key string, version entity.ObjectVersion) error
+	CopyVideo(ctx context.Context, key string, version entity.ObjectVersion, destinationBucket, destinationKey string) error
+	TagVideo(ctx context.Context, key string, version entity.ObjectVersion, tags map[string]string) error
+	StatVideo(ctx context.Context, key string) (*entity.StorageObject, error)
+	ListVideos(ctx context.Context, prefix string) ([]entity.StorageObject, error)
 }
@@ -8,4 +8,5 @@ import (
 
 type VideoController interface {
 	ProcessVideo(ctx context.Context, input dto.ProcessVideoInput) ([]byte, error)
+	ProcessBatch(ctx context.Context, input dto.ProcessBatchInput) ([]byte, error)
 }
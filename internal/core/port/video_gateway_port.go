@@ -3,18 +3,35 @@ package port
 import (
 	"context"
 	"io"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
 )
 
 type VideoGateway interface {
-	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	Download(ctx context.Context, key string, version entity.ObjectVersion) (io.ReadCloser, entity.ObjectVersion, error)
 	Upload(ctx context.Context, key string, data io.Reader, contentType string, size int64) (string, error)
-	Delete(ctx context.Context, key string) error
-	UpdateStatus(ctx context.Context, videoId string, userId string, videoHash string, status string) error
+	StatOutput(ctx context.Context, key string) (int64, error)
+	DeleteOutput(ctx context.Context, key string) error
+	Delete(ctx context.Context, key string, version entity.ObjectVersion) error
+	Copy(ctx context.Context, key string, version entity.ObjectVersion, destinationBucket, destinationKey string) error
+	Tag(ctx context.Context, key string, version entity.ObjectVersion, tags map[string]string) error
+	Stat(ctx context.Context, key string) (*entity.StorageObject, error)
+	List(ctx context.Context, prefix string) ([]entity.StorageObject, error)
+	UpdateStatus(ctx context.Context, update dto.VideoStatusUpdate) error
+	UploadBatchReport(ctx context.Context, key string, report *dto.ProcessBatchOutput) error
+	// UploadProcessingReport stores the report.json describing how a single job ran
+	UploadProcessingReport(ctx context.Context, key string, report *dto.ProcessingReport) error
+	NotifyCallback(ctx context.Context, notification dto.CallbackNotification) (entity.CallbackDelivery, error)
 }
 
 type VideoProcessor interface {
-	ProcessVideo(ctx context.Context, videoPath string, frameRate float64, outputFormat string) (int, string, error)
+	ProcessVideo(ctx context.Context, videoPath string, config entity.ProcessingConfig) (*entity.FrameExtraction, error)
 	ValidateVideo(ctx context.Context, videoPath string) error
+	ProbeVideo(ctx context.Context, videoPath string) (*entity.VideoMetadata, error)
+	// VerifyArchive checks that a frame archive produced by ProcessVideo is readable and complete
+	VerifyArchive(ctx context.Context, archivePath string) (*entity.FrameArchive, error)
+	Capabilities(ctx context.Context) (*entity.ProcessorCapabilities, error)
 }
 
 type FileManager interface {
@@ -26,4 +43,11 @@ type FileManager interface {
 	DeleteDir(ctx context.Context, dirPath string) error
 	ListFiles(ctx context.Context, dirPath, pattern string) ([]string, error)
 	GetFileSize(ctx context.Context, filePath string) (int64, error)
+	// OpenWorkspace creates the workspace of a job: the temp files and directories created with the returned context
+	// live in it
+	OpenWorkspace(ctx context.Context) (context.Context, error)
+	// EnsureSpace fails when the volume of the workspace of ctx has fewer than required bytes available
+	EnsureSpace(ctx context.Context, required int64) error
+	// CloseWorkspace removes the workspace of ctx with everything left in it, returning the most bytes it held
+	CloseWorkspace(ctx context.Context) (int64, error)
 }
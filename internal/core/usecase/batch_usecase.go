@@ -0,0 +1,301 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+const (
+	// DefaultBatchConcurrency is the number of videos processed at the same time when not configured
+	DefaultBatchConcurrency = 4
+
+	// tempSpaceFactor estimates the temp space used per video: the source file, its frames and the zip archive
+	tempSpaceFactor = 3
+)
+
+type batchUseCase struct {
+	videoGateway port.VideoGateway
+	videoUseCase port.VideoUseCase
+	logger       logger.Logger
+}
+
+func NewBatchUseCase(
+	videoGateway port.VideoGateway,
+	videoUseCase port.VideoUseCase,
+	logger logger.Logger,
+) port.BatchUseCase {
+	return &batchUseCase{
+		videoGateway: videoGateway,
+		videoUseCase: videoUseCase,
+		logger:       logger,
+	}
+}
+
+func (uc *batchUseCase) ProcessBatch(ctx context.Context, input dto.ProcessBatchInput) (*dto.ProcessBatchOutput, error) {
+	log := uc.logger.WithContext(ctx).With("prefix", input.Prefix)
+	log.Info("Starting batch processing")
+
+	objects, err := uc.resolveObjects(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) == 0 {
+		return nil, domain.NewInvalidInputError("no videos to process: provide video keys or a prefix with objects")
+	}
+
+	concurrency := input.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+	if concurrency > len(objects) {
+		concurrency = len(objects)
+	}
+	log.Info("Batch resolved", "videos", len(objects), "concurrency", concurrency, "max_temp_bytes", input.MaxTempBytes)
+
+	budget := newTempSpaceBudget(input.MaxTempBytes)
+	results := make([]dto.BatchItemResult, len(objects))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = uc.processOne(ctx, objects[i], input, budget)
+			}
+		}()
+	}
+	for i := range objects {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	output := &dto.ProcessBatchOutput{Total: len(results), Results: results}
+	for _, r := range results {
+		if r.Success {
+			output.Succeeded++
+		} else {
+			output.Failed++
+		}
+		log.Info("Batch item finished", "video_key", r.VideoKey, "success", r.Success, "output_key", r.OutputKey, "error", r.Error)
+	}
+
+	reportKey := generateBatchReportKey(time.Now(), uuid.NewString())
+	if err := uc.videoGateway.UploadBatchReport(ctx, reportKey, output); err != nil {
+		log.Warn("Failed to upload batch report", "error", err, "report_key", reportKey)
+	} else {
+		output.ReportKey = reportKey
+	}
+
+	log.Info("Batch processing completed", "total", output.Total, "succeeded", output.Succeeded, "failed", output.Failed, "report_key", output.ReportKey)
+	return output, nil
+}
+
+// resolveObjects merges explicit keys and prefix listing into a de-duplicated list of objects
+func (uc *batchUseCase) resolveObjects(ctx context.Context, input dto.ProcessBatchInput) ([]entity.StorageObject, error) {
+	log := uc.logger.WithContext(ctx)
+	seen := make(map[string]struct{})
+	var objects []entity.StorageObject
+
+	for _, key := range input.VideoKeys {
+		if key == "" {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		obj, err := uc.videoGateway.Stat(ctx, key)
+		if err != nil {
+			// The per-video run reports the real failure; without a size it is not counted in the temp budget
+			log.Warn("Failed to stat video, temp space will not be reserved", "video_key", key, "error", err)
+			obj = &entity.StorageObject{Key: key}
+		}
+		objects = append(objects, *obj)
+	}
+
+	if input.Prefix != "" {
+		listed, err := uc.videoGateway.List(ctx, input.Prefix)
+		if err != nil {
+			log.Error("Failed to list videos", "prefix", input.Prefix, "error", err)
+			return nil, domain.NewRetryableError(fmt.Errorf("failed to list videos: %w", err))
+		}
+		for _, obj := range listed {
+			if _, ok := seen[obj.Key]; ok {
+				continue
+			}
+			seen[obj.Key] = struct{}{}
+			objects = append(objects, obj)
+		}
+	}
+
+	return objects, nil
+}
+
+// processOne runs a single video through the video use case, isolating its failure from the rest of the batch
+func (uc *batchUseCase) processOne(ctx context.Context, obj entity.StorageObject, input dto.ProcessBatchInput, budget *tempSpaceBudget) (result dto.BatchItemResult) {
+	log := uc.logger.WithContext(ctx).With("video_key", obj.Key)
+	start := time.Now()
+	result.VideoKey = obj.Key
+	ctx, stage := withStageTracker(ctx, domain.StageInput)
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("Batch item panicked", "panic", r, "stage", stage.current())
+			result.Success = false
+			result.Error = fmt.Sprintf("panic: %v", r)
+			result.ErrorCode = string(domain.ErrorCodeInternal)
+			result.Stage = string(stage.current())
+			result.Retryable = false
+		}
+		result.Duration = time.Since(start)
+	}()
+
+	// Listed objects carry no metadata; the stat read for their IDs is reused by the video run
+	var statErr error
+	if obj.Metadata == nil {
+		stat, err := uc.videoGateway.Stat(ctx, obj.Key)
+		if err == nil {
+			obj = *stat
+		}
+		statErr = err
+	}
+	videoId, userId, err := resolveIDs(obj, input.KeyPattern, statErr)
+	if err != nil {
+		log.Warn("Failed to resolve video and user IDs", "error", err)
+		result.Error = err.Error()
+		result.ErrorCode = string(domain.CodeOf(err))
+		result.Stage = string(domain.StageInput)
+		result.Retryable = domain.IsRetryable(err)
+		return result
+	}
+	ctx = withSourceObject(ctx, obj)
+
+	reserved, err := budget.acquire(ctx, obj.Size*tempSpaceFactor)
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to reserve temp space: %v", err)
+		result.ErrorCode = string(domain.CodeOf(err))
+		result.Stage = string(domain.StageInput)
+		return result
+	}
+	defer budget.release(reserved)
+
+	out, err := uc.videoUseCase.ProcessVideo(ctx, dto.ProcessVideoInput{
+		VideoKey:        obj.Key,
+		VideoId:         videoId,
+		UserId:          userId,
+		IdFormat:        input.IdFormat,
+		Configuration:   input.Configuration,
+		RetentionPolicy: input.RetentionPolicy,
+	})
+	if out != nil {
+		result.Success = out.Success
+		result.OutputKey = out.OutputKey
+		result.FrameCount = out.FrameCount
+		result.Hash = out.Hash
+		result.Error = out.Error
+		result.ErrorCode = out.ErrorCode
+		result.Stage = out.Stage
+		result.Retryable = out.Retryable
+	}
+	if err != nil {
+		result.Success = false
+		if result.Error == "" {
+			result.Error = err.Error()
+		}
+		if result.ErrorCode == "" {
+			result.ErrorCode = string(domain.CodeOf(err))
+			result.Retryable = domain.IsRetryable(err)
+		}
+		if result.Stage == "" {
+			result.Stage = string(domain.StageOf(err))
+		}
+	}
+	return result
+}
+
+// resolveIDs reads the IDs of obj from its metadata, falling back to keyPattern; statErr is why the metadata of obj
+// could not be read, if so
+func resolveIDs(obj entity.StorageObject, keyPattern *regexp.Regexp, statErr error) (string, string, error) {
+	videoId, userId := obj.IDs(keyPattern)
+	if videoId != "" && userId != "" {
+		return videoId, userId, nil
+	}
+	if statErr != nil {
+		return "", "", fmt.Errorf("failed to read object metadata: %w", statErr)
+	}
+	return "", "", domain.NewInvalidInputError(fmt.Sprintf(
+		"video and user IDs not found in the %s/%s metadata nor in the key", entity.VideoIdMetadata, entity.UserIdMetadata))
+}
+
+// generateBatchReportKey creates the processed bucket key of the batch summary report; the run ID keeps batches
+// started in the same second apart
+func generateBatchReportKey(now time.Time, runId string) string {
+	return fmt.Sprintf("reports/batch-%s-%s.json", now.UTC().Format("20060102T150405Z"), runId)
+}
+
+// tempSpaceBudget bounds the temp space reserved by concurrent batch items. A zero limit disables accounting.
+type tempSpaceBudget struct {
+	mu      sync.Mutex
+	limit   int64
+	used    int64
+	changed chan struct{}
+}
+
+func newTempSpaceBudget(limit int64) *tempSpaceBudget {
+	return &tempSpaceBudget{limit: limit, changed: make(chan struct{})}
+}
+
+// acquire blocks until n bytes fit in the budget and returns the amount actually reserved.
+// Items larger than the whole budget are admitted alone so they can still run.
+func (b *tempSpaceBudget) acquire(ctx context.Context, n int64) (int64, error) {
+	if b.limit <= 0 || n <= 0 {
+		return 0, nil
+	}
+	if n > b.limit {
+		n = b.limit
+	}
+
+	for {
+		b.mu.Lock()
+		if b.used == 0 || b.used+n <= b.limit {
+			b.used += n
+			b.mu.Unlock()
+			return n, nil
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// release returns previously reserved bytes to the budget and wakes up waiting items
+func (b *tempSpaceBudget) release(n int64) {
+	if n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	b.used -= n
+	close(b.changed)
+	b.changed = make(chan struct{})
+	b.mu.Unlock()
+}
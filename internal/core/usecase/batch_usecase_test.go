@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+func TestBatchUseCase(t *testing.T) {
+	t.Run("ProcessBatch/keys_and_prefix_isolate_failures", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vu := pmocks.NewMockVideoUseCase(ctrl)
+		uc := NewBatchUseCase(vg, vu, logger.NewSlogLogger())
+
+		vg.EXPECT().Stat(gomock.Any(), "a.mp4").Return(&entity.StorageObject{Key: "a.mp4", Size: 10,
+			Metadata: map[string]string{entity.VideoIdMetadata: "v-a", entity.UserIdMetadata: "u-a"}}, nil)
+		vg.EXPECT().List(gomock.Any(), "backfill/").Return([]entity.StorageObject{
+			{Key: "a.mp4", Size: 10}, // duplicate of an explicit key
+			{Key: "backfill/b.mp4", Size: 20},
+		}, nil)
+		// Listed objects carry no metadata; b has none, so its IDs come from the key
+		vg.EXPECT().Stat(gomock.Any(), "backfill/b.mp4").Return(&entity.StorageObject{Key: "backfill/b.mp4", Size: 20,
+			Metadata: map[string]string{}}, nil)
+
+		vu.EXPECT().ProcessVideo(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, in dto.ProcessVideoInput) (*dto.ProcessVideoOutput, error) {
+				r.Equal("numeric", in.IdFormat)
+				// the stat read by the batch is handed to the run instead of being read again
+				r.NotNil(sourceObjectFromContext(ctx, in.VideoKey))
+				if in.VideoKey == "a.mp4" {
+					r.Equal("v-a", in.VideoId)
+					r.Equal("u-a", in.UserId)
+					return &dto.ProcessVideoOutput{Success: true, OutputKey: "processed/a.zip", FrameCount: 3, Hash: "a"}, nil
+				}
+				r.Equal("b", in.VideoId)
+				r.Equal("backfill", in.UserId)
+				return &dto.ProcessVideoOutput{Success: false, Error: "boom"}, domain.NewInternalError(errors.New("boom"))
+			}).Times(2)
+
+		var report *dto.ProcessBatchOutput
+		vg.EXPECT().UploadBatchReport(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, key string, out *dto.ProcessBatchOutput) error {
+				r.Regexp(`^reports/batch-\d{8}T\d{6}Z-[0-9a-f-]{36}\.json$`, key)
+				report = out
+				return nil
+			})
+
+		out, err := uc.ProcessBatch(context.Background(), dto.ProcessBatchInput{
+			VideoKeys:   []string{"a.mp4", "a.mp4"},
+			Prefix:      "backfill/",
+			Concurrency: 2,
+			IdFormat:    "numeric",
+			KeyPattern:  regexp.MustCompile(`^(?P<user_id>[^/]+)/(?P<video_id>[^/.]+)`),
+		})
+		r.NoError(err)
+		r.Equal(2, out.Total)
+		r.Equal(1, out.Succeeded)
+		r.Equal(1, out.Failed)
+		r.NotEmpty(out.ReportKey)
+		r.Same(out, report)
+
+		r.Equal("a.mp4", out.Results[0].VideoKey)
+		r.True(out.Results[0].Success)
+		r.Equal("processed/a.zip", out.Results[0].OutputKey)
+		r.Equal("backfill/b.mp4", out.Results[1].VideoKey)
+		r.False(out.Results[1].Success)
+		r.Equal("boom", out.Results[1].Error)
+		r.Equal(string(domain.ErrorCodeInternal), out.Results[1].ErrorCode)
+	})
+
+	t.Run("ProcessBatch/panic_is_recorded_as_failure", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vu := pmocks.NewMockVideoUseCase(ctrl)
+		uc := NewBatchUseCase(vg, vu, logger.NewSlogLogger())
+
+		vg.EXPECT().Stat(gomock.Any(), "p.mp4").Return(&entity.StorageObject{Key: "p.mp4",
+			Metadata: map[string]string{entity.VideoIdMetadata: "v", entity.UserIdMetadata: "u"}}, nil)
+		vu.EXPECT().ProcessVideo(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, in dto.ProcessVideoInput) (*dto.ProcessVideoOutput, error) {
+				_, stage := startStage(ctx, string(domain.StageExtract))
+				stage.finish(nil)
+				panic("unexpected")
+			})
+		vg.EXPECT().UploadBatchReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("s3 down"))
+
+		out, err := uc.ProcessBatch(context.Background(), dto.ProcessBatchInput{VideoKeys: []string{"p.mp4"}})
+		r.NoError(err)
+		r.Equal(1, out.Failed)
+		r.Contains(out.Results[0].Error, "panic")
+		r.Equal(string(domain.ErrorCodeInternal), out.Results[0].ErrorCode)
+		r.Equal(string(domain.StageExtract), out.Results[0].Stage)
+		r.False(out.Results[0].Retryable)
+		r.Empty(out.ReportKey)
+	})
+
+	t.Run("ProcessBatch/videos_without_ids_fail", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vu := pmocks.NewMockVideoUseCase(ctrl)
+		uc := NewBatchUseCase(vg, vu, logger.NewSlogLogger())
+
+		vg.EXPECT().Stat(gomock.Any(), "no-ids.mp4").Return(&entity.StorageObject{Key: "no-ids.mp4", Metadata: map[string]string{}}, nil)
+		// The object could not be read: the item reports why instead of missing IDs
+		vg.EXPECT().Stat(gomock.Any(), "gone.mp4").Return(nil, domain.NewNotFoundError(domain.ErrNotFound)).Times(2)
+		vg.EXPECT().UploadBatchReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		out, err := uc.ProcessBatch(context.Background(), dto.ProcessBatchInput{VideoKeys: []string{"no-ids.mp4", "gone.mp4"}})
+		r.NoError(err)
+		r.Equal(2, out.Failed)
+		r.Equal(string(domain.ErrorCodeInvalidInput), out.Results[0].ErrorCode)
+		r.Contains(out.Results[0].Error, "IDs not found")
+		r.Equal(string(domain.StageInput), out.Results[0].Stage)
+		r.Equal(string(domain.ErrorCodeNotFound), out.Results[1].ErrorCode)
+	})
+
+	t.Run("ProcessBatch/list_error", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vu := pmocks.NewMockVideoUseCase(ctrl)
+		uc := NewBatchUseCase(vg, vu, logger.NewSlogLogger())
+
+		vg.EXPECT().List(gomock.Any(), "x/").Return(nil, errors.New("denied"))
+
+		_, err := uc.ProcessBatch(context.Background(), dto.ProcessBatchInput{Prefix: "x/"})
+		var iErr *domain.InternalError
+		r.ErrorAs(err, &iErr)
+	})
+
+	t.Run("ProcessBatch/empty_input", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vu := pmocks.NewMockVideoUseCase(ctrl)
+		uc := NewBatchUseCase(vg, vu, logger.NewSlogLogger())
+
+		vg.EXPECT().List(gomock.Any(), "empty/").Return(nil, nil)
+
+		_, err := uc.ProcessBatch(context.Background(), dto.ProcessBatchInput{Prefix: "empty/"})
+		var inv *domain.InvalidInputError
+		r.ErrorAs(err, &inv)
+	})
+}
+
+func TestTempSpaceBudget(t *testing.T) {
+	t.Run("unlimited", func(t *testing.T) {
+		r := require.New(t)
+		b := newTempSpaceBudget(0)
+		n, err := b.acquire(context.Background(), 1<<40)
+		r.NoError(err)
+		r.Zero(n)
+	})
+
+	t.Run("blocks_until_released", func(t *testing.T) {
+		r := require.New(t)
+		b := newTempSpaceBudget(100)
+
+		first, err := b.acquire(context.Background(), 80)
+		r.NoError(err)
+
+		var wg sync.WaitGroup
+		acquired := make(chan int64, 1)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, _ := b.acquire(context.Background(), 50)
+			acquired <- n
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("acquire should block while the budget is exhausted")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		b.release(first)
+		wg.Wait()
+		r.Equal(int64(50), <-acquired)
+	})
+
+	t.Run("oversized_item_is_capped", func(t *testing.T) {
+		r := require.New(t)
+		b := newTempSpaceBudget(100)
+		n, err := b.acquire(context.Background(), 500)
+		r.NoError(err)
+		r.Equal(int64(100), n)
+	})
+
+	t.Run("context_cancelled", func(t *testing.T) {
+		r := require.New(t)
+		b := newTempSpaceBudget(100)
+		_, err := b.acquire(context.Background(), 100)
+		r.NoError(err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = b.acquire(ctx, 10)
+		r.ErrorIs(err, context.Canceled)
+	})
+}
@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+type diagnosticsUseCase struct {
+	// videoGateway is nil when the commands run without cloud access; only probing a key needs it
+	videoGateway   port.VideoGateway
+	videoProcessor port.VideoProcessor
+	fileManager    port.FileManager
+	logger         logger.Logger
+}
+
+func NewDiagnosticsUseCase(
+	videoGateway port.VideoGateway,
+	videoProcessor port.VideoProcessor,
+	fileManager port.FileManager,
+	logger logger.Logger,
+) port.DiagnosticsUseCase {
+	return &diagnosticsUseCase{
+		videoGateway:   videoGateway,
+		videoProcessor: videoProcessor,
+		fileManager:    fileManager,
+		logger:         logger,
+	}
+}
+
+// Probe reports the metadata of a local file, or of an object of the video bucket when no such file exists
+func (uc *diagnosticsUseCase) Probe(ctx context.Context, input dto.ProbeInput) (*dto.ProbeOutput, error) {
+	log := uc.logger.WithContext(ctx).With("source", input.Source)
+	if strings.TrimSpace(input.Source) == "" {
+		return nil, domain.NewInvalidInputError("a file or key to probe is required")
+	}
+
+	path := input.Source
+	fromStorage := false
+	if _, err := uc.fileManager.GetFileSize(ctx, input.Source); err != nil {
+		if uc.videoGateway == nil {
+			return nil, domain.NewInvalidInputError(fmt.Sprintf("%s is not a readable local file and no storage is configured", input.Source))
+		}
+		log.Info("Source is not a local file, downloading it from storage")
+		path, err = uc.download(ctx, input.Source)
+		if err != nil {
+			return nil, err
+		}
+		defer uc.cleanupFile(ctx, path, "temp video file")
+		fromStorage = true
+	}
+
+	metadata, err := uc.videoProcessor.ProbeVideo(ctx, path)
+	if err != nil {
+		log.Error("Failed to probe video", "error", err)
+		return nil, domain.NewValidationError(err)
+	}
+
+	output := newProbeOutput(metadata)
+	output.Source = input.Source
+	output.FromStorage = fromStorage
+	return output, nil
+}
+
+// newProbeOutput converts probed metadata; it is shared with the job report
+func newProbeOutput(metadata *entity.VideoMetadata) *dto.ProbeOutput {
+	output := &dto.ProbeOutput{
+		FormatName: metadata.FormatName,
+		Duration:   metadata.Duration,
+		Size:       metadata.Size,
+		BitRate:    metadata.BitRate,
+		Streams:    make([]dto.StreamOutput, 0, len(metadata.Streams)),
+	}
+	for _, stream := range metadata.Streams {
+		output.Streams = append(output.Streams, dto.StreamOutput{
+			Index:     stream.Index,
+			CodecType: stream.CodecType,
+			CodecName: stream.CodecName,
+			Width:     stream.Width,
+			Height:    stream.Height,
+			FrameRate: stream.FrameRate,
+			Duration:  stream.Duration,
+		})
+	}
+	return output
+}
+
+// download copies an object of the video bucket to a temp file, keeping its extension so ffprobe can use it
+func (uc *diagnosticsUseCase) download(ctx context.Context, key string) (string, error) {
+	tempFile, err := uc.fileManager.CreateTempFile(ctx, "probe_", filepath.Ext(key))
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	reader, _, err := uc.videoGateway.Download(ctx, key, entity.ObjectVersion{})
+	if err != nil {
+		uc.cleanupFile(ctx, tempFile, "temp video file")
+		var nErr *domain.NotFoundError
+		if errors.As(err, &nErr) {
+			return "", domain.NewNotFoundError(fmt.Sprintf("%s was found neither locally nor in storage", key))
+		}
+		return "", fmt.Errorf("failed to download from storage: %w", err)
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	if err := uc.fileManager.WriteToFile(ctx, tempFile, reader); err != nil {
+		uc.cleanupFile(ctx, tempFile, "temp video file")
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return tempFile, nil
+}
+
+// Extract runs the frame extraction of a job on a local video and writes the archive to the output path
+func (uc *diagnosticsUseCase) Extract(ctx context.Context, input dto.ExtractInput) (*dto.ExtractOutput, error) {
+	log := uc.logger.WithContext(ctx).With("video_path", input.VideoPath)
+	if strings.TrimSpace(input.VideoPath) == "" {
+		return nil, domain.NewInvalidInputError("a local video file is required")
+	}
+
+	cfg := configureProcessing(input.Configuration, log)
+	if err := cfg.Validate(); err != nil {
+		return nil, domain.NewInvalidInputError(err.Error())
+	}
+	outputPath := input.OutputPath
+	if outputPath == "" {
+		outputPath = strings.TrimSuffix(input.VideoPath, filepath.Ext(input.VideoPath)) + "_frames.zip"
+	}
+
+	if err := uc.videoProcessor.ValidateVideo(ctx, input.VideoPath); err != nil {
+		log.Error("Video validation failed", "error", err)
+		return nil, domain.NewValidationError(err)
+	}
+	extraction, err := uc.videoProcessor.ProcessVideo(ctx, input.VideoPath, cfg)
+	if err != nil {
+		log.Error("Failed to process video", "error", err)
+		return nil, domain.NewInternalError(fmt.Errorf("failed to process video: %w", err))
+	}
+	frameCount, zipPath := extraction.FrameCount, extraction.ArchivePath
+	defer uc.cleanupFile(ctx, zipPath, "temp zip file")
+
+	reader, err := uc.fileManager.ReadFile(ctx, zipPath)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Errorf("failed to read zip file: %w", err))
+	}
+	defer func() {
+		_ = reader.Close()
+	}()
+	if err := uc.fileManager.WriteToFile(ctx, outputPath, reader); err != nil {
+		return nil, domain.NewInternalError(fmt.Errorf("failed to write %s: %w", outputPath, err))
+	}
+	size, err := uc.fileManager.GetFileSize(ctx, outputPath)
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Errorf("failed to get archive size: %w", err))
+	}
+
+	log.Info("Frames extracted", "frame_count", frameCount, "output_path", outputPath)
+	return &dto.ExtractOutput{
+		VideoPath:  input.VideoPath,
+		OutputPath: outputPath,
+		FrameCount: frameCount,
+		Size:       size,
+	}, nil
+}
+
+// Verify checks the integrity of a local frame archive; the problems found are reported in the output
+func (uc *diagnosticsUseCase) Verify(ctx context.Context, input dto.VerifyInput) (*dto.VerifyOutput, error) {
+	if strings.TrimSpace(input.ArchivePath) == "" {
+		return nil, domain.NewInvalidInputError("a local archive file is required")
+	}
+
+	archive, err := uc.videoProcessor.VerifyArchive(ctx, input.ArchivePath)
+	if err != nil {
+		uc.logger.WithContext(ctx).Error("Failed to verify archive", "archive_path", input.ArchivePath, "error", err)
+		return nil, domain.NewInvalidInputError(err.Error())
+	}
+
+	return &dto.VerifyOutput{
+		ArchivePath: input.ArchivePath,
+		Valid:       archive.Valid(),
+		Size:        archive.Size,
+		SHA256:      archive.SHA256,
+		FrameCount:  archive.FrameCount,
+		Format:      archive.Format,
+		Width:       archive.Width,
+		Height:      archive.Height,
+		FirstFrame:  archive.FirstFrame,
+		LastFrame:   archive.LastFrame,
+		Problems:    archive.Problems,
+	}, nil
+}
+
+// Version adds the detected ffmpeg capabilities to the build information; a missing ffmpeg is reported, not failed
+func (uc *diagnosticsUseCase) Version(ctx context.Context, input dto.VersionInput) (*dto.VersionOutput, error) {
+	output := &dto.VersionOutput{VersionInput: input}
+
+	capabilities, err := uc.videoProcessor.Capabilities(ctx)
+	if err != nil {
+		uc.logger.WithContext(ctx).Warn("Failed to detect ffmpeg capabilities", "error", err)
+		output.CapabilitiesError = err.Error()
+	}
+	if capabilities != nil {
+		output.FFmpegVersion = capabilities.FFmpegVersion
+		output.FFprobeVersion = capabilities.FFprobeVersion
+		output.Encoders = capabilities.Encoders
+		output.HWAccels = capabilities.HWAccels
+	}
+	return output, nil
+}
+
+// cleanupFile safely deletes temporary files
+func (uc *diagnosticsUseCase) cleanupFile(ctx context.Context, filePath, fileType string) {
+	if err := uc.fileManager.DeleteFile(ctx, filePath); err != nil {
+		uc.logger.WithContext(ctx).Warn("Failed to delete "+fileType, "path", filePath, "error", err)
+	}
+}
@@ -0,0 +1,174 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+func TestDiagnosticsUseCase(t *testing.T) {
+	metadata := &entity.VideoMetadata{
+		FormatName: "mov,mp4",
+		Duration:   10,
+		Streams:    []entity.StreamMetadata{{Index: 0, CodecType: "video", CodecName: "h264", Width: 640, Height: 360, FrameRate: 25}},
+	}
+
+	t.Run("Probe/local_file", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewDiagnosticsUseCase(nil, vp, fm, logger.NewSlogLogger())
+
+		fm.EXPECT().GetFileSize(gomock.Any(), "/videos/a.mp4").Return(int64(100), nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), "/videos/a.mp4").Return(metadata, nil)
+
+		out, err := uc.Probe(context.Background(), dto.ProbeInput{Source: "/videos/a.mp4"})
+		r.NoError(err)
+		r.False(out.FromStorage)
+		r.Equal("mov,mp4", out.FormatName)
+		r.Equal([]dto.StreamOutput{{Index: 0, CodecType: "video", CodecName: "h264", Width: 640, Height: 360, FrameRate: 25}}, out.Streams)
+	})
+
+	t.Run("Probe/storage_key", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewDiagnosticsUseCase(vg, vp, fm, logger.NewSlogLogger())
+
+		fm.EXPECT().GetFileSize(gomock.Any(), "videos/a.mp4").Return(int64(0), errors.New("no such file"))
+		fm.EXPECT().CreateTempFile(gomock.Any(), "probe_", ".mp4").Return("/tmp/probe_1.mp4", nil)
+		vg.EXPECT().Download(gomock.Any(), "videos/a.mp4", entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("data")), testVersion, nil)
+		fm.EXPECT().WriteToFile(gomock.Any(), "/tmp/probe_1.mp4", gomock.Any()).Return(nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), "/tmp/probe_1.mp4").Return(metadata, nil)
+		fm.EXPECT().DeleteFile(gomock.Any(), "/tmp/probe_1.mp4").Return(nil)
+
+		out, err := uc.Probe(context.Background(), dto.ProbeInput{Source: "videos/a.mp4"})
+		r.NoError(err)
+		r.True(out.FromStorage)
+		r.Equal("videos/a.mp4", out.Source)
+	})
+
+	t.Run("Probe/missing_key", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewDiagnosticsUseCase(vg, vp, fm, logger.NewSlogLogger())
+
+		fm.EXPECT().GetFileSize(gomock.Any(), "videos/a.mp4").Return(int64(0), errors.New("no such file"))
+		fm.EXPECT().CreateTempFile(gomock.Any(), "probe_", ".mp4").Return("/tmp/probe_1.mp4", nil)
+		vg.EXPECT().Download(gomock.Any(), "videos/a.mp4", entity.ObjectVersion{}).Return(nil, entity.ObjectVersion{}, domain.NewNotFoundError("not found"))
+		fm.EXPECT().DeleteFile(gomock.Any(), "/tmp/probe_1.mp4").Return(nil)
+
+		_, err := uc.Probe(context.Background(), dto.ProbeInput{Source: "videos/a.mp4"})
+		var nErr *domain.NotFoundError
+		r.ErrorAs(err, &nErr)
+	})
+
+	t.Run("Probe/key_without_storage", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewDiagnosticsUseCase(nil, pmocks.NewMockVideoProcessor(ctrl), fm, logger.NewSlogLogger())
+
+		fm.EXPECT().GetFileSize(gomock.Any(), "videos/a.mp4").Return(int64(0), errors.New("no such file"))
+
+		_, err := uc.Probe(context.Background(), dto.ProbeInput{Source: "videos/a.mp4"})
+		var iErr *domain.InvalidInputError
+		require.ErrorAs(t, err, &iErr)
+	})
+
+	t.Run("Extract/writes_archive_next_to_video", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewDiagnosticsUseCase(nil, vp, fm, logger.NewSlogLogger())
+
+		config := entity.ProcessingConfig{FrameRate: 2, OutputFormat: "png", Width: 320}
+		vp.EXPECT().ValidateVideo(gomock.Any(), "/videos/a.mp4").Return(nil)
+		vp.EXPECT().ProcessVideo(gomock.Any(), "/videos/a.mp4", config).Return(&entity.FrameExtraction{FrameCount: 20, ArchivePath: "/tmp/frames.zip"}, nil)
+		fm.EXPECT().ReadFile(gomock.Any(), "/tmp/frames.zip").Return(io.NopCloser(strings.NewReader("zipdata")), nil)
+		fm.EXPECT().WriteToFile(gomock.Any(), "/videos/a_frames.zip", gomock.Any()).Return(nil)
+		fm.EXPECT().GetFileSize(gomock.Any(), "/videos/a_frames.zip").Return(int64(7), nil)
+		fm.EXPECT().DeleteFile(gomock.Any(), "/tmp/frames.zip").Return(nil)
+
+		out, err := uc.Extract(context.Background(), dto.ExtractInput{
+			VideoPath:     "/videos/a.mp4",
+			Configuration: &dto.ProcessingConfigInput{FrameRate: 2, OutputFormat: "png", Width: 320},
+		})
+		r.NoError(err)
+		r.Equal(&dto.ExtractOutput{VideoPath: "/videos/a.mp4", OutputPath: "/videos/a_frames.zip", FrameCount: 20, Size: 7}, out)
+	})
+
+	t.Run("Extract/invalid_config", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		uc := NewDiagnosticsUseCase(nil, pmocks.NewMockVideoProcessor(ctrl), pmocks.NewMockFileManager(ctrl), logger.NewSlogLogger())
+
+		_, err := uc.Extract(context.Background(), dto.ExtractInput{
+			VideoPath:     "/videos/a.mp4",
+			Configuration: &dto.ProcessingConfigInput{FrameRate: 1, OutputFormat: "jpg", StartTime: 5, EndTime: 2},
+		})
+		var iErr *domain.InvalidInputError
+		require.ErrorAs(t, err, &iErr)
+		require.ErrorContains(t, err, "end_time")
+	})
+
+	t.Run("Verify/reports_problems", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		uc := NewDiagnosticsUseCase(nil, vp, pmocks.NewMockFileManager(ctrl), logger.NewSlogLogger())
+
+		vp.EXPECT().VerifyArchive(gomock.Any(), "frames.zip").Return(&entity.FrameArchive{
+			FrameCount: 2, Format: "jpg", FirstFrame: 1, LastFrame: 4, Problems: []string{"frames 2 to 3 are missing"},
+		}, nil)
+
+		out, err := uc.Verify(context.Background(), dto.VerifyInput{ArchivePath: "frames.zip"})
+		r.NoError(err)
+		r.False(out.Valid)
+		r.Equal(4, out.LastFrame)
+		r.Equal([]string{"frames 2 to 3 are missing"}, out.Problems)
+	})
+
+	t.Run("Verify/unreadable_archive", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		uc := NewDiagnosticsUseCase(nil, vp, pmocks.NewMockFileManager(ctrl), logger.NewSlogLogger())
+
+		vp.EXPECT().VerifyArchive(gomock.Any(), "missing.zip").Return(nil, errors.New("failed to open archive"))
+
+		_, err := uc.Verify(context.Background(), dto.VerifyInput{ArchivePath: "missing.zip"})
+		var iErr *domain.InvalidInputError
+		require.ErrorAs(t, err, &iErr)
+	})
+
+	t.Run("Version/reports_missing_ffmpeg", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		uc := NewDiagnosticsUseCase(nil, vp, pmocks.NewMockFileManager(ctrl), logger.NewSlogLogger())
+
+		vp.EXPECT().Capabilities(gomock.Any()).Return(&entity.ProcessorCapabilities{FFprobeVersion: "ffprobe version 6.1"}, errors.New("ffmpeg -version failed"))
+
+		out, err := uc.Version(context.Background(), dto.VersionInput{Version: "1.2.0"})
+		r.NoError(err)
+		r.Equal("1.2.0", out.Version)
+		r.Equal("ffprobe version 6.1", out.FFprobeVersion)
+		r.Equal("ffmpeg -version failed", out.CapabilitiesError)
+	})
+}
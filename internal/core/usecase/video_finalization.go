@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+const (
+	// statusPublishAttempts is how many times the FINISHED status is published before giving up
+	statusPublishAttempts = 3
+
+	// defaultPublishBackoff is the wait before the first publish retry, doubled on every attempt
+	defaultPublishBackoff = 500 * time.Millisecond
+)
+
+// finalize runs the ordered finalization saga once frames are extracted:
+//
+//  1. upload the archive
+//  2. verify the uploaded artifact
+//  3. upload the processing report and publish FINISHED with its key, with retries, until the broker acknowledged it
+//  4. apply the retention policy to the original video
+//
+// The original video is only touched after upstream has been told about the result. A failed step compensates the
+// completed ones: an unverified archive is removed unless a previous run stored it, and an unpublished status leaves
+// the source in place so the job can be retried; the archive key is derived from the video hash, so re-running is
+// idempotent.
+func (uc *videoUseCase) finalize(
+	ctx context.Context,
+	input dto.ProcessVideoInput,
+	source *sourceVideo,
+	policy entity.RetentionPolicy,
+	zipPath string,
+	frameCount int,
+) (*dto.ProcessVideoOutput, error) {
+	log := uc.logger.WithContext(ctx)
+
+	// Step 1: Upload result using hash as filename
+	stageCtx, stage := startStage(ctx, string(domain.StageUpload))
+	outputKey, size, created, err := uc.uploadResult(stageCtx, zipPath, source.Hash)
+	stage.finish(err)
+	if err != nil {
+		return uc.createErrorResponse(domain.StageUpload, "Failed to upload result", err)
+	}
+	report := jobReportFromContext(ctx)
+	report.OutputKey = outputKey
+	report.BytesUploaded = size
+
+	// Step 2: Verify the uploaded artifact, removing it when it cannot be trusted
+	stageCtx, stage = startStage(ctx, string(domain.StageVerify))
+	err = uc.verifyUpload(stageCtx, outputKey, size)
+	stage.finish(err)
+	if err != nil {
+		uc.compensateUpload(ctx, outputKey, created)
+		return uc.createErrorResponse(domain.StageVerify, "Failed to verify uploaded result", err)
+	}
+
+	// Step 3: Upload the report and publish FINISHED; the retention action is announced as scheduled
+	update := dto.VideoStatusUpdate{
+		VideoId: input.VideoId,
+		UserId:  input.UserId,
+		Hash:    source.Hash,
+		Status:  "FINISHED",
+		Retention: &dto.RetentionOutput{
+			Action: policy.String(),
+			Status: dto.RetentionStatusScheduled,
+		},
+		// Queued is not enough: the source must not be touched before the broker has the event
+		AwaitDelivery: true,
+	}
+	update.ReportKey = uc.uploadReport(ctx, report.finished(update.Retention))
+	if err := uc.publishStatus(ctx, update); err != nil {
+		// Compensation: keep the source so a retry can finish the job; the verified archive is left for reuse
+		log.Error("Failed to publish video status, original video left in place", "error", err, "output_key", outputKey)
+		out, rErr := uc.createErrorResponse(domain.StagePublish, "Failed to publish video status, original video left in place", domain.NewRetryableError(err))
+		out.OutputKey = outputKey
+		out.FrameCount = frameCount
+		out.Hash = source.Hash
+		return out, rErr
+	}
+
+	// Step 4: Apply the retention policy to the original video
+	stageCtx, stage = startStage(ctx, "retention", attribute.String("retention.action", policy.String()))
+	retentionOutput := uc.applyRetention(stageCtx, input.VideoKey, source.Version, policy)
+	stage.SetAttributes(attribute.String("retention.status", string(retentionOutput.Status)))
+	stage.finish(nil)
+	sourceChanged := retentionOutput.Status == dto.RetentionStatusSkipped
+
+	// Upstream was told the action was scheduled; only a deviation needs a follow-up event and an updated report
+	if retentionOutput.Status != dto.RetentionStatusApplied {
+		report.warn("retention %s: %s", retentionOutput.Status, retentionOutput.Detail)
+		update.Retention = retentionOutput
+		update.SourceChanged = sourceChanged
+		update.AwaitDelivery = false
+		update.ReportKey = uc.uploadReport(ctx, report.finished(retentionOutput))
+		if err := uc.publishStatus(ctx, update); err != nil {
+			log.Warn("Failed to publish retention outcome", "error", err, "retention_status", retentionOutput.Status)
+		}
+	}
+
+	log.Info("Video processing completed successfully", "frame_count", frameCount, "output_key", outputKey, "hash", source.Hash,
+		"retention_action", retentionOutput.Action, "retention_status", retentionOutput.Status)
+	return &dto.ProcessVideoOutput{
+		Success:       true,
+		Message:       fmt.Sprintf("Video processed successfully. %d frames extracted.", frameCount),
+		OutputKey:     outputKey,
+		FrameCount:    frameCount,
+		Hash:          source.Hash,
+		Retention:     retentionOutput,
+		SourceChanged: sourceChanged,
+		ReportKey:     update.ReportKey,
+	}, nil
+}
+
+// verifyUpload checks that the stored artifact exists and has the size that was uploaded
+func (uc *videoUseCase) verifyUpload(ctx context.Context, outputKey string, expectedSize int64) error {
+	log := uc.logger.WithContext(ctx).With("output_key", outputKey)
+	log.Info("Verifying uploaded result")
+
+	size, err := uc.videoGateway.StatOutput(ctx, outputKey)
+	if err != nil {
+		log.Error("Failed to stat uploaded result", "error", err)
+		return domain.NewRetryableError(fmt.Errorf("failed to stat uploaded result: %w", err))
+	}
+	if size != expectedSize {
+		log.Error("Uploaded result size mismatch", "expected_size", expectedSize, "size", size)
+		return fmt.Errorf("uploaded result size mismatch: expected %d bytes, got %d", expectedSize, size)
+	}
+
+	log.Info("Uploaded result verified", "size", size)
+	return nil
+}
+
+// compensateUpload removes an archive that failed verification so consumers never read a broken result. An archive
+// that existed before this run may have been announced by it, so it is left in place.
+func (uc *videoUseCase) compensateUpload(ctx context.Context, outputKey string, created bool) {
+	log := uc.logger.WithContext(ctx).With("output_key", outputKey)
+	if !created {
+		log.Warn("Unverified result was stored by a previous run, left in place")
+		jobReportFromContext(ctx).warn("unverified result %s was stored by a previous run, left in place", outputKey)
+		return
+	}
+	log.Warn("Removing unverified result")
+	if err := uc.videoGateway.DeleteOutput(ctx, outputKey); err != nil {
+		log.Error("Failed to remove unverified result", "error", err)
+		jobReportFromContext(ctx).warn("failed to remove unverified result %s: %v", outputKey, err)
+	}
+}
+
+// publishStatus publishes a status update, retrying with exponential backoff
+func (uc *videoUseCase) publishStatus(ctx context.Context, update dto.VideoStatusUpdate) error {
+	if update.EventId == "" {
+		update.EventId = uuid.NewString()
+	}
+	ctx, stage := startStage(ctx, string(domain.StagePublish), attribute.String("video.status", update.Status))
+	err := uc.publishWithRetries(ctx, update)
+	stage.finish(err)
+	return err
+}
+
+func (uc *videoUseCase) publishWithRetries(ctx context.Context, update dto.VideoStatusUpdate) error {
+	backoff := uc.publishBackoff
+
+	var err error
+	for attempt := 1; attempt <= statusPublishAttempts; attempt++ {
+		attemptCtx := logger.WithAttributes(ctx, "attempt", attempt)
+		log := uc.logger.WithContext(attemptCtx).With("status", update.Status)
+		log.Info("Updating video status")
+		if err = uc.videoGateway.UpdateStatus(attemptCtx, update); err == nil {
+			log.Info("Video status updated successfully")
+			return nil
+		}
+		log.Warn("Failed to update video status", "error", err)
+		jobReportFromContext(ctx).warn("publish %s attempt %d failed: %v", update.Status, attempt, err)
+
+		if attempt == statusPublishAttempts {
+			break
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("failed to publish status: %w", ctx.Err())
+		}
+		backoff *= 2
+	}
+
+	return fmt.Errorf("failed to publish status after %d attempts: %w", statusPublishAttempts, err)
+}
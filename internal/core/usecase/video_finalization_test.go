@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+func TestVideoUseCase_Finalization(t *testing.T) {
+	// setup builds the use case and expects a run up to the upload, returning the upload call for ordering; stored
+	// makes a previous run's archive exist under the output key
+	setup := func(t *testing.T, stored bool) (*pmocks.MockVideoGateway, *gomock.Call, *videoUseCase) {
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger()).(*videoUseCase)
+		uc.publishBackoff = 0
+
+		local := "/tmp/video.mp4"
+		zip := "/tmp/frames.zip"
+		expectWorkspace(fm, vg)
+		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(local, nil)
+		vg.EXPECT().Download(gomock.Any(), "vid.mp4", entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("x")), testVersion, nil)
+		fm.EXPECT().WriteToFile(gomock.Any(), local, gomock.Any()).Return(nil)
+		vp.EXPECT().ValidateVideo(gomock.Any(), local).Return(nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), local).Return(&entity.VideoMetadata{FormatName: "mov,mp4,m4a,3gp,3g2,mj2"}, nil)
+		vp.EXPECT().ProcessVideo(gomock.Any(), local, entity.ProcessingConfig{FrameRate: 1.0, OutputFormat: "jpg"}).Return(&entity.FrameExtraction{FrameCount: 2, ArchivePath: zip}, nil)
+		fm.EXPECT().ReadFile(gomock.Any(), zip).Return(io.NopCloser(strings.NewReader("zip")), nil)
+		fm.EXPECT().GetFileSize(gomock.Any(), zip).Return(int64(3), nil)
+		if stored {
+			vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+		} else {
+			expectNewOutput(vg)
+		}
+		upload := vg.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any(), "application/zip", int64(3)).DoAndReturn(
+			func(ctx context.Context, key string, r io.Reader, ct string, n int64) (string, error) {
+				return key, nil
+			})
+		fm.EXPECT().DeleteFile(gomock.Any(), local).Return(nil)
+		fm.EXPECT().DeleteFile(gomock.Any(), zip).Return(nil)
+		return vg, upload, uc
+	}
+
+	input := dto.ProcessVideoInput{VideoKey: "vid.mp4", VideoId: "7", UserId: "9"}
+
+	t.Run("success_runs_steps_in_order", func(t *testing.T) {
+		r := require.New(t)
+		vg, upload, uc := setup(t, false)
+
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		gomock.InOrder(
+			upload,
+			vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(3), nil),
+			vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Cond(func(u dto.VideoStatusUpdate) bool {
+				return u.Status == "FINISHED" && u.VideoId == "7" && u.UserId == "9" &&
+					u.Retention.Action == "delete" && u.Retention.Status == dto.RetentionStatusScheduled
+			})).Return(nil),
+			vg.EXPECT().Delete(gomock.Any(), "vid.mp4", testVersion).Return(nil),
+		)
+
+		out, err := uc.ProcessVideo(context.Background(), input)
+		r.NoError(err)
+		r.True(out.Success)
+		r.Equal(dto.RetentionStatusApplied, out.Retention.Status)
+	})
+
+	t.Run("verify_error_removes_upload", func(t *testing.T) {
+		r := require.New(t)
+		vg, _, uc := setup(t, false)
+
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("head failed"))
+		vg.EXPECT().DeleteOutput(gomock.Any(), gomock.Any()).Return(nil)
+		// no status update and no retention
+
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInternal, domain.StageVerify)).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), input)
+		var iErr *domain.InternalError
+		r.ErrorAs(err, &iErr)
+		r.False(out.Success)
+		r.Contains(out.Error, "Failed to verify uploaded result")
+	})
+
+	t.Run("size_mismatch_removes_upload", func(t *testing.T) {
+		r := require.New(t)
+		vg, _, uc := setup(t, false)
+
+		var uploadedKey string
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key string) (int64, error) {
+			uploadedKey = key
+			return 1, nil
+		})
+		vg.EXPECT().DeleteOutput(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, key string) error {
+			r.Equal(uploadedKey, key)
+			return nil
+		})
+
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInternal, domain.StageVerify)).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), input)
+		r.Error(err)
+		r.False(domain.IsRetryable(err))
+		r.Contains(out.Error, "size mismatch")
+	})
+
+	t.Run("compensation_failure_keeps_verify_error", func(t *testing.T) {
+		r := require.New(t)
+		vg, _, uc := setup(t, false)
+
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(1), nil)
+		vg.EXPECT().DeleteOutput(gomock.Any(), gomock.Any()).Return(errors.New("denied"))
+
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInternal, domain.StageVerify)).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), input)
+		r.Error(err)
+		r.Contains(out.Error, "size mismatch")
+	})
+
+	t.Run("verify_error_keeps_a_previous_archive", func(t *testing.T) {
+		r := require.New(t)
+		vg, _, uc := setup(t, true)
+
+		// The archive was stored, and possibly announced, by a previous run: no DeleteOutput
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("head failed"))
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, key string, report *dto.ProcessingReport) error {
+				r.Contains(strings.Join(report.Warnings, "\n"), "stored by a previous run")
+				return nil
+			})
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInternal, domain.StageVerify)).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), input)
+		r.Error(err)
+		r.Contains(out.Error, "Failed to verify uploaded result")
+	})
+
+	t.Run("publish_failure_leaves_source_in_place", func(t *testing.T) {
+		r := require.New(t)
+		vg, _, uc := setup(t, false)
+
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		vg.EXPECT().UpdateStatus(gomock.Any(), statusIs("FINISHED")).Return(errors.New("broker down")).Times(statusPublishAttempts)
+		// no Delete: the original video must survive so the job can be retried
+
+		out, err := uc.ProcessVideo(context.Background(), input)
+		var iErr *domain.InternalError
+		r.ErrorAs(err, &iErr)
+		r.True(iErr.Retryable)
+		r.False(out.Success)
+		r.NotEmpty(out.OutputKey)
+		r.NotEmpty(out.Hash)
+		r.Nil(out.Retention)
+	})
+
+	t.Run("publish_succeeds_on_retry", func(t *testing.T) {
+		r := require.New(t)
+		vg, _, uc := setup(t, false)
+
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		gomock.InOrder(
+			vg.EXPECT().UpdateStatus(gomock.Any(), statusIs("FINISHED")).Return(errors.New("throttled")),
+			vg.EXPECT().UpdateStatus(gomock.Any(), statusIs("FINISHED")).Return(nil),
+			vg.EXPECT().Delete(gomock.Any(), "vid.mp4", testVersion).Return(nil),
+		)
+
+		out, err := uc.ProcessVideo(context.Background(), input)
+		r.NoError(err)
+		r.True(out.Success)
+	})
+
+	t.Run("publish_stops_when_context_is_cancelled", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		uc := NewVideoUseCase(vg, nil, nil, nil, logger.NewSlogLogger()).(*videoUseCase)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, u dto.VideoStatusUpdate) error {
+			cancel()
+			return errors.New("broker down")
+		})
+
+		err := uc.publishStatus(ctx, dto.VideoStatusUpdate{Status: "FINISHED"})
+		r.ErrorIs(err, context.Canceled)
+	})
+}
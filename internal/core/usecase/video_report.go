@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/tracing"
+)
+
+type jobReportKey struct{}
+
+// jobReport collects the processing report of a job while it runs. It travels in the context so every stage records
+// its timing; a nil report records nothing.
+type jobReport struct {
+	dto.ProcessingReport
+}
+
+func newJobReport(input dto.ProcessVideoInput) *jobReport {
+	return &jobReport{ProcessingReport: dto.ProcessingReport{
+		VideoKey:  input.VideoKey,
+		VideoId:   input.VideoId,
+		UserId:    input.UserId,
+		StartedAt: time.Now(),
+	}}
+}
+
+func withJobReport(ctx context.Context, report *jobReport) context.Context {
+	return context.WithValue(ctx, jobReportKey{}, report)
+}
+
+func jobReportFromContext(ctx context.Context) *jobReport {
+	report, _ := ctx.Value(jobReportKey{}).(*jobReport)
+	return report
+}
+
+func (r *jobReport) addStage(stage string, duration time.Duration, err error) {
+	if r == nil {
+		return
+	}
+	timing := dto.StageTiming{Stage: stage, Duration: duration}
+	if err != nil {
+		timing.Error = err.Error()
+	}
+	r.Stages = append(r.Stages, timing)
+}
+
+func (r *jobReport) warn(format string, args ...any) {
+	if r == nil {
+		return
+	}
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// setConfiguration records the effective configuration, with the defaults filled in
+func (r *jobReport) setConfiguration(cfg entity.ProcessingConfig) {
+	r.Configuration = &dto.ProcessingConfigInput{
+		FrameRate:    cfg.FrameRate,
+		OutputFormat: cfg.OutputFormat,
+		Width:        cfg.Width,
+		Height:       cfg.Height,
+		JPEGQuality:  cfg.JPEGQuality,
+		StartTime:    cfg.StartTime,
+		EndTime:      cfg.EndTime,
+	}
+}
+
+func (r *jobReport) setExtraction(extraction *entity.FrameExtraction) {
+	r.FrameCount = extraction.FrameCount
+	r.FFmpegVersion = extraction.FFmpegVersion
+	r.FFmpegCommands = extraction.Commands
+}
+
+// finished snapshots the report of a job that reached the FINISHED status
+func (r *jobReport) finished(retention *dto.RetentionOutput) *dto.ProcessingReport {
+	r.Status = "FINISHED"
+	r.Retention = retention
+	r.Failure = nil
+	r.FinishedAt = time.Now()
+	return &r.ProcessingReport
+}
+
+// failed snapshots the report of a job that failed as described by output
+func (r *jobReport) failed(output *dto.ProcessVideoOutput) *dto.ProcessingReport {
+	r.Status = "FAILED"
+	r.Failure = &dto.FailureOutput{
+		Code:      output.ErrorCode,
+		Stage:     output.Stage,
+		Retryable: output.Retryable,
+		Message:   output.Error,
+	}
+	r.FinishedAt = time.Now()
+	return &r.ProcessingReport
+}
+
+// reportKey returns the key of the report.json of a job: next to the archive once the video hash is known, under
+// processed/failed/ otherwise
+func reportKey(videoKey, hash string) string {
+	if hash != "" {
+		return fmt.Sprintf("processed/%s.report.json", hash)
+	}
+	return fmt.Sprintf("processed/failed/%s.report.json", videoKey)
+}
+
+// uploadReport stores the report of a job, returning its key. The report is best effort: a failed upload is logged
+// and reported as an empty key, never failing the job.
+func (uc *videoUseCase) uploadReport(ctx context.Context, report *dto.ProcessingReport) string {
+	if report.VideoKey == "" {
+		return ""
+	}
+	key := reportKey(report.VideoKey, report.Hash)
+	log := uc.logger.WithContext(ctx).With("report_key", key)
+	if err := uc.videoGateway.UploadProcessingReport(ctx, key, report); err != nil {
+		log.Warn("Failed to upload processing report", "error", err)
+		return ""
+	}
+	log.Info("Processing report uploaded")
+	return key
+}
+
+type stageTrackerKey struct{}
+
+// stageTracker remembers the last stage started under a context, so a failure that escapes the stages, like a
+// panic, can still name it; a nil tracker records nothing
+type stageTracker struct {
+	mu    sync.Mutex
+	stage domain.Stage
+}
+
+func withStageTracker(ctx context.Context, initial domain.Stage) (context.Context, *stageTracker) {
+	tracker := &stageTracker{stage: initial}
+	return context.WithValue(ctx, stageTrackerKey{}, tracker), tracker
+}
+
+func stageTrackerFromContext(ctx context.Context) *stageTracker {
+	tracker, _ := ctx.Value(stageTrackerKey{}).(*stageTracker)
+	return tracker
+}
+
+func (t *stageTracker) enter(stage domain.Stage) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stage = stage
+}
+
+func (t *stageTracker) current() domain.Stage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.stage
+}
+
+// stageRun is a running processing stage: its span, and its timing in the job report
+type stageRun struct {
+	trace.Span
+	name   string
+	start  time.Time
+	report *jobReport
+}
+
+// startStage starts the span of a processing stage and names the stage in the logs of the returned context
+func startStage(ctx context.Context, stage string, attrs ...attribute.KeyValue) (context.Context, *stageRun) {
+	report := jobReportFromContext(ctx)
+	stageTrackerFromContext(ctx).enter(domain.Stage(stage))
+	ctx, span := tracing.Start(logger.WithAttributes(ctx, "stage", stage), stage, attrs...)
+	return ctx, &stageRun{Span: span, name: stage, start: time.Now(), report: report}
+}
+
+// finish ends the span of the stage and records its duration in the job report
+func (s *stageRun) finish(err error) {
+	s.report.addStage(s.name, time.Since(s.start), err)
+	tracing.End(s.Span, err)
+}
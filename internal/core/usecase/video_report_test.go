@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+func TestVideoUseCase_Report(t *testing.T) {
+	input := dto.ProcessVideoInput{
+		VideoKey:      "uploads/vid.mp4",
+		VideoId:       "7",
+		UserId:        "9",
+		Configuration: &dto.ProcessingConfigInput{FrameRate: 2, OutputFormat: "PNG", Width: 320},
+	}
+
+	t.Run("finished_job_uploads_its_report_before_the_status", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+
+		local := "/tmp/video.mp4"
+		zip := "/tmp/frames.zip"
+		expectWorkspace(fm, vg)
+		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(local, nil)
+		vg.EXPECT().Download(gomock.Any(), input.VideoKey, entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("video data")), testVersion, nil)
+		fm.EXPECT().WriteToFile(gomock.Any(), local, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, data io.Reader) error {
+			_, err := io.Copy(io.Discard, data)
+			return err
+		})
+		vp.EXPECT().ValidateVideo(gomock.Any(), local).Return(nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), local).Return(&entity.VideoMetadata{
+			FormatName: "mov,mp4,m4a,3gp,3g2,mj2",
+			Duration:   12.5,
+			Streams:    []entity.StreamMetadata{{CodecType: "video", CodecName: "h264", Width: 1280, Height: 720, FrameRate: 25}},
+		}, nil)
+		vp.EXPECT().ProcessVideo(gomock.Any(), local, entity.ProcessingConfig{FrameRate: 2, OutputFormat: "png", Width: 320}).
+			Return(&entity.FrameExtraction{
+				FrameCount:    25,
+				ArchivePath:   zip,
+				Commands:      []string{"ffmpeg -nostdin -i /tmp/video.mp4 frame_%04d.png"},
+				FFmpegVersion: "ffmpeg version 7.1",
+			}, nil)
+		fm.EXPECT().ReadFile(gomock.Any(), zip).Return(io.NopCloser(strings.NewReader("zip")), nil)
+		fm.EXPECT().GetFileSize(gomock.Any(), zip).Return(int64(3), nil)
+		expectNewOutput(vg)
+		vg.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any(), "application/zip", int64(3)).Return("", nil)
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+		fm.EXPECT().DeleteFile(gomock.Any(), local).Return(nil)
+		fm.EXPECT().DeleteFile(gomock.Any(), zip).Return(nil)
+
+		var report dto.ProcessingReport
+		var reportKey string
+		gomock.InOrder(
+			vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, key string, rep *dto.ProcessingReport) error {
+					reportKey, report = key, *rep
+					return nil
+				}),
+			vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Cond(func(u dto.VideoStatusUpdate) bool {
+				return u.Status == "FINISHED" && u.ReportKey == reportKey
+			})).Return(nil),
+			vg.EXPECT().Delete(gomock.Any(), input.VideoKey, testVersion).Return(nil),
+		)
+
+		out, err := uc.ProcessVideo(context.Background(), input)
+		r.NoError(err)
+		r.Equal("processed/"+out.Hash+".report.json", reportKey)
+		r.Equal(reportKey, out.ReportKey)
+
+		r.Equal("FINISHED", report.Status)
+		r.Equal(out.Hash, report.Hash)
+		r.Equal(testVersion.ETag, report.SourceETag)
+		r.Equal(int64(len("video data")), report.BytesDownloaded)
+		r.Equal(int64(3), report.BytesUploaded)
+		r.Equal(out.OutputKey, report.OutputKey)
+		r.Equal(&dto.ProcessingConfigInput{FrameRate: 2, OutputFormat: "png", Width: 320}, report.Configuration)
+		r.Equal(12.5, report.Metadata.Duration)
+		r.Equal(1280, report.Metadata.Streams[0].Width)
+		r.Equal(25, report.FrameCount)
+		r.Equal("ffmpeg version 7.1", report.FFmpegVersion)
+		r.Len(report.FFmpegCommands, 1)
+		r.Equal(dto.RetentionStatusScheduled, report.Retention.Status)
+		r.Nil(report.Failure)
+		r.Empty(report.Warnings)
+		r.False(report.FinishedAt.Before(report.StartedAt))
+
+		var stages []string
+		for _, stage := range report.Stages {
+			stages = append(stages, stage.Stage)
+		}
+		r.Equal([]string{"validate", "download", "extract", "upload", "verify"}, stages)
+	})
+
+	t.Run("failed_job_reports_the_failure_under_its_video_key", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, nil, fm, nil, logger.NewSlogLogger())
+
+		expectWorkspace(fm, vg)
+		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return("/tmp/v.mp4", nil)
+		vg.EXPECT().Download(gomock.Any(), input.VideoKey, entity.ObjectVersion{}).Return(nil, entity.ObjectVersion{}, errors.New("connection reset"))
+		fm.EXPECT().DeleteFile(gomock.Any(), "/tmp/v.mp4").Return(nil)
+
+		var report dto.ProcessingReport
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), "processed/failed/uploads/vid.mp4.report.json", gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, rep *dto.ProcessingReport) error {
+				report = *rep
+				return nil
+			})
+		vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Cond(func(u dto.VideoStatusUpdate) bool {
+			return u.Status == "FAILED" && u.ReportKey == "processed/failed/uploads/vid.mp4.report.json"
+		})).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), input)
+		r.Error(err)
+		r.Equal("processed/failed/uploads/vid.mp4.report.json", out.ReportKey)
+		r.Equal("FAILED", report.Status)
+		r.Equal(string(domain.StageDownload), report.Failure.Stage)
+		r.True(report.Failure.Retryable)
+		r.Len(report.Stages, 1)
+		r.Contains(report.Stages[0].Error, "connection reset")
+	})
+
+	t.Run("unreachable_storage_leaves_the_report_key_empty", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		uc := NewVideoUseCase(vg, nil, nil, nil, logger.NewSlogLogger())
+
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("no such host"))
+		vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Cond(func(u dto.VideoStatusUpdate) bool {
+			return u.Status == "FAILED" && u.ReportKey == ""
+		})).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4", RetentionPolicy: "archive"})
+		var inv *domain.InvalidInputError
+		r.ErrorAs(err, &inv)
+		r.Empty(out.ReportKey)
+	})
+}
@@ -8,44 +8,115 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/tracing"
 )
 
 type videoUseCase struct {
 	videoGateway   port.VideoGateway
 	videoProcessor port.VideoProcessor
 	fileManager    port.FileManager
+	// sourceCache is nil when downloaded videos are not cached
+	sourceCache    port.SourceCache
 	logger         logger.Logger
+	publishBackoff time.Duration
 }
 
+// NewVideoUseCase creates the video use case; a nil sourceCache downloads every video
 func NewVideoUseCase(
 	videoGateway port.VideoGateway,
 	videoProcessor port.VideoProcessor,
 	fileManager port.FileManager,
+	sourceCache port.SourceCache,
 	logger logger.Logger,
 ) port.VideoUseCase {
 	return &videoUseCase{
 		videoGateway:   videoGateway,
 		videoProcessor: videoProcessor,
 		fileManager:    fileManager,
+		sourceCache:    sourceCache,
 		logger:         logger,
+		publishBackoff: defaultPublishBackoff,
 	}
 }
 
 func (uc *videoUseCase) ProcessVideo(ctx context.Context, input dto.ProcessVideoInput) (*dto.ProcessVideoOutput, error) {
-	log := uc.logger.WithContext(ctx).With("video_key", input.VideoKey)
+	ctx = logger.WithAttributes(ctx, "video_key", input.VideoKey, "video_id", input.VideoId, "user_id", input.UserId)
+	ctx, span := tracing.Start(ctx, "VideoUseCase.ProcessVideo",
+		attribute.String("video.key", input.VideoKey), attribute.String("video.id", input.VideoId))
+	report := newJobReport(input)
+	ctx = withJobReport(ctx, report)
+	output, err := uc.processVideo(ctx, input)
+
+	if err != nil && output != nil {
+		// A failed job has a report too; it replaces the one uploaded before a FINISHED status that failed to publish
+		output.ReportKey = uc.uploadReport(ctx, report.failed(output))
+
+		// Upstream is told about failures too, unless publishing the status is what failed
+		if domain.StageOf(err) != domain.StagePublish {
+			uc.publishFailure(ctx, input, output)
+		}
+	}
+
+	// The callback reports the final result, whether the job succeeded or not; an invalid callback is never sent
+	if input.Callback != nil && input.Callback.URL != "" && input.Validate() == nil && output != nil {
+		output.Callback = uc.notifyCallback(ctx, input, output)
+	}
+	tracing.End(span, err)
+	return output, err
+}
+
+func (uc *videoUseCase) processVideo(ctx context.Context, input dto.ProcessVideoInput) (*dto.ProcessVideoOutput, error) {
+	log := uc.logger.WithContext(ctx)
 	log.Info("Starting video processing")
 
+	// Fail-fast: invalid retention policy
+	retention, err := entity.ParseRetentionPolicy(input.RetentionPolicy)
+	if err != nil {
+		return uc.createErrorResponse(domain.StageInput, "", domain.NewInvalidInputError(err.Error()))
+	}
+
+	// Fail-fast: IDs that do not match the configured format
+	if err := validateIDs(input); err != nil {
+		return uc.createErrorResponse(domain.StageInput, "", domain.NewInvalidInputError(err.Error()))
+	}
+
+	// Fail-fast: a callback that cannot be signed
+	if err := input.Validate(); err != nil {
+		return uc.createErrorResponse(domain.StageInput, "", domain.NewInvalidInputError(err.Error()))
+	}
+
+	// Every temp file of the job lives in its own workspace, removed with whatever is left in it
+	ctx, err = uc.fileManager.OpenWorkspace(ctx)
+	if err != nil {
+		return uc.createErrorResponse(domain.StageDownload, "Failed to create workspace", err)
+	}
+	defer uc.closeWorkspace(ctx)
+
 	// Step 1: Download and validate video
-	localVideoPath, videoHash, err := uc.downloadAndValidateVideo(ctx, input.VideoKey)
+	stageCtx, stage := startStage(ctx, string(domain.StageDownload))
+	source, err := uc.downloadAndValidateVideo(stageCtx, input.VideoKey, entity.ObjectVersion{ETag: input.SourceETag})
+	stage.finish(err)
 	if err != nil {
-		return uc.createErrorResponse("Failed to download or validate video", err)
+		return uc.createErrorResponse(domain.StageDownload, "Failed to download or validate video", err)
+	}
+	report := jobReportFromContext(ctx)
+	report.Hash = source.Hash
+	report.SourceETag = source.Version.ETag
+	report.SourceCache = source.Cache
+	if source.Cache != dto.SourceCacheHit {
+		report.BytesDownloaded = source.Size
 	}
+	report.Metadata = source.Metadata
+	localVideoPath := source.Path
 	defer func() {
 		if localVideoPath != "" {
 			uc.cleanupFile(ctx, localVideoPath, "temp video file")
@@ -53,120 +124,268 @@ func (uc *videoUseCase) ProcessVideo(ctx context.Context, input dto.ProcessVideo
 	}()
 
 	// Step 2: Configure processing parameters
-	cfg := uc.configureProcessing(input.Configuration, log)
+	cfg := configureProcessing(input.Configuration, log)
+	report.setConfiguration(cfg)
 
-	// Fail-fast: unsupported output_format
-	if cfg.OutputFormat != "jpg" && cfg.OutputFormat != "png" {
-		invErr := domain.NewInvalidInputError(fmt.Sprintf("unsupported output_format: %q (allowed: jpg, png)", cfg.OutputFormat))
-		return &dto.ProcessVideoOutput{
-			Success: false,
-			Message: "Processing failed",
-			Error:   invErr.Error(),
-		}, invErr
+	// Fail-fast: invalid processing configuration
+	if err := cfg.Validate(); err != nil {
+		return uc.createErrorResponse(domain.StageInput, "", domain.NewInvalidInputError(err.Error()))
 	}
 
 	// Step 3: Extract frames from video
-	frameCount, zipPath, err := uc.extractFrames(ctx, localVideoPath, cfg)
+	stageCtx, stage = startStage(ctx, string(domain.StageExtract))
+	extraction, err := uc.extractFrames(stageCtx, localVideoPath, cfg)
+	if err == nil {
+		stage.SetAttributes(attribute.Int("video.frames", extraction.FrameCount))
+	}
+	stage.finish(err)
 	if err != nil {
-		return uc.createErrorResponse("Failed to extract frames", err)
+		return uc.createErrorResponse(domain.StageExtract, "Failed to extract frames", err)
 	}
+	report.setExtraction(extraction)
+	frameCount, zipPath := extraction.FrameCount, extraction.ArchivePath
 	defer uc.cleanupFile(ctx, zipPath, "temp zip file")
 
 	if frameCount == 0 {
 		log.Warn("No frames extracted from video")
-		return &dto.ProcessVideoOutput{
-			Success: false,
-			Message: "Processing failed",
-			Error:   "No frames extracted from video",
-		}, domain.NewInvalidInputError("no frames extracted from video")
+		return uc.createErrorResponse(domain.StageExtract, "", domain.NewInvalidInputError("no frames extracted from video"))
 	}
 
-	// Step 4: Upload result using hash as filename
-	outputKey, err := uc.uploadResult(ctx, zipPath, videoHash)
+	// Step 4: Upload, verify, publish and apply retention as an ordered saga
+	return uc.finalize(ctx, input, source, retention, zipPath, frameCount)
+}
+
+// validateIDs checks the video and user IDs against the requested format, if any
+func validateIDs(input dto.ProcessVideoInput) error {
+	if input.IdFormat == "" {
+		return nil
+	}
+	format, err := entity.ParseIDFormat(input.IdFormat)
 	if err != nil {
-		return uc.createErrorResponse("Failed to upload result", err)
+		return err
 	}
+	if err := format.Validate("video id", input.VideoId); err != nil {
+		return err
+	}
+	return format.Validate("user id", input.UserId)
+}
 
-	// Step 5: Cleanup - delete original video
-	uc.deleteOriginalVideo(ctx, input.VideoKey)
-
-	// Step 6: Update video status
-	uc.updateVideoStatus(ctx, input.VideoId, input.UserId, videoHash, "FINISHED")
-
-	log.Info("Video processing completed successfully", "frame_count", frameCount, "output_key", outputKey, "hash", videoHash)
-	return &dto.ProcessVideoOutput{
-		Success:    true,
-		Message:    fmt.Sprintf("Video processed successfully. %d frames extracted.", frameCount),
-		OutputKey:  outputKey,
-		FrameCount: frameCount,
-		Hash:       videoHash,
-	}, nil
+// sourceVideo is the local copy of the original video and the storage revision it was read from
+type sourceVideo struct {
+	Path    string
+	Hash    string
+	Size    int64
+	Version entity.ObjectVersion
+	// Metadata is nil when the video could not be probed
+	Metadata *dto.ProbeOutput
+	// Cache is the outcome of the source cache lookup, empty when the cache was not used
+	Cache string
 }
 
-// downloadAndValidateVideo downloads video, generates hash and validates it
-func (uc *videoUseCase) downloadAndValidateVideo(ctx context.Context, videoKey string) (string, string, error) {
-	log := uc.logger.WithContext(ctx).With("video_key", videoKey)
-	log.Info("Starting video download")
+// downloadAndValidateVideo downloads video, generates hash and validates it. A cached copy of the revision skips the
+// download and the hashing.
+// A non-zero expected version fails the download when the object was replaced since it was announced.
+func (uc *videoUseCase) downloadAndValidateVideo(ctx context.Context, videoKey string, expected entity.ObjectVersion) (*sourceVideo, error) {
+	log := uc.logger.WithContext(ctx)
+
+	// Fail before downloading when the video, its frames and their archive cannot fit in the workspace
+	object, err := uc.ensureSpace(ctx, videoKey)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create temp file for video
 	tempFile, err := uc.fileManager.CreateTempFile(ctx, "video_", ".mp4")
 	if err != nil {
 		log.Error("Failed to create temp file", "error", err)
-		return "", "", fmt.Errorf("failed to create temp file: %w", err)
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
 	}
 	log.Debug("Temp file created", "temp_file", tempFile)
 
+	source := &sourceVideo{Path: tempFile, Size: object.Size, Version: object.Version}
+	source.Hash, source.Cache = uc.fetchCachedVideo(ctx, videoKey, expected, object, tempFile)
+	if source.Cache != dto.SourceCacheHit {
+		source.Hash, source.Size, source.Version, err = uc.downloadVideo(ctx, videoKey, expected, tempFile)
+		if err != nil {
+			// Cleanup temp file on download error
+			uc.cleanupFile(ctx, tempFile, "temp video file")
+			return nil, err
+		}
+	}
+
+	// Validate video format
+	log.Info("Validating video format")
+	validateCtx, stage := startStage(ctx, string(domain.StageValidate))
+	err = uc.videoProcessor.ValidateVideo(validateCtx, tempFile)
+	var metadata *dto.ProbeOutput
+	if err == nil {
+		metadata = uc.probeVideo(validateCtx, tempFile)
+	}
+	stage.finish(err)
+	if err != nil {
+		uc.logger.WithContext(validateCtx).Error("Video validation failed", "error", err)
+		// Cleanup temp file on validation error
+		uc.cleanupFile(ctx, tempFile, "temp video file")
+		return nil, domain.WithStage(domain.StageValidate, domain.NewValidationError(err))
+	}
+	log.Info("Video format validated successfully")
+
+	// Only valid videos are cached, a re-run of an invalid one fails the same either way
+	if source.Cache != dto.SourceCacheHit {
+		uc.storeCachedVideo(ctx, videoKey, source)
+	}
+	source.Metadata = metadata
+	return source, nil
+}
+
+// downloadVideo downloads the video to path, returning its hash, its size and the revision read
+func (uc *videoUseCase) downloadVideo(ctx context.Context, videoKey string, expected entity.ObjectVersion, path string) (string, int64, entity.ObjectVersion, error) {
+	log := uc.logger.WithContext(ctx)
+	log.Info("Starting video download")
+
 	// Download video from storage
-	reader, err := uc.videoGateway.Download(ctx, videoKey)
+	reader, version, err := uc.videoGateway.Download(ctx, videoKey, expected)
 	if err != nil {
 		log.Error("Failed to download from storage", "error", err)
-		// Cleanup temp file on download error
-		uc.cleanupFile(ctx, tempFile, "temp video file")
-		var nErr *domain.NotFoundError
-		if errors.As(err, &nErr) {
-			return "", "", domain.NewNotFoundError(domain.ErrNotFound)
-		}
-		return "", "", fmt.Errorf("failed to download from storage: %w", err)
+		return "", 0, entity.ObjectVersion{}, storageReadError("failed to download from storage", err)
 	}
 	defer func() {
 		if cerr := reader.Close(); cerr != nil {
 			log.Warn("Failed to close reader", "error", cerr)
 		}
 	}()
-	log.Debug("Video reader obtained from storage")
+	log.Debug("Video reader obtained from storage", "etag", version.ETag, "version_id", version.VersionId)
 
 	// Write to file and generate hash simultaneously
-	hash, err := uc.writeFileAndGenerateHash(ctx, tempFile, reader)
+	hash, size, err := uc.writeFileAndGenerateHash(ctx, path, reader)
 	if err != nil {
 		log.Error("Failed to write file and generate hash", "error", err)
-		// Cleanup temp file on write error
-		uc.cleanupFile(ctx, tempFile, "temp video file")
-		return "", "", fmt.Errorf("failed to write file and generate hash: %w", err)
+		return "", 0, entity.ObjectVersion{}, fmt.Errorf("failed to write file and generate hash: %w", err)
 	}
-	log.Info("Video downloaded successfully", "local_path", tempFile, "hash", hash)
+	log.Info("Video downloaded successfully", "local_path", path, "hash", hash, "size", size)
+	return hash, size, version, nil
+}
 
-	// Validate video format
-	log.Info("Validating video format")
-	if err := uc.videoProcessor.ValidateVideo(ctx, tempFile); err != nil {
-		log.Error("Video validation failed", "error", err)
-		// Cleanup temp file on validation error
-		uc.cleanupFile(ctx, tempFile, "temp video file")
-		return "", "", domain.NewValidationError(err)
+// fetchCachedVideo copies the cached copy of the stored revision to path, returning its hash and the outcome of the
+// lookup. Revisions other than the expected one are not looked up, so the download reports the conflict.
+func (uc *videoUseCase) fetchCachedVideo(ctx context.Context, videoKey string, expected entity.ObjectVersion, object *entity.StorageObject, path string) (string, string) {
+	if uc.sourceCache == nil || object.Version.ETag == "" {
+		return "", ""
 	}
-	log.Info("Video format validated successfully")
+	if (expected.ETag != "" && expected.ETag != object.Version.ETag) ||
+		(expected.VersionId != "" && expected.VersionId != object.Version.VersionId) {
+		return "", ""
+	}
+
+	log := uc.logger.WithContext(ctx).With("etag", object.Version.ETag)
+	hash, found, err := uc.sourceCache.Fetch(ctx, videoKey, object.Version, path)
+	if err != nil {
+		log.Warn("Failed to read source cache, downloading the video", "error", err)
+		return "", dto.SourceCacheMiss
+	}
+	if !found {
+		log.Info("Source cache miss")
+		return "", dto.SourceCacheMiss
+	}
+	log.Info("Source cache hit, skipping download", "local_path", path, "hash", hash, "size", object.Size)
+	return hash, dto.SourceCacheHit
+}
+
+// storeCachedVideo adds a downloaded video to the source cache; a failure is only a warning
+func (uc *videoUseCase) storeCachedVideo(ctx context.Context, videoKey string, source *sourceVideo) {
+	if uc.sourceCache == nil {
+		return
+	}
+	if err := uc.sourceCache.Store(ctx, videoKey, source.Version, source.Path, source.Hash); err != nil {
+		uc.logger.WithContext(ctx).Warn("Failed to store video in the source cache", "error", err)
+		jobReportFromContext(ctx).warn("failed to store video in the source cache: %v", err)
+	}
+}
+
+// ensureSpace checks the workspace has room for the video, its frames and their archive, estimated from the size
+// of the stored video, which it returns
+func (uc *videoUseCase) ensureSpace(ctx context.Context, videoKey string) (*entity.StorageObject, error) {
+	log := uc.logger.WithContext(ctx)
+	object := sourceObjectFromContext(ctx, videoKey)
+	if object == nil {
+		var err error
+		if object, err = uc.videoGateway.Stat(ctx, videoKey); err != nil {
+			log.Error("Failed to stat video", "error", err)
+			return nil, storageReadError("failed to stat video", err)
+		}
+	}
+	required := object.Size * tempSpaceFactor
+	if err := uc.fileManager.EnsureSpace(ctx, required); err != nil {
+		log.Error("Not enough space in the workspace", "video_size", object.Size, "required_bytes", required, "error", err)
+		return nil, fmt.Errorf("not enough space to process the video: %w", err)
+	}
+	log.Debug("Workspace space checked", "video_size", object.Size, "required_bytes", required)
+	return object, nil
+}
+
+type sourceObjectKey struct{}
+
+// withSourceObject hands the stat of a video read right before its run, e.g. by a batch, to the run, which then
+// does not read it again
+func withSourceObject(ctx context.Context, object entity.StorageObject) context.Context {
+	return context.WithValue(ctx, sourceObjectKey{}, &object)
+}
+
+// sourceObjectFromContext returns the stat of the video under key carried by ctx, nil when there is none
+func sourceObjectFromContext(ctx context.Context, key string) *entity.StorageObject {
+	object, _ := ctx.Value(sourceObjectKey{}).(*entity.StorageObject)
+	if object == nil || object.Key != key {
+		return nil
+	}
+	return object
+}
+
+// storageReadError classifies a failed read of the original video: a missing video is not found and a source
+// replaced since it was announced fails every run, while other storage failures may be transient
+func storageReadError(message string, err error) error {
+	var nErr *domain.NotFoundError
+	if errors.As(err, &nErr) {
+		return domain.NewNotFoundError(domain.ErrNotFound)
+	}
+	var cErr *domain.ConflictError
+	if errors.As(err, &cErr) {
+		return fmt.Errorf("%s: %w", message, err)
+	}
+	return domain.NewRetryableError(fmt.Errorf("%s: %w", message, err))
+}
 
-	return tempFile, hash, nil
+// closeWorkspace removes the workspace of the job, logging the most space it used
+func (uc *videoUseCase) closeWorkspace(ctx context.Context) {
+	log := uc.logger.WithContext(ctx)
+	peak, err := uc.fileManager.CloseWorkspace(ctx)
+	if err != nil {
+		log.Warn("Failed to remove workspace", "error", err)
+		return
+	}
+	log.Info("Workspace removed", "peak_bytes", peak)
 }
 
-// uploadResult uploads the processed video using hash as filename
-func (uc *videoUseCase) uploadResult(ctx context.Context, zipPath, videoHash string) (string, error) {
+// probeVideo returns the metadata of a validated video for the job report; a failed probe is only a warning
+func (uc *videoUseCase) probeVideo(ctx context.Context, videoPath string) *dto.ProbeOutput {
+	metadata, err := uc.videoProcessor.ProbeVideo(ctx, videoPath)
+	if err != nil {
+		uc.logger.WithContext(ctx).Warn("Failed to probe video", "error", err)
+		jobReportFromContext(ctx).warn("failed to probe video: %v", err)
+		return nil
+	}
+	return newProbeOutput(metadata)
+}
+
+// uploadResult uploads the processed video using hash as filename, returning the key, the uploaded size and whether
+// this run created the object rather than replaced the archive of a previous run
+func (uc *videoUseCase) uploadResult(ctx context.Context, zipPath, videoHash string) (string, int64, bool, error) {
 	log := uc.logger.WithContext(ctx).With("zip_path", zipPath, "video_hash", videoHash)
 	log.Info("Starting upload of processed video")
 
 	reader, err := uc.fileManager.ReadFile(ctx, zipPath)
 	if err != nil {
 		log.Error("Failed to read zip file", "error", err)
-		return "", fmt.Errorf("failed to read zip file: %w", err)
+		return "", 0, false, fmt.Errorf("failed to read zip file: %w", err)
 	}
 	defer func() {
 		if cerr := reader.Close(); cerr != nil {
@@ -177,7 +396,7 @@ func (uc *videoUseCase) uploadResult(ctx context.Context, zipPath, videoHash str
 	size, err := uc.fileManager.GetFileSize(ctx, zipPath)
 	if err != nil {
 		log.Error("Failed to get file size", "error", err)
-		return "", fmt.Errorf("failed to get file size: %w", err)
+		return "", 0, false, fmt.Errorf("failed to get file size: %w", err)
 	}
 	log.Debug("File size obtained", "size", size)
 
@@ -185,14 +404,29 @@ func (uc *videoUseCase) uploadResult(ctx context.Context, zipPath, videoHash str
 	outputKey := uc.generateOutputKeyFromHash(videoHash)
 	log.Debug("Generated output key from hash", "output_key", outputKey)
 
+	created := uc.outputMissing(ctx, outputKey)
 	_, err = uc.videoGateway.Upload(ctx, outputKey, reader, "application/zip", size)
 	if err != nil {
 		log.Error("Failed to upload to storage", "error", err)
-		return "", fmt.Errorf("failed to upload to storage: %w", err)
+		return "", 0, false, domain.NewRetryableError(fmt.Errorf("failed to upload to storage: %w", err))
 	}
-	log.Info("Upload completed successfully", "output_key", outputKey)
+	log.Info("Upload completed successfully", "output_key", outputKey, "created", created)
 
-	return outputKey, nil
+	return outputKey, size, created, nil
+}
+
+// outputMissing reports whether nothing is stored under outputKey yet. When it cannot be told, the key counts as
+// taken, so an archive a previous run announced is never removed by mistake.
+func (uc *videoUseCase) outputMissing(ctx context.Context, outputKey string) bool {
+	_, err := uc.videoGateway.StatOutput(ctx, outputKey)
+	var nErr *domain.NotFoundError
+	if errors.As(err, &nErr) {
+		return true
+	}
+	if err != nil {
+		uc.logger.WithContext(ctx).Warn("Failed to check for a previous result", "output_key", outputKey, "error", err)
+	}
+	return false
 }
 
 // generateOutputKeyFromHash creates output key using video hash to avoid duplicates
@@ -200,31 +434,50 @@ func (uc *videoUseCase) generateOutputKeyFromHash(videoHash string) string {
 	return fmt.Sprintf("processed/%s.zip", videoHash)
 }
 
-// writeFileAndGenerateHash writes content to file while generating SHA-256 hash
-func (uc *videoUseCase) writeFileAndGenerateHash(ctx context.Context, filePath string, reader io.Reader) (string, error) {
-	// Create a tee reader to calculate hash while writing
+// writeFileAndGenerateHash writes content to file while generating SHA-256 hash, returning the hash and the size
+func (uc *videoUseCase) writeFileAndGenerateHash(ctx context.Context, filePath string, reader io.Reader) (string, int64, error) {
+	// Create a tee reader to calculate hash and size while writing
 	hasher := sha256.New()
-	teeReader := io.TeeReader(reader, hasher)
+	var size byteCounter
+	teeReader := io.TeeReader(reader, io.MultiWriter(hasher, &size))
 
 	// Write to file
 	if err := uc.fileManager.WriteToFile(ctx, filePath, teeReader); err != nil {
-		return "", fmt.Errorf("failed to write to file: %w", err)
+		return "", 0, fmt.Errorf("failed to write to file: %w", err)
 	}
 
 	// Generate hash
 	hash := hex.EncodeToString(hasher.Sum(nil))
-	return hash, nil
+	return hash, int64(size), nil
 }
 
-// configureProcessing sets up processing configuration
-func (uc *videoUseCase) configureProcessing(inputConfig *dto.ProcessingConfigInput, log logger.Logger) entity.ProcessingConfig {
+// byteCounter counts the bytes written to it
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// configureProcessing sets up processing configuration, filling in the defaults; it is shared by the use cases
+// that extract frames
+func configureProcessing(inputConfig *dto.ProcessingConfigInput, log logger.Logger) entity.ProcessingConfig {
 	var cfg entity.ProcessingConfig
 	if inputConfig == nil {
 		cfg = entity.ProcessingConfig{FrameRate: 1.0, OutputFormat: "jpg"}
 		log.Info("Using default configuration")
 	} else {
-		cfg = entity.ProcessingConfig{FrameRate: inputConfig.FrameRate, OutputFormat: inputConfig.OutputFormat}
-		log.Info("Using custom configuration", "frame_rate", cfg.FrameRate, "output_format", cfg.OutputFormat)
+		cfg = entity.ProcessingConfig{
+			FrameRate:    inputConfig.FrameRate,
+			OutputFormat: inputConfig.OutputFormat,
+			Width:        inputConfig.Width,
+			Height:       inputConfig.Height,
+			JPEGQuality:  inputConfig.JPEGQuality,
+			StartTime:    inputConfig.StartTime,
+			EndTime:      inputConfig.EndTime,
+		}
+		log.Info("Using custom configuration", "frame_rate", cfg.FrameRate, "output_format", cfg.OutputFormat,
+			"width", cfg.Width, "height", cfg.Height, "start_time", cfg.StartTime, "end_time", cfg.EndTime)
 	}
 
 	if cfg.FrameRate <= 0 {
@@ -238,46 +491,63 @@ func (uc *videoUseCase) configureProcessing(inputConfig *dto.ProcessingConfigInp
 }
 
 // extractFrames processes video and extracts frames
-func (uc *videoUseCase) extractFrames(ctx context.Context, videoPath string, cfg entity.ProcessingConfig) (int, string, error) {
+func (uc *videoUseCase) extractFrames(ctx context.Context, videoPath string, cfg entity.ProcessingConfig) (*entity.FrameExtraction, error) {
 	log := uc.logger.WithContext(ctx)
 	log.Info("Starting frame extraction")
 
-	frameCount, zipPath, err := uc.videoProcessor.ProcessVideo(ctx, videoPath, cfg.FrameRate, cfg.OutputFormat)
+	extraction, err := uc.videoProcessor.ProcessVideo(ctx, videoPath, cfg)
 	if err != nil {
 		log.Error("Failed to process video", "error", err)
-		return 0, "", fmt.Errorf("failed to process video: %w", err)
+		return nil, fmt.Errorf("failed to process video: %w", err)
 	}
 
-	log.Info("Frame extraction completed", "frame_count", frameCount, "zip_path", zipPath)
-	return frameCount, zipPath, nil
+	log.Info("Frame extraction completed", "frame_count", extraction.FrameCount, "zip_path", extraction.ArchivePath)
+	return extraction, nil
 }
 
-// createErrorResponse creates standardized error response
-func (uc *videoUseCase) createErrorResponse(message string, err error) (*dto.ProcessVideoOutput, error) {
-	var nErr *domain.NotFoundError
-	var vErr *domain.ValidationError
-
-	if errors.As(err, &nErr) {
-		return &dto.ProcessVideoOutput{
-			Success: false,
-			Message: "Processing failed",
-			Error:   fmt.Sprintf("%s: %v", message, err),
-		}, nErr
+// createErrorResponse creates standardized error response. Domain errors keep their class and unclassified ones
+// become internal; the stage recorded in err, if any, wins over the given one.
+func (uc *videoUseCase) createErrorResponse(stage domain.Stage, message string, err error) (*dto.ProcessVideoOutput, error) {
+	if recorded := domain.StageOf(err); recorded != "" {
+		stage = recorded
 	}
-
-	if errors.As(err, &vErr) {
-		return &dto.ProcessVideoOutput{
-			Success: false,
-			Message: "Processing failed",
-			Error:   fmt.Sprintf("%s: %v", message, err),
-		}, vErr
+	var dErr domain.Error
+	if !errors.As(err, &dErr) {
+		dErr = domain.NewInternalError(err)
 	}
 
+	detail := err.Error()
+	if message != "" {
+		detail = fmt.Sprintf("%s: %v", message, err)
+	}
 	return &dto.ProcessVideoOutput{
-		Success: false,
-		Message: "Processing failed",
-		Error:   fmt.Sprintf("%s: %v", message, err),
-	}, domain.NewInternalError(err)
+		Success:   false,
+		Message:   "Processing failed",
+		Error:     detail,
+		ErrorCode: string(dErr.Code()),
+		Stage:     string(stage),
+		Retryable: domain.IsRetryable(dErr),
+	}, domain.WithStage(stage, dErr)
+}
+
+// publishFailure publishes a FAILED status describing the error. Its outcome is logged, never changing the result.
+func (uc *videoUseCase) publishFailure(ctx context.Context, input dto.ProcessVideoInput, output *dto.ProcessVideoOutput) {
+	err := uc.publishStatus(ctx, dto.VideoStatusUpdate{
+		VideoId: input.VideoId,
+		UserId:  input.UserId,
+		Hash:    output.Hash,
+		Status:  "FAILED",
+		Failure: &dto.FailureOutput{
+			Code:      output.ErrorCode,
+			Stage:     output.Stage,
+			Retryable: output.Retryable,
+			Message:   output.Error,
+		},
+		ReportKey: output.ReportKey,
+	})
+	if err != nil {
+		uc.logger.WithContext(ctx).Warn("Failed to publish failure status", "error", err)
+	}
 }
 
 // cleanupFile safely deletes temporary files
@@ -287,23 +557,95 @@ func (uc *videoUseCase) cleanupFile(ctx context.Context, filePath, fileType stri
 	}
 }
 
-// deleteOriginalVideo removes the original video from storage
-func (uc *videoUseCase) deleteOriginalVideo(ctx context.Context, videoKey string) {
-	log := uc.logger.WithContext(ctx)
-	log.Info("Deleting original video from storage")
-	if err := uc.videoGateway.Delete(ctx, videoKey); err != nil {
-		log.Warn("Failed to delete original video", "error", err, "video_key", videoKey)
-	} else {
-		log.Info("Original video deleted successfully")
+// applyRetention deletes, keeps, moves or tags the original video according to the policy.
+// Every action is conditional on the downloaded revision, so a file re-uploaded under the same key is left untouched.
+// Failures are reported in the returned outcome instead of failing the job, as the result is already uploaded.
+func (uc *videoUseCase) applyRetention(ctx context.Context, videoKey string, version entity.ObjectVersion, policy entity.RetentionPolicy) *dto.RetentionOutput {
+	log := uc.logger.WithContext(ctx).With("retention_policy", policy.String())
+	out := &dto.RetentionOutput{Action: policy.String(), Status: dto.RetentionStatusApplied}
+
+	// fail records a failed step, distinguishing a changed source from other errors
+	fail := func(step string, err error) *dto.RetentionOutput {
+		var cErr *domain.ConflictError
+		if errors.As(err, &cErr) {
+			log.Warn("Original video changed during processing, leaving it untouched", "step", step, "etag", version.ETag, "version_id", version.VersionId)
+			out.Status = dto.RetentionStatusSkipped
+			out.Detail = "source_changed: " + step + " skipped"
+			return out
+		}
+		log.Warn("Failed to apply retention policy", "step", step, "error", err)
+		out.Status = dto.RetentionStatusFailed
+		out.Detail = fmt.Sprintf("failed to %s original video: %v", step, err)
+		return out
 	}
+
+	switch policy.Action {
+	case entity.RetentionKeep:
+		log.Info("Keeping original video in storage")
+		out.Detail = "original video kept"
+		return out
+
+	case entity.RetentionTag:
+		log.Info("Tagging original video for lifecycle expiry")
+		if err := uc.videoGateway.Tag(ctx, videoKey, version, map[string]string{policy.TagKey: policy.TagValue}); err != nil {
+			return fail("tag", err)
+		}
+		out.Detail = fmt.Sprintf("tagged %s=%s", policy.TagKey, policy.TagValue)
+
+	case entity.RetentionMove:
+		destinationKey := policy.DestinationKey(videoKey)
+		log.Info("Moving original video to archive", "destination_bucket", policy.Bucket, "destination_key", destinationKey)
+		if err := uc.videoGateway.Copy(ctx, videoKey, version, policy.Bucket, destinationKey); err != nil {
+			return fail("copy", err)
+		}
+		if err := uc.videoGateway.Delete(ctx, videoKey, version); err != nil {
+			fail("delete", err)
+			out.Detail = fmt.Sprintf("copied to %s; %s", destinationKey, out.Detail)
+			return out
+		}
+		out.Detail = "moved to " + destinationKey
+
+	default:
+		log.Info("Deleting original video from storage")
+		if err := uc.videoGateway.Delete(ctx, videoKey, version); err != nil {
+			return fail("delete", err)
+		}
+		out.Detail = "original video deleted"
+	}
+
+	log.Info("Retention policy applied successfully", "detail", out.Detail)
+	return out
 }
 
-func (uc *videoUseCase) updateVideoStatus(ctx context.Context, videoId string, userId string, videoHash, status string) {
-	log := uc.logger.WithContext(ctx)
-	log.Info("Updating video status", "video_key", videoId, "status", status)
-	if err := uc.videoGateway.UpdateStatus(ctx, videoId, userId, videoHash, status); err != nil {
-		log.Warn("Failed to update video status", "error", err, "video_key", videoId, "status", status)
-	} else {
-		log.Info("Video status updated successfully", "video_key", videoId, "status", status)
+// notifyCallback sends the final result to the caller's endpoint. Its outcome is reported, never failing the job.
+func (uc *videoUseCase) notifyCallback(ctx context.Context, input dto.ProcessVideoInput, output *dto.ProcessVideoOutput) *dto.CallbackOutput {
+	log := uc.logger.WithContext(ctx).With("callback_url", input.Callback.URL)
+	log.Info("Sending result callback")
+
+	delivery, err := uc.videoGateway.NotifyCallback(ctx, dto.CallbackNotification{
+		Callback: *input.Callback,
+		VideoKey: input.VideoKey,
+		VideoId:  input.VideoId,
+		UserId:   input.UserId,
+		Result:   output,
+	})
+	out := &dto.CallbackOutput{
+		URL:        input.Callback.URL,
+		Status:     dto.CallbackStatusDelivered,
+		Attempts:   delivery.Attempts,
+		StatusCode: delivery.StatusCode,
 	}
+	if err != nil {
+		out.Status = dto.CallbackStatusFailed
+		var invErr *domain.InvalidInputError
+		if errors.As(err, &invErr) {
+			out.Status = dto.CallbackStatusRejected
+		}
+		out.Error = err.Error()
+		log.Warn("Result callback not delivered", "error", err, "callback_status", out.Status, "attempts", out.Attempts, "status_code", out.StatusCode)
+		return out
+	}
+
+	log.Info("Result callback delivered", "attempts", out.Attempts, "status_code", out.StatusCode)
+	return out
 }
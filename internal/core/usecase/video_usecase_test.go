@@ -12,11 +12,15 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
 	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
 )
 
+// testVersion is the storage revision returned by successful downloads in these tests
+var testVersion = entity.ObjectVersion{ETag: `"etag-1"`, VersionId: "v1"}
+
 func TestVideoUseCase(t *testing.T) {
 
 	t.Run("ProcessVideo", func(t *testing.T) {
@@ -29,7 +33,7 @@ func TestVideoUseCase(t *testing.T) {
 			fm := pmocks.NewMockFileManager(ctrl)
 			log := logger.NewSlogLogger()
 
-			uc := NewVideoUseCase(vg, vp, fm, log)
+			uc := NewVideoUseCase(vg, vp, fm, nil, log)
 
 			videoKey := "folder/foo.mp4"
 			localPath := "/tmp/video123.mp4"
@@ -37,30 +41,35 @@ func TestVideoUseCase(t *testing.T) {
 			testVideoData := "test video data"
 			// Hash will be calculated dynamically from the actual content
 
+			expectWorkspace(fm, vg)
 			// Download to local and generate hash
 			fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(localPath, nil)
-			vg.EXPECT().Download(gomock.Any(), videoKey).Return(io.NopCloser(strings.NewReader(testVideoData)), nil)
+			vg.EXPECT().Download(gomock.Any(), videoKey, entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader(testVideoData)), testVersion, nil)
 			fm.EXPECT().WriteToFile(gomock.Any(), localPath, gomock.Any()).Return(nil)
 
 			// Validate and process (defaults: 1.0, png)
 			vp.EXPECT().ValidateVideo(gomock.Any(), localPath).Return(nil)
-			vp.EXPECT().ProcessVideo(gomock.Any(), localPath, 1.0, "jpg").Return(1, zipPath, nil)
+			vp.EXPECT().ProbeVideo(gomock.Any(), localPath).Return(&entity.VideoMetadata{FormatName: "mov,mp4,m4a,3gp,3g2,mj2"}, nil)
+			vp.EXPECT().ProcessVideo(gomock.Any(), localPath, entity.ProcessingConfig{FrameRate: 1.0, OutputFormat: "jpg"}).Return(&entity.FrameExtraction{FrameCount: 1, ArchivePath: zipPath}, nil)
 
 			// Upload result using hash - mock returns any key that is passed
 			fm.EXPECT().ReadFile(gomock.Any(), zipPath).Return(io.NopCloser(bytes.NewBufferString("zipdata")), nil)
 			fm.EXPECT().GetFileSize(gomock.Any(), zipPath).Return(int64(7), nil)
+			expectNewOutput(vg)
 			vg.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any(), "application/zip", int64(7)).DoAndReturn(
 				func(ctx context.Context, key string, reader io.Reader, contentType string, size int64) (string, error) {
 					return key, nil // returns the same key that was passed
 				})
+			vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(7), nil)
 
 			// Delete original video and temp files
-			vg.EXPECT().Delete(gomock.Any(), videoKey).Return(nil)
+			vg.EXPECT().Delete(gomock.Any(), videoKey, testVersion).Return(nil)
 			fm.EXPECT().DeleteFile(gomock.Any(), localPath).Return(nil)
 			fm.EXPECT().DeleteFile(gomock.Any(), zipPath).Return(nil)
 
 			// Update video status
-			vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "FINISHED").Return(nil)
+			vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			vg.EXPECT().UpdateStatus(gomock.Any(), statusIs("FINISHED")).Return(nil)
 
 			out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: videoKey})
 			require.NoError(t, err)
@@ -80,23 +89,30 @@ func TestVideoUseCase(t *testing.T) {
 			fm := pmocks.NewMockFileManager(ctrl)
 			log := logger.NewSlogLogger()
 
-			uc := NewVideoUseCase(vg, vp, fm, log)
+			uc := NewVideoUseCase(vg, vp, fm, nil, log)
 
 			videoKey := "bad.mp4"
 			localPath := "/tmp/video_bad.mp4"
 
+			expectWorkspace(fm, vg)
 			fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(localPath, nil)
-			vg.EXPECT().Download(gomock.Any(), videoKey).Return(io.NopCloser(strings.NewReader("data")), nil)
+			vg.EXPECT().Download(gomock.Any(), videoKey, entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("data")), testVersion, nil)
 			fm.EXPECT().WriteToFile(gomock.Any(), localPath, gomock.Any()).Return(nil)
 			vp.EXPECT().ValidateVideo(gomock.Any(), localPath).Return(errors.New("boom"))
 			// File cleanup is handled internally by downloadAndValidateVideo on error
 			fm.EXPECT().DeleteFile(gomock.Any(), localPath).Return(nil)
 
+			vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeValidation, domain.StageValidate)).Return(nil)
+
 			out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: videoKey})
 			require.Error(t, err)
 			require.False(t, out.Success)
 			var vErr *domain.ValidationError
 			require.ErrorAs(t, err, &vErr)
+			// the underlying ffprobe error stays reachable through the chain
+			require.EqualError(t, errors.Unwrap(vErr), "boom")
+			require.False(t, out.Retryable)
 		})
 
 		// ensure download errors are wrapped as InternalError
@@ -109,19 +125,24 @@ func TestVideoUseCase(t *testing.T) {
 			fm := pmocks.NewMockFileManager(ctrl)
 			log := logger.NewSlogLogger()
 
-			uc := NewVideoUseCase(vg, vp, fm, log)
+			uc := NewVideoUseCase(vg, vp, fm, nil, log)
 
 			videoKey := "folder/foo.mp4"
 			localPath := "/tmp/video123.mp4"
 
+			expectWorkspace(fm, vg)
 			fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(localPath, nil)
-			vg.EXPECT().Download(gomock.Any(), videoKey).Return(nil, errors.New("download failed"))
+			vg.EXPECT().Download(gomock.Any(), videoKey, entity.ObjectVersion{}).Return(nil, entity.ObjectVersion{}, errors.New("download failed"))
 			// Cleanup temp file on download error
 			fm.EXPECT().DeleteFile(gomock.Any(), localPath).Return(nil)
 
+			vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInternal, domain.StageDownload)).Return(nil)
+
 			_, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: videoKey})
 			var iErr *domain.InternalError
 			require.ErrorAs(t, err, &iErr)
+			require.True(t, iErr.Retryable)
 		})
 
 		// zero frames should produce InvalidInputError and cleanup temp files
@@ -134,22 +155,27 @@ func TestVideoUseCase(t *testing.T) {
 			fm := pmocks.NewMockFileManager(ctrl)
 			log := logger.NewSlogLogger()
 
-			uc := NewVideoUseCase(vg, vp, fm, log)
+			uc := NewVideoUseCase(vg, vp, fm, nil, log)
 
 			videoKey := "folder/empty.mp4"
 			localPath := "/tmp/empty.mp4"
 			zipPath := "/tmp/frames0.zip"
 
+			expectWorkspace(fm, vg)
 			fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(localPath, nil)
-			vg.EXPECT().Download(gomock.Any(), videoKey).Return(io.NopCloser(strings.NewReader("data")), nil)
+			vg.EXPECT().Download(gomock.Any(), videoKey, entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("data")), testVersion, nil)
 			fm.EXPECT().WriteToFile(gomock.Any(), localPath, gomock.Any()).Return(nil)
 			vp.EXPECT().ValidateVideo(gomock.Any(), localPath).Return(nil)
-			vp.EXPECT().ProcessVideo(gomock.Any(), localPath, 1.0, "jpg").Return(0, zipPath, nil)
+			vp.EXPECT().ProbeVideo(gomock.Any(), localPath).Return(&entity.VideoMetadata{FormatName: "mov,mp4,m4a,3gp,3g2,mj2"}, nil)
+			vp.EXPECT().ProcessVideo(gomock.Any(), localPath, entity.ProcessingConfig{FrameRate: 1.0, OutputFormat: "jpg"}).Return(&entity.FrameExtraction{FrameCount: 0, ArchivePath: zipPath}, nil)
 
 			// defers should cleanup these files when error occurs
 			fm.EXPECT().DeleteFile(gomock.Any(), localPath).Return(nil)
 			fm.EXPECT().DeleteFile(gomock.Any(), zipPath).Return(nil)
 
+			vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInvalidInput, domain.StageExtract)).Return(nil)
+
 			_, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: videoKey})
 			var invErr *domain.InvalidInputError
 			require.ErrorAs(t, err, &invErr)
@@ -164,21 +190,70 @@ func TestVideoUseCase(t *testing.T) {
 			fm := pmocks.NewMockFileManager(ctrl)
 			log := logger.NewSlogLogger()
 
-			uc := NewVideoUseCase(vg, vp, fm, log)
+			uc := NewVideoUseCase(vg, vp, fm, nil, log)
 
 			videoKey := "missing.mp4"
 			localPath := "/tmp/missing.mp4"
 
+			expectWorkspace(fm, vg)
 			fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(localPath, nil)
-			vg.EXPECT().Download(gomock.Any(), videoKey).Return(nil, domain.NewNotFoundError(domain.ErrNotFound))
+			vg.EXPECT().Download(gomock.Any(), videoKey, entity.ObjectVersion{}).Return(nil, entity.ObjectVersion{}, domain.NewNotFoundError(domain.ErrNotFound))
 			// Cleanup temp file on download error
 			fm.EXPECT().DeleteFile(gomock.Any(), localPath).Return(nil)
 
+			vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeNotFound, domain.StageDownload)).Return(nil)
+
 			out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: videoKey})
 			var nf *domain.NotFoundError
 			require.ErrorAs(t, err, &nf)
 			require.False(t, out.Success)
 		})
+
+		t.Run("source_etag_pins_download", func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			vg := pmocks.NewMockVideoGateway(ctrl)
+			vp := pmocks.NewMockVideoProcessor(ctrl)
+			fm := pmocks.NewMockFileManager(ctrl)
+			uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+
+			expectWorkspace(fm, vg)
+			fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return("/tmp/v.mp4", nil)
+			// The object was replaced after the event announced it
+			vg.EXPECT().Download(gomock.Any(), "v.mp4", entity.ObjectVersion{ETag: `"e1"`}).
+				Return(nil, entity.ObjectVersion{}, domain.NewConflictError(domain.ErrSourceChanged))
+			fm.EXPECT().DeleteFile(gomock.Any(), "/tmp/v.mp4").Return(nil)
+
+			vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeConflict, domain.StageDownload)).Return(nil)
+
+			out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "v.mp4", SourceETag: `"e1"`})
+			require.ErrorContains(t, err, domain.ErrSourceChanged)
+			require.False(t, out.Success)
+		})
+	})
+
+	t.Run("ProcessVideo_reuses_the_stat_of_the_batch", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+
+		// No Stat: the size comes from the object handed over in the context
+		fm.EXPECT().OpenWorkspace(gomock.Any()).DoAndReturn(func(ctx context.Context) (context.Context, error) { return ctx, nil })
+		fm.EXPECT().EnsureSpace(gomock.Any(), int64(20*tempSpaceFactor)).Return(nil)
+		fm.EXPECT().CloseWorkspace(gomock.Any()).Return(int64(0), nil)
+		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return("/tmp/v.mp4", nil)
+		vg.EXPECT().Download(gomock.Any(), "v.mp4", entity.ObjectVersion{}).
+			Return(nil, entity.ObjectVersion{}, domain.NewNotFoundError(domain.ErrNotFound))
+		fm.EXPECT().DeleteFile(gomock.Any(), "/tmp/v.mp4").Return(nil)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeNotFound, domain.StageDownload)).Return(nil)
+
+		ctx := withSourceObject(context.Background(), entity.StorageObject{Key: "v.mp4", Size: 20})
+		_, err := uc.ProcessVideo(ctx, dto.ProcessVideoInput{VideoKey: "v.mp4"})
+		require.Error(t, err)
 	})
 
 	t.Run("ProcessVideo_custom_config_sanitization_frameRate_and_format_lowercase", func(t *testing.T) {
@@ -190,31 +265,36 @@ func TestVideoUseCase(t *testing.T) {
 		fm := pmocks.NewMockFileManager(ctrl)
 		log := logger.NewSlogLogger()
 
-		uc := NewVideoUseCase(vg, vp, fm, log)
+		uc := NewVideoUseCase(vg, vp, fm, nil, log)
 
 		videoKey := "vid.mp4"
 		localPath := "/tmp/vid.mp4"
 		zipPath := "/tmp/zip1.zip"
 		testVideoData := "custom test video data"
 
+		expectWorkspace(fm, vg)
 		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(localPath, nil)
-		vg.EXPECT().Download(gomock.Any(), videoKey).Return(io.NopCloser(strings.NewReader(testVideoData)), nil)
+		vg.EXPECT().Download(gomock.Any(), videoKey, entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader(testVideoData)), testVersion, nil)
 		fm.EXPECT().WriteToFile(gomock.Any(), localPath, gomock.Any()).Return(nil)
 		vp.EXPECT().ValidateVideo(gomock.Any(), localPath).Return(nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), localPath).Return(&entity.VideoMetadata{FormatName: "mov,mp4,m4a,3gp,3g2,mj2"}, nil)
 		// input has frame_rate=0 (sanitize to 1.0) and output_format="JPG" (lowercase to "jpg")
-		vp.EXPECT().ProcessVideo(gomock.Any(), localPath, 1.0, "jpg").Return(1, zipPath, nil)
+		vp.EXPECT().ProcessVideo(gomock.Any(), localPath, entity.ProcessingConfig{FrameRate: 1.0, OutputFormat: "jpg"}).Return(&entity.FrameExtraction{FrameCount: 1, ArchivePath: zipPath}, nil)
 		fm.EXPECT().ReadFile(gomock.Any(), zipPath).Return(io.NopCloser(bytes.NewBufferString("zip")), nil)
 		fm.EXPECT().GetFileSize(gomock.Any(), zipPath).Return(int64(3), nil)
+		expectNewOutput(vg)
 		vg.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any(), "application/zip", int64(3)).DoAndReturn(
 			func(ctx context.Context, key string, reader io.Reader, contentType string, size int64) (string, error) {
 				return key, nil // returns the same key that was passed
 			})
-		vg.EXPECT().Delete(gomock.Any(), videoKey).Return(nil)
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+		vg.EXPECT().Delete(gomock.Any(), videoKey, testVersion).Return(nil)
 		fm.EXPECT().DeleteFile(gomock.Any(), localPath).Return(nil)
 		fm.EXPECT().DeleteFile(gomock.Any(), zipPath).Return(nil)
 
 		// Update video status
-		vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "FINISHED").Return(nil)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), statusIs("FINISHED")).Return(nil)
 
 		in := dto.ProcessVideoInput{VideoKey: videoKey, Configuration: &dto.ProcessingConfigInput{FrameRate: 0, OutputFormat: "JPG"}}
 		out, err := uc.ProcessVideo(context.Background(), in)
@@ -235,16 +315,21 @@ func TestVideoUseCase(t *testing.T) {
 		vp := pmocks.NewMockVideoProcessor(ctrl)
 		fm := pmocks.NewMockFileManager(ctrl)
 		log := logger.NewSlogLogger()
-		uc := NewVideoUseCase(vg, vp, fm, log)
+		uc := NewVideoUseCase(vg, vp, fm, nil, log)
 
 		local := "/tmp/unsupported.mp4"
+		expectWorkspace(fm, vg)
 		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(local, nil)
-		vg.EXPECT().Download(gomock.Any(), "vid.mp4").Return(io.NopCloser(strings.NewReader("x")), nil)
+		vg.EXPECT().Download(gomock.Any(), "vid.mp4", entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("x")), testVersion, nil)
 		fm.EXPECT().WriteToFile(gomock.Any(), local, gomock.Any()).Return(nil)
 		vp.EXPECT().ValidateVideo(gomock.Any(), local).Return(nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), local).Return(&entity.VideoMetadata{FormatName: "mov,mp4,m4a,3gp,3g2,mj2"}, nil)
 		// cleanup of temp local file due to fail-fast
 		fm.EXPECT().DeleteFile(gomock.Any(), local).Return(nil)
 
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInvalidInput, domain.StageInput)).Return(nil)
+
 		in := dto.ProcessVideoInput{
 			VideoKey:      "vid.mp4",
 			Configuration: &dto.ProcessingConfigInput{FrameRate: 1.0, OutputFormat: "webp"},
@@ -263,20 +348,25 @@ func TestVideoUseCase(t *testing.T) {
 		vp := pmocks.NewMockVideoProcessor(ctrl)
 		fm := pmocks.NewMockFileManager(ctrl)
 		log := logger.NewSlogLogger()
-		uc := NewVideoUseCase(vg, vp, fm, log)
+		uc := NewVideoUseCase(vg, vp, fm, nil, log)
 
 		local := "/tmp/video.mp4"
 		zip := "/tmp/frames.zip"
+		expectWorkspace(fm, vg)
 		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(local, nil)
-		vg.EXPECT().Download(gomock.Any(), "foo").Return(io.NopCloser(strings.NewReader("x")), nil)
+		vg.EXPECT().Download(gomock.Any(), "foo", entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("x")), testVersion, nil)
 		fm.EXPECT().WriteToFile(gomock.Any(), local, gomock.Any()).Return(nil)
 		vp.EXPECT().ValidateVideo(gomock.Any(), local).Return(nil)
-		vp.EXPECT().ProcessVideo(gomock.Any(), local, 1.0, "jpg").Return(1, zip, nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), local).Return(&entity.VideoMetadata{FormatName: "mov,mp4,m4a,3gp,3g2,mj2"}, nil)
+		vp.EXPECT().ProcessVideo(gomock.Any(), local, entity.ProcessingConfig{FrameRate: 1.0, OutputFormat: "jpg"}).Return(&entity.FrameExtraction{FrameCount: 1, ArchivePath: zip}, nil)
 
 		fm.EXPECT().ReadFile(gomock.Any(), zip).Return(nil, errors.New("read error"))
 		fm.EXPECT().DeleteFile(gomock.Any(), local).Return(nil)
 		fm.EXPECT().DeleteFile(gomock.Any(), zip).Return(nil)
 
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInternal, domain.StageUpload)).Return(nil)
+
 		_, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "foo"})
 		require.Error(t, err)
 	})
@@ -289,25 +379,37 @@ func TestVideoUseCase(t *testing.T) {
 		vp := pmocks.NewMockVideoProcessor(ctrl)
 		fm := pmocks.NewMockFileManager(ctrl)
 		log := logger.NewSlogLogger()
-		uc := NewVideoUseCase(vg, vp, fm, log)
+		uc := NewVideoUseCase(vg, vp, fm, nil, log)
 
 		local := "/tmp/video.mp4"
 		zip := "/tmp/frames.zip"
+		expectWorkspace(fm, vg)
 		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(local, nil)
-		vg.EXPECT().Download(gomock.Any(), "foo").Return(io.NopCloser(strings.NewReader("x")), nil)
+		vg.EXPECT().Download(gomock.Any(), "foo", entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("x")), testVersion, nil)
 		fm.EXPECT().WriteToFile(gomock.Any(), local, gomock.Any()).Return(nil)
 		vp.EXPECT().ValidateVideo(gomock.Any(), local).Return(nil)
-		vp.EXPECT().ProcessVideo(gomock.Any(), local, 1.0, "jpg").Return(1, zip, nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), local).Return(&entity.VideoMetadata{FormatName: "mov,mp4,m4a,3gp,3g2,mj2"}, nil)
+		vp.EXPECT().ProcessVideo(gomock.Any(), local, entity.ProcessingConfig{FrameRate: 1.0, OutputFormat: "jpg"}).Return(&entity.FrameExtraction{FrameCount: 1, ArchivePath: zip}, nil)
 
 		rc := io.NopCloser(strings.NewReader("zip"))
 		fm.EXPECT().ReadFile(gomock.Any(), zip).Return(rc, nil)
 		fm.EXPECT().GetFileSize(gomock.Any(), zip).Return(int64(3), nil)
+		expectNewOutput(vg)
 		vg.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any(), "application/zip", int64(3)).Return("", errors.New("upload error"))
 		fm.EXPECT().DeleteFile(gomock.Any(), local).Return(nil)
 		fm.EXPECT().DeleteFile(gomock.Any(), zip).Return(nil)
 
-		_, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "foo"})
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInternal, domain.StageUpload)).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "foo"})
 		require.Error(t, err)
+		require.True(t, domain.IsRetryable(err))
+		require.Equal(t, domain.StageUpload, domain.StageOf(err))
+		require.Equal(t, domain.ErrorCodeInternal, domain.CodeOf(err))
+		require.Equal(t, "INTERNAL_ERROR", out.ErrorCode)
+		require.Equal(t, "upload", out.Stage)
+		require.True(t, out.Retryable)
 	})
 
 	// JPEG normalization: "jpeg" -> "jpg"
@@ -318,27 +420,32 @@ func TestVideoUseCase(t *testing.T) {
 		vp := pmocks.NewMockVideoProcessor(ctrl)
 		fm := pmocks.NewMockFileManager(ctrl)
 		log := logger.NewSlogLogger()
-		uc := NewVideoUseCase(vg, vp, fm, log)
+		uc := NewVideoUseCase(vg, vp, fm, nil, log)
 
 		local := "/tmp/video.mp4"
 		zip := "/tmp/frames.zip"
+		expectWorkspace(fm, vg)
 		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(local, nil)
-		vg.EXPECT().Download(gomock.Any(), "vid.mp4").Return(io.NopCloser(strings.NewReader("x")), nil)
+		vg.EXPECT().Download(gomock.Any(), "vid.mp4", entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("x")), testVersion, nil)
 		fm.EXPECT().WriteToFile(gomock.Any(), local, gomock.Any()).Return(nil)
 		vp.EXPECT().ValidateVideo(gomock.Any(), local).Return(nil)
-		vp.EXPECT().ProcessVideo(gomock.Any(), local, 1.0, "jpg").Return(1, zip, nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), local).Return(&entity.VideoMetadata{FormatName: "mov,mp4,m4a,3gp,3g2,mj2"}, nil)
+		vp.EXPECT().ProcessVideo(gomock.Any(), local, entity.ProcessingConfig{FrameRate: 1.0, OutputFormat: "jpg"}).Return(&entity.FrameExtraction{FrameCount: 1, ArchivePath: zip}, nil)
 		fm.EXPECT().ReadFile(gomock.Any(), zip).Return(io.NopCloser(strings.NewReader("zip")), nil)
 		fm.EXPECT().GetFileSize(gomock.Any(), zip).Return(int64(3), nil)
+		expectNewOutput(vg)
 		vg.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any(), "application/zip", int64(3)).DoAndReturn(
 			func(ctx context.Context, key string, r io.Reader, ct string, n int64) (string, error) {
 				return key, nil
 			})
-		vg.EXPECT().Delete(gomock.Any(), "vid.mp4").Return(nil)
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+		vg.EXPECT().Delete(gomock.Any(), "vid.mp4", testVersion).Return(nil)
 		fm.EXPECT().DeleteFile(gomock.Any(), local).Return(nil)
 		fm.EXPECT().DeleteFile(gomock.Any(), zip).Return(nil)
 
 		// Update video status
-		vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "FINISHED").Return(nil)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), statusIs("FINISHED")).Return(nil)
 
 		in := dto.ProcessVideoInput{VideoKey: "vid.mp4", Configuration: &dto.ProcessingConfigInput{FrameRate: 1.0, OutputFormat: "jpeg"}}
 		out, err := uc.ProcessVideo(context.Background(), in)
@@ -346,3 +453,595 @@ func TestVideoUseCase(t *testing.T) {
 		require.True(t, out.Success)
 	})
 }
+
+func TestVideoUseCase_Retention(t *testing.T) {
+	// expectProcessing sets up the download, extraction and upload of a successful run
+	expectProcessing := func(vg *pmocks.MockVideoGateway, vp *pmocks.MockVideoProcessor, fm *pmocks.MockFileManager, key string) {
+		local := "/tmp/video.mp4"
+		zip := "/tmp/frames.zip"
+		expectWorkspace(fm, vg)
+		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(local, nil)
+		vg.EXPECT().Download(gomock.Any(), key, entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("x")), testVersion, nil)
+		fm.EXPECT().WriteToFile(gomock.Any(), local, gomock.Any()).Return(nil)
+		vp.EXPECT().ValidateVideo(gomock.Any(), local).Return(nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), local).Return(&entity.VideoMetadata{FormatName: "mov,mp4,m4a,3gp,3g2,mj2"}, nil)
+		vp.EXPECT().ProcessVideo(gomock.Any(), local, entity.ProcessingConfig{FrameRate: 1.0, OutputFormat: "jpg"}).Return(&entity.FrameExtraction{FrameCount: 2, ArchivePath: zip}, nil)
+		fm.EXPECT().ReadFile(gomock.Any(), zip).Return(io.NopCloser(strings.NewReader("zip")), nil)
+		fm.EXPECT().GetFileSize(gomock.Any(), zip).Return(int64(3), nil)
+		expectNewOutput(vg)
+		vg.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any(), "application/zip", int64(3)).DoAndReturn(
+			func(ctx context.Context, key string, r io.Reader, ct string, n int64) (string, error) {
+				return key, nil
+			})
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+		fm.EXPECT().DeleteFile(gomock.Any(), local).Return(nil)
+		fm.EXPECT().DeleteFile(gomock.Any(), zip).Return(nil)
+	}
+
+	// retentionIs matches a FINISHED status update carrying the given retention outcome.
+	// The first FINISHED update announces the action as scheduled; failed or skipped outcomes are published again.
+	retentionIs := func(action, status string) gomock.Matcher {
+		return gomock.Cond(func(u dto.VideoStatusUpdate) bool {
+			return u.Status == "FINISHED" && u.Retention != nil && u.Retention.Action == action && u.Retention.Status == status
+		})
+	}
+
+	t.Run("keep", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+		uc.(*videoUseCase).publishBackoff = 0
+
+		expectProcessing(vg, vp, fm, "vid.mp4")
+		// no Delete, Copy or Tag expected
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), retentionIs("keep", dto.RetentionStatusScheduled)).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4", RetentionPolicy: "keep"})
+		r.NoError(err)
+		r.Equal("keep", out.Retention.Action)
+		r.Equal(dto.RetentionStatusApplied, out.Retention.Status)
+	})
+
+	t.Run("move_to_prefix", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+		uc.(*videoUseCase).publishBackoff = 0
+
+		expectProcessing(vg, vp, fm, "videos/vid.mp4")
+		gomock.InOrder(
+			vg.EXPECT().Copy(gomock.Any(), "videos/vid.mp4", testVersion, "", "archive/videos/vid.mp4").Return(nil),
+			vg.EXPECT().Delete(gomock.Any(), "videos/vid.mp4", testVersion).Return(nil),
+		)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), retentionIs("move:archive/", dto.RetentionStatusScheduled)).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "videos/vid.mp4", RetentionPolicy: "move:archive/"})
+		r.NoError(err)
+		r.Equal("moved to archive/videos/vid.mp4", out.Retention.Detail)
+	})
+
+	t.Run("move_to_bucket_copy_failure_keeps_original", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+		uc.(*videoUseCase).publishBackoff = 0
+
+		expectProcessing(vg, vp, fm, "vid.mp4")
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		gomock.InOrder(
+			vg.EXPECT().UpdateStatus(gomock.Any(), retentionIs("move:s3://archive-bucket/tenant-a/", dto.RetentionStatusScheduled)).Return(nil),
+			vg.EXPECT().Copy(gomock.Any(), "vid.mp4", testVersion, "archive-bucket", "tenant-a/vid.mp4").Return(errors.New("denied")),
+			vg.EXPECT().UpdateStatus(gomock.Any(), retentionIs("move:s3://archive-bucket/tenant-a/", dto.RetentionStatusFailed)).Return(nil),
+		)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4", RetentionPolicy: "move:s3://archive-bucket/tenant-a/"})
+		r.NoError(err)
+		r.True(out.Success)
+		r.Equal(dto.RetentionStatusFailed, out.Retention.Status)
+	})
+
+	t.Run("tag", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+		uc.(*videoUseCase).publishBackoff = 0
+
+		expectProcessing(vg, vp, fm, "vid.mp4")
+		vg.EXPECT().Tag(gomock.Any(), "vid.mp4", testVersion, map[string]string{"expire": "30d"}).Return(nil)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), retentionIs("tag:expire=30d", dto.RetentionStatusScheduled)).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4", RetentionPolicy: "tag:expire=30d"})
+		r.NoError(err)
+		r.Equal("tagged expire=30d", out.Retention.Detail)
+	})
+
+	t.Run("delete_failure_is_reported", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+		uc.(*videoUseCase).publishBackoff = 0
+
+		expectProcessing(vg, vp, fm, "vid.mp4")
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		var eventIds []string
+		var awaited []bool
+		recordEventId := func(_ context.Context, u dto.VideoStatusUpdate) {
+			eventIds = append(eventIds, u.EventId)
+			awaited = append(awaited, u.AwaitDelivery)
+		}
+		vg.EXPECT().UpdateStatus(gomock.Any(), retentionIs("delete", dto.RetentionStatusScheduled)).Do(recordEventId).Return(nil)
+		vg.EXPECT().Delete(gomock.Any(), "vid.mp4", testVersion).Return(errors.New("denied"))
+		// a failed follow-up publish does not fail the job
+		vg.EXPECT().UpdateStatus(gomock.Any(), retentionIs("delete", dto.RetentionStatusFailed)).
+			Do(recordEventId).Return(errors.New("broker down")).Times(statusPublishAttempts)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4"})
+		r.NoError(err)
+		r.Equal(dto.RetentionStatusFailed, out.Retention.Status)
+
+		// retries of an update share its event ID; the follow-up update has its own
+		r.Len(eventIds, 1+statusPublishAttempts)
+		r.NotEmpty(eventIds[0])
+		r.NotEqual(eventIds[0], eventIds[1])
+		for _, id := range eventIds[2:] {
+			r.Equal(eventIds[1], id)
+		}
+		// only the update preceding the retention must be acknowledged by the broker
+		r.True(awaited[0])
+		r.False(awaited[1])
+	})
+
+	t.Run("source_changed_skips_delete", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+		uc.(*videoUseCase).publishBackoff = 0
+
+		expectProcessing(vg, vp, fm, "vid.mp4")
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		vg.EXPECT().UpdateStatus(gomock.Any(), retentionIs("delete", dto.RetentionStatusScheduled)).Return(nil)
+		vg.EXPECT().Delete(gomock.Any(), "vid.mp4", testVersion).Return(domain.NewConflictError(domain.ErrSourceChanged))
+		vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Cond(func(u dto.VideoStatusUpdate) bool {
+			return u.Status == "FINISHED" && u.SourceChanged && u.Retention.Status == dto.RetentionStatusSkipped
+		})).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4"})
+		r.NoError(err)
+		r.True(out.Success)
+		r.True(out.SourceChanged)
+		r.Equal(dto.RetentionStatusSkipped, out.Retention.Status)
+		r.Contains(out.Retention.Detail, "source_changed")
+	})
+
+	t.Run("source_changed_between_copy_and_delete", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+		uc.(*videoUseCase).publishBackoff = 0
+
+		expectProcessing(vg, vp, fm, "vid.mp4")
+		vg.EXPECT().Copy(gomock.Any(), "vid.mp4", testVersion, "", "archive/vid.mp4").Return(nil)
+		vg.EXPECT().Delete(gomock.Any(), "vid.mp4", testVersion).Return(domain.NewConflictError(domain.ErrSourceChanged))
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+		vg.EXPECT().UpdateStatus(gomock.Any(), retentionIs("move:archive/", dto.RetentionStatusScheduled)).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Cond(func(u dto.VideoStatusUpdate) bool { return u.SourceChanged })).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4", RetentionPolicy: "move:archive/"})
+		r.NoError(err)
+		r.True(out.SourceChanged)
+		r.Equal("copied to archive/vid.mp4; source_changed: delete skipped", out.Retention.Detail)
+	})
+
+	t.Run("invalid_policy_fails_fast", func(t *testing.T) {
+		for _, policy := range []string{"archive", "move:", "move:s3://", "tag:novalue", "keep:forever"} {
+			t.Run(policy, func(t *testing.T) {
+				r := require.New(t)
+				ctrl := gomock.NewController(t)
+				vg := pmocks.NewMockVideoGateway(ctrl)
+				vp := pmocks.NewMockVideoProcessor(ctrl)
+				fm := pmocks.NewMockFileManager(ctrl)
+				uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+				uc.(*videoUseCase).publishBackoff = 0
+
+				vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInvalidInput, domain.StageInput)).Return(nil)
+
+				out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4", RetentionPolicy: policy})
+				var inv *domain.InvalidInputError
+				r.ErrorAs(err, &inv)
+				r.False(out.Success)
+			})
+		}
+	})
+}
+
+func TestVideoUseCase_IDFormat(t *testing.T) {
+	// run processes a missing video so that IDs accepted by the format stop at the download
+	run := func(t *testing.T, format, videoId, userId string, accepted bool) error {
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+
+		if accepted {
+			expectWorkspace(fm, vg)
+			fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return("/tmp/v.mp4", nil)
+			vg.EXPECT().Download(gomock.Any(), "vid.mp4", entity.ObjectVersion{}).Return(nil, entity.ObjectVersion{}, domain.NewNotFoundError(domain.ErrNotFound))
+			fm.EXPECT().DeleteFile(gomock.Any(), "/tmp/v.mp4").Return(nil)
+		}
+		// both outcomes fail the job, which uploads its report
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		if accepted {
+			vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeNotFound, domain.StageDownload)).Return(nil)
+		} else {
+			vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInvalidInput, domain.StageInput)).Return(nil)
+		}
+		_, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{
+			VideoKey: "vid.mp4",
+			VideoId:  videoId,
+			UserId:   userId,
+			IdFormat: format,
+		})
+		return err
+	}
+
+	for _, tc := range []struct {
+		format, videoId, userId string
+	}{
+		{"", "", ""},
+		{"any", "video-abc", "user@example.com"},
+		{"numeric", "42", "7"},
+		{"uuid", "0b9e4c6e-1f0a-4d7b-9a52-3f1d2c8e7a10", "0B9E4C6E-1F0A-4D7B-9A52-3F1D2C8E7A10"},
+		{"ULID", "01ARZ3NDEKTSV4RRFFQ69G5FAV", "01arz3ndektsv4rrffq69g5fav"},
+	} {
+		t.Run("accepts/"+tc.format+"/"+tc.videoId, func(t *testing.T) {
+			var nf *domain.NotFoundError
+			require.ErrorAs(t, run(t, tc.format, tc.videoId, tc.userId, true), &nf)
+		})
+	}
+
+	for _, tc := range []struct {
+		format, videoId, userId string
+	}{
+		{"any", "", "7"},
+		{"any", "42", " "},
+		{"numeric", "42a", "7"},
+		{"numeric", "-1", "7"},
+		{"uuid", "42", "0b9e4c6e-1f0a-4d7b-9a52-3f1d2c8e7a10"},
+		{"uuid", "0b9e4c6e1f0a4d7b9a523f1d2c8e7a10", "0b9e4c6e-1f0a-4d7b-9a52-3f1d2c8e7a10"},
+		{"ulid", "01ARZ3NDEKTSV4RRFFQ69G5FAV", "81ARZ3NDEKTSV4RRFFQ69G5FAV"},
+		{"ulid", "01ARZ3NDEKTSV4RRFFQ69G5FAU", "01ARZ3NDEKTSV4RRFFQ69G5FA"},
+		{"snowflake", "42", "7"},
+	} {
+		t.Run("rejects/"+tc.format+"/"+tc.videoId+"/"+tc.userId, func(t *testing.T) {
+			var inv *domain.InvalidInputError
+			require.ErrorAs(t, run(t, tc.format, tc.videoId, tc.userId, false), &inv)
+		})
+	}
+}
+
+func TestVideoUseCase_Callback(t *testing.T) {
+	callback := &dto.CallbackInput{URL: "https://hooks.example.com/video", Secret: "s3cr3t"}
+
+	t.Run("delivered_with_final_result", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, vp, fm, nil, logger.NewSlogLogger())
+
+		local := "/tmp/video.mp4"
+		zip := "/tmp/frames.zip"
+		expectWorkspace(fm, vg)
+		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(local, nil)
+		vg.EXPECT().Download(gomock.Any(), "vid.mp4", entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("x")), testVersion, nil)
+		fm.EXPECT().WriteToFile(gomock.Any(), local, gomock.Any()).Return(nil)
+		vp.EXPECT().ValidateVideo(gomock.Any(), local).Return(nil)
+		vp.EXPECT().ProbeVideo(gomock.Any(), local).Return(&entity.VideoMetadata{FormatName: "mov,mp4,m4a,3gp,3g2,mj2"}, nil)
+		vp.EXPECT().ProcessVideo(gomock.Any(), local, entity.ProcessingConfig{FrameRate: 1.0, OutputFormat: "jpg"}).Return(&entity.FrameExtraction{FrameCount: 2, ArchivePath: zip}, nil)
+		fm.EXPECT().ReadFile(gomock.Any(), zip).Return(io.NopCloser(strings.NewReader("zip")), nil)
+		fm.EXPECT().GetFileSize(gomock.Any(), zip).Return(int64(3), nil)
+		expectNewOutput(vg)
+		vg.EXPECT().Upload(gomock.Any(), gomock.Any(), gomock.Any(), "application/zip", int64(3)).DoAndReturn(
+			func(ctx context.Context, key string, r io.Reader, ct string, n int64) (string, error) {
+				return key, nil
+			})
+		vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(3), nil)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), statusIs("FINISHED")).Return(nil)
+		vg.EXPECT().Delete(gomock.Any(), "vid.mp4", testVersion).Return(nil)
+		fm.EXPECT().DeleteFile(gomock.Any(), local).Return(nil)
+		fm.EXPECT().DeleteFile(gomock.Any(), zip).Return(nil)
+
+		// the callback is sent last, carrying the complete result
+		vg.EXPECT().NotifyCallback(gomock.Any(), gomock.Cond(func(n dto.CallbackNotification) bool {
+			return n.Callback == *callback && n.VideoKey == "vid.mp4" && n.VideoId == "7" &&
+				n.Result.Success && n.Result.FrameCount == 2 && n.Result.Retention.Status == dto.RetentionStatusApplied
+		})).Return(entity.CallbackDelivery{Attempts: 1, StatusCode: 200}, nil)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4", VideoId: "7", Callback: callback})
+		r.NoError(err)
+		r.Equal(&dto.CallbackOutput{URL: callback.URL, Status: dto.CallbackStatusDelivered, Attempts: 1, StatusCode: 200}, out.Callback)
+	})
+
+	t.Run("failed_job_is_reported_and_delivery_failure_recorded", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		uc := NewVideoUseCase(vg, nil, nil, nil, logger.NewSlogLogger())
+
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInvalidInput, domain.StageInput)).Return(nil)
+		vg.EXPECT().NotifyCallback(gomock.Any(), gomock.Cond(func(n dto.CallbackNotification) bool {
+			return !n.Result.Success && n.Result.Error != ""
+		})).Return(entity.CallbackDelivery{Attempts: 3, StatusCode: 503}, errors.New("callback responded with status 503"))
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4", RetentionPolicy: "archive", Callback: callback})
+		var inv *domain.InvalidInputError
+		r.ErrorAs(err, &inv)
+		r.Equal(dto.CallbackStatusFailed, out.Callback.Status)
+		r.Equal(3, out.Callback.Attempts)
+		r.Equal(503, out.Callback.StatusCode)
+		r.Contains(out.Callback.Error, "503")
+	})
+
+	t.Run("disallowed_host_is_rejected", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		uc := NewVideoUseCase(vg, nil, nil, nil, logger.NewSlogLogger())
+
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInvalidInput, domain.StageInput)).Return(nil)
+		vg.EXPECT().NotifyCallback(gomock.Any(), gomock.Any()).
+			Return(entity.CallbackDelivery{}, domain.NewInvalidInputError("callback host \"10.0.0.1\" is not allowed"))
+
+		out, _ := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4", RetentionPolicy: "archive", Callback: callback})
+		r.Equal(dto.CallbackStatusRejected, out.Callback.Status)
+		r.Zero(out.Callback.Attempts)
+	})
+
+	t.Run("unsigned_callback_fails_the_input", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		uc := NewVideoUseCase(vg, nil, nil, nil, logger.NewSlogLogger())
+
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInvalidInput, domain.StageInput)).Return(nil)
+		// no NotifyCallback expected: it would be sent unsigned
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{
+			VideoKey: "vid.mp4",
+			Callback: &dto.CallbackInput{URL: "https://hooks.example.com/video"},
+		})
+		r.Equal(domain.ErrorCodeInvalidInput, domain.CodeOf(err))
+		r.ErrorContains(err, "callback.secret is required")
+		r.Nil(out.Callback)
+	})
+
+	t.Run("no_callback_configured", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		uc := NewVideoUseCase(vg, nil, nil, nil, logger.NewSlogLogger())
+
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInvalidInput, domain.StageInput)).Return(nil)
+		// no NotifyCallback expected
+		out, _ := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4", RetentionPolicy: "archive"})
+		r.Nil(out.Callback)
+	})
+}
+
+func TestVideoUseCase_Workspace(t *testing.T) {
+	t.Run("temp_files_are_created_in_the_job_workspace", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, nil, fm, nil, logger.NewSlogLogger())
+
+		type workspaceKey struct{}
+		inWorkspace := gomock.Cond(func(ctx context.Context) bool { return ctx.Value(workspaceKey{}) != nil })
+		gomock.InOrder(
+			fm.EXPECT().OpenWorkspace(gomock.Any()).DoAndReturn(func(ctx context.Context) (context.Context, error) {
+				return context.WithValue(ctx, workspaceKey{}, "ws"), nil
+			}),
+			vg.EXPECT().Stat(gomock.Any(), "vid.mp4").Return(&entity.StorageObject{Size: 100}, nil),
+			fm.EXPECT().EnsureSpace(inWorkspace, int64(100*tempSpaceFactor)).Return(nil),
+			fm.EXPECT().CreateTempFile(inWorkspace, "video_", ".mp4").Return("/tmp/ws/v.mp4", nil),
+			vg.EXPECT().Download(gomock.Any(), "vid.mp4", entity.ObjectVersion{}).Return(nil, entity.ObjectVersion{}, errors.New("reset")),
+			fm.EXPECT().DeleteFile(gomock.Any(), "/tmp/ws/v.mp4").Return(nil),
+			fm.EXPECT().CloseWorkspace(inWorkspace).Return(int64(0), nil),
+		)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInternal, domain.StageDownload)).Return(nil)
+
+		_, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4"})
+		r.Error(err)
+	})
+
+	t.Run("insufficient_space_fails_before_download", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, nil, fm, nil, logger.NewSlogLogger())
+
+		fm.EXPECT().OpenWorkspace(gomock.Any()).DoAndReturn(func(ctx context.Context) (context.Context, error) { return ctx, nil })
+		vg.EXPECT().Stat(gomock.Any(), "vid.mp4").Return(&entity.StorageObject{Size: 1 << 30}, nil)
+		fm.EXPECT().EnsureSpace(gomock.Any(), int64(1<<30)*tempSpaceFactor).Return(errors.New("3221225472 bytes needed in /tmp, only 1024 available"))
+		fm.EXPECT().CloseWorkspace(gomock.Any()).Return(int64(0), nil)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeInternal, domain.StageDownload)).Return(nil)
+
+		out, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4"})
+		r.Error(err)
+		r.Contains(out.Error, "not enough space")
+		r.False(out.Retryable)
+	})
+
+	t.Run("missing_video_is_not_found_before_download", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		uc := NewVideoUseCase(vg, nil, fm, nil, logger.NewSlogLogger())
+
+		fm.EXPECT().OpenWorkspace(gomock.Any()).DoAndReturn(func(ctx context.Context) (context.Context, error) { return ctx, nil })
+		vg.EXPECT().Stat(gomock.Any(), "vid.mp4").Return(nil, domain.NewNotFoundError(domain.ErrNotFound))
+		fm.EXPECT().CloseWorkspace(gomock.Any()).Return(int64(0), nil)
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), failedWith(domain.ErrorCodeNotFound, domain.StageDownload)).Return(nil)
+
+		_, err := uc.ProcessVideo(context.Background(), dto.ProcessVideoInput{VideoKey: "vid.mp4"})
+		var nErr *domain.NotFoundError
+		r.ErrorAs(err, &nErr)
+	})
+}
+
+func TestVideoUseCase_SourceCache(t *testing.T) {
+	const videoKey = "vid.mp4"
+	const local = "/tmp/v.mp4"
+
+	// run processes videoKey, cached in sc, up to an extraction without frames, returning the uploaded report
+	run := func(t *testing.T, vg *pmocks.MockVideoGateway, vp *pmocks.MockVideoProcessor, fm *pmocks.MockFileManager, sc *pmocks.MockSourceCache, input dto.ProcessVideoInput) (dto.ProcessingReport, error) {
+		fm.EXPECT().OpenWorkspace(gomock.Any()).DoAndReturn(func(ctx context.Context) (context.Context, error) { return ctx, nil })
+		fm.EXPECT().EnsureSpace(gomock.Any(), gomock.Any()).Return(nil)
+		fm.EXPECT().CloseWorkspace(gomock.Any()).Return(int64(0), nil)
+		fm.EXPECT().CreateTempFile(gomock.Any(), "video_", ".mp4").Return(local, nil)
+		fm.EXPECT().DeleteFile(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+		vp.EXPECT().ValidateVideo(gomock.Any(), local).Return(nil).AnyTimes()
+		vp.EXPECT().ProbeVideo(gomock.Any(), local).Return(&entity.VideoMetadata{}, nil).AnyTimes()
+		vp.EXPECT().ProcessVideo(gomock.Any(), local, gomock.Any()).Return(&entity.FrameExtraction{ArchivePath: "/tmp/f.zip"}, nil).AnyTimes()
+		vg.EXPECT().UpdateStatus(gomock.Any(), statusIs("FAILED")).Return(nil)
+
+		var report dto.ProcessingReport
+		vg.EXPECT().UploadProcessingReport(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, rep *dto.ProcessingReport) error {
+				report = *rep
+				return nil
+			})
+		uc := NewVideoUseCase(vg, vp, fm, sc, logger.NewSlogLogger())
+		_, err := uc.ProcessVideo(context.Background(), input)
+		return report, err
+	}
+
+	t.Run("hit_skips_the_download_and_the_hashing", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		sc := pmocks.NewMockSourceCache(ctrl)
+
+		vg.EXPECT().Stat(gomock.Any(), videoKey).Return(&entity.StorageObject{Key: videoKey, Size: 10, Version: testVersion}, nil)
+		sc.EXPECT().Fetch(gomock.Any(), videoKey, testVersion, local).Return("cached-hash", true, nil)
+
+		report, err := run(t, vg, vp, fm, sc, dto.ProcessVideoInput{VideoKey: videoKey})
+		var invErr *domain.InvalidInputError
+		r.ErrorAs(err, &invErr)
+		r.Equal(dto.SourceCacheHit, report.SourceCache)
+		r.Equal("cached-hash", report.Hash)
+		r.Equal(testVersion.ETag, report.SourceETag)
+		r.Zero(report.BytesDownloaded)
+	})
+
+	t.Run("miss_downloads_and_stores_the_video", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		sc := pmocks.NewMockSourceCache(ctrl)
+
+		vg.EXPECT().Stat(gomock.Any(), videoKey).Return(&entity.StorageObject{Key: videoKey, Size: 10, Version: testVersion}, nil)
+		sc.EXPECT().Fetch(gomock.Any(), videoKey, testVersion, local).Return("", false, nil)
+		vg.EXPECT().Download(gomock.Any(), videoKey, entity.ObjectVersion{}).Return(io.NopCloser(strings.NewReader("video data")), testVersion, nil)
+		fm.EXPECT().WriteToFile(gomock.Any(), local, gomock.Any()).DoAndReturn(func(_ context.Context, _ string, data io.Reader) error {
+			_, err := io.Copy(io.Discard, data)
+			return err
+		})
+		var stored string
+		sc.EXPECT().Store(gomock.Any(), videoKey, testVersion, local, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ string, _ entity.ObjectVersion, _, hash string) error {
+				stored = hash
+				return nil
+			})
+
+		report, err := run(t, vg, vp, fm, sc, dto.ProcessVideoInput{VideoKey: videoKey})
+		r.Error(err)
+		r.Equal(dto.SourceCacheMiss, report.SourceCache)
+		r.Equal(report.Hash, stored)
+		r.Equal(int64(len("video data")), report.BytesDownloaded)
+	})
+
+	t.Run("replaced_source_is_not_looked_up", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		vp := pmocks.NewMockVideoProcessor(ctrl)
+		fm := pmocks.NewMockFileManager(ctrl)
+		sc := pmocks.NewMockSourceCache(ctrl)
+
+		announced := entity.ObjectVersion{ETag: `"etag-0"`}
+		vg.EXPECT().Stat(gomock.Any(), videoKey).Return(&entity.StorageObject{Key: videoKey, Size: 10, Version: testVersion}, nil)
+		vg.EXPECT().Download(gomock.Any(), videoKey, announced).Return(nil, entity.ObjectVersion{}, domain.NewConflictError(domain.ErrSourceChanged))
+
+		report, err := run(t, vg, vp, fm, sc, dto.ProcessVideoInput{VideoKey: videoKey, SourceETag: announced.ETag})
+		var cErr *domain.ConflictError
+		r.ErrorAs(err, &cErr)
+		r.Empty(report.SourceCache)
+	})
+}
+
+// failedWith matches a FAILED status update by the code and stage of its failure
+func failedWith(code domain.ErrorCode, stage domain.Stage) gomock.Matcher {
+	return gomock.Cond(func(u dto.VideoStatusUpdate) bool {
+		return u.Status == "FAILED" && u.Failure != nil && u.Failure.Code == string(code) && u.Failure.Stage == string(stage)
+	})
+}
+
+// statusIs matches a status update by its status value
+func statusIs(status string) gomock.Matcher {
+	return gomock.Cond(func(u dto.VideoStatusUpdate) bool { return u.Status == status })
+}
+
+// expectNewOutput expects the check, before the upload, that no previous run stored the archive
+func expectNewOutput(vg *pmocks.MockVideoGateway) {
+	vg.EXPECT().StatOutput(gomock.Any(), gomock.Any()).Return(int64(0), domain.NewNotFoundError(domain.ErrNotFound))
+}
+
+// expectWorkspace expects the job to run in a workspace with room for a 10-byte video
+func expectWorkspace(fm *pmocks.MockFileManager, vg *pmocks.MockVideoGateway) {
+	fm.EXPECT().OpenWorkspace(gomock.Any()).DoAndReturn(func(ctx context.Context) (context.Context, error) { return ctx, nil })
+	vg.EXPECT().Stat(gomock.Any(), gomock.Any()).Return(&entity.StorageObject{Size: 10}, nil)
+	fm.EXPECT().EnsureSpace(gomock.Any(), int64(10*tempSpaceFactor)).Return(nil)
+	fm.EXPECT().CloseWorkspace(gomock.Any()).Return(int64(30), nil)
+}
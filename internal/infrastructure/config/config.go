@@ -1,107 +1,215 @@
 package config
 
 import (
-	"log"
-	"os"
-	"strconv"
+	"strings"
 
-	"github.com/joho/godotenv"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
 )
 
+// Config is the typed configuration of the job. Every field is a setting (see settings.go) that can be set, in
+// increasing order of precedence, by its default, the config file, its environment variable and its flag.
 type Config struct {
-	// AWS Settings
-	AWS struct {
-		Region          string
-		AccessKey       string
-		SecretAccessKey string
-		SessionToken    string
-	}
+	AWS         AWSConfig         `yaml:"aws" json:"aws"`
+	Video       VideoConfig       `yaml:"video" json:"video"`
+	Job         JobConfig         `yaml:"job" json:"job"`
+	Batch       BatchConfig       `yaml:"batch" json:"batch"`
+	Trigger     TriggerConfig     `yaml:"trigger" json:"trigger"`
+	Lambda      LambdaConfig      `yaml:"lambda" json:"lambda"`
+	Status      StatusConfig      `yaml:"status" json:"status"`
+	Callback    CallbackConfig    `yaml:"callback" json:"callback"`
+	Outbox      OutboxConfig      `yaml:"outbox" json:"outbox"`
+	FFmpeg      FFmpegConfig      `yaml:"ffmpeg" json:"ffmpeg"`
+	Workspace   WorkspaceConfig   `yaml:"workspace" json:"workspace"`
+	SourceCache SourceCacheConfig `yaml:"source_cache" json:"source_cache"`
+	Termination TerminationConfig `yaml:"termination" json:"termination"`
+	Metrics     MetricsConfig     `yaml:"metrics" json:"metrics"`
+	Tracing     TracingConfig     `yaml:"tracing" json:"tracing"`
+	Log         LogConfig         `yaml:"log" json:"log"`
+
+	// diagnostics is set by LoadDiagnostics: the settings of the job run are not required
+	diagnostics bool
+}
 
-	// Video Processing Settings
-	Video struct {
-		Key             string
-		Id              string
-		UserId          string
-		Bucket          string
-		ProcessedBucket string
-		ExportFormat    string
-		ExportFPS       float64
-		SnsTopic        string
-	}
+// AWSConfig holds the AWS region, credentials and endpoints
+type AWSConfig struct {
+	Region          string `yaml:"region" json:"region"`
+	AccessKey       string `yaml:"access_key_id" json:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key" json:"secret_access_key"`
+	SessionToken    string `yaml:"session_token" json:"session_token"`
+	// Endpoint overrides point the clients at S3/SNS/SQS compatible stand-ins such as MinIO or LocalStack
+	S3Endpoint       string `yaml:"s3_endpoint_url" json:"s3_endpoint_url"`
+	S3ForcePathStyle bool   `yaml:"s3_force_path_style" json:"s3_force_path_style"`
+	SNSEndpoint      string `yaml:"sns_endpoint_url" json:"sns_endpoint_url"`
+	SQSEndpoint      string `yaml:"sqs_endpoint_url" json:"sqs_endpoint_url"`
 }
 
-// LoadConfig loads configuration from environment variables
-func LoadConfig() *Config {
-	// Try to load .env file, but don't fail if it doesn't exist
-	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
-		log.Printf("Warning: .env file not found or error loading it: %v", err)
-	}
+// VideoConfig holds the video to process and the processing settings
+type VideoConfig struct {
+	Key             string  `yaml:"key" json:"key"`
+	Id              string  `yaml:"id" json:"id"`
+	UserId          string  `yaml:"user_id" json:"user_id"`
+	IdFormat        string  `yaml:"id_format" json:"id_format"`
+	Bucket          string  `yaml:"bucket" json:"bucket"`
+	ProcessedBucket string  `yaml:"processed_bucket" json:"processed_bucket"`
+	ExportFormat    string  `yaml:"export_format" json:"export_format"`
+	ExportFPS       float64 `yaml:"export_fps" json:"export_fps"`
+	RetentionPolicy string  `yaml:"retention_policy" json:"retention_policy"`
+}
 
-	config := &Config{}
-
-	// AWS Configuration
-	config.AWS.Region = getEnv("AWS_REGION", "us-east-1")
-	config.AWS.AccessKey = getEnv("AWS_ACCESS_KEY_ID", "")
-	config.AWS.SecretAccessKey = getEnv("AWS_SECRET_ACCESS_KEY", "")
-	config.AWS.SessionToken = getEnv("AWS_SESSION_TOKEN", "")
-
-	// Video Configuration
-	config.Video.Key = getEnv("VIDEO_KEY", "")
-	config.Video.Id = getEnv("VIDEO_ID", "1")
-	config.Video.UserId = getEnv("VIDEO_USER_ID", "1")
-	config.Video.Bucket = getEnv("VIDEO_BUCKET", "video-processor-raw-videos")
-	config.Video.ProcessedBucket = getEnv("PROCESSED_BUCKET", "video-processor-processed-images")
-	config.Video.ExportFormat = getEnv("VIDEO_EXPORT_FORMAT", "jpg")
-	config.Video.SnsTopic = getEnv("SNS_TOPIC_ARN", "arn:aws:sns:us-east-1:905417995957:video-status-updated")
-	// Parse frame rate
-	frameRateStr := getEnv("VIDEO_EXPORT_FPS", "1.0")
-	frameRate, err := strconv.ParseFloat(frameRateStr, 64)
-	if err != nil {
-		log.Printf("Warning: Invalid VIDEO_EXPORT_FPS '%s', using default 1.0: %v", frameRateStr, err)
-		frameRate = 1.0
-	}
-	config.Video.ExportFPS = frameRate
+// JobConfig holds the identifier of the run and the JSON job spec that describes the video and its processing in
+// one document
+type JobConfig struct {
+	ID       string `yaml:"id" json:"id"`
+	Spec     string `yaml:"spec" json:"spec"`
+	SpecFile string `yaml:"spec_file" json:"spec_file"`
+}
 
-	return config
+// BatchConfig holds the batch settings
+type BatchConfig struct {
+	Keys         []string `yaml:"keys" json:"keys"`
+	Prefix       string   `yaml:"prefix" json:"prefix"`
+	Concurrency  int      `yaml:"concurrency" json:"concurrency"`
+	MaxTempBytes int64    `yaml:"max_temp_bytes" json:"max_temp_bytes"`
 }
 
-// ValidateRequiredFields validates that all required configuration fields are set
-func (c *Config) ValidateRequiredFields() error {
-	var missingFields []string
+// TriggerConfig holds the S3 event trigger settings
+type TriggerConfig struct {
+	Event      string `yaml:"event" json:"event"`
+	EventFile  string `yaml:"event_file" json:"event_file"`
+	KeyPattern string `yaml:"key_pattern" json:"key_pattern"`
+}
 
-	if c.AWS.AccessKey == "" {
-		missingFields = append(missingFields, "AWS_ACCESS_KEY_ID")
-	}
-	if c.AWS.SecretAccessKey == "" {
-		missingFields = append(missingFields, "AWS_SECRET_ACCESS_KEY")
-	}
-	if c.AWS.SessionToken == "" {
-		missingFields = append(missingFields, "AWS_SESSION_TOKEN")
-	}
-	if c.Video.Key == "" {
-		missingFields = append(missingFields, "VIDEO_KEY")
-	}
+// LambdaConfig holds the Lambda settings
+type LambdaConfig struct {
+	RuntimeAPI string `yaml:"runtime_api" json:"runtime_api"`
+}
 
-	if len(missingFields) > 0 {
-		return &ConfigValidationError{MissingFields: missingFields}
-	}
+// StatusConfig holds the status broker settings
+type StatusConfig struct {
+	Broker      string `yaml:"broker" json:"broker"`
+	BrokerURL   string `yaml:"broker_url" json:"broker_url"`
+	EventFormat string `yaml:"event_format" json:"event_format"`
+	EventSource string `yaml:"event_source" json:"event_source"`
+}
+
+// CallbackConfig holds the result callback settings
+type CallbackConfig struct {
+	URL           string   `yaml:"url" json:"url"`
+	Secret        string   `yaml:"secret" json:"secret"`
+	AllowedHosts  []string `yaml:"allowed_hosts" json:"allowed_hosts"`
+	InsecureHosts []string `yaml:"insecure_hosts" json:"insecure_hosts"`
+	MaxAttempts   int      `yaml:"max_attempts" json:"max_attempts"`
+}
+
+// OutboxConfig holds the status outbox settings
+type OutboxConfig struct {
+	Path        string `yaml:"path" json:"path"`
+	MaxAttempts int    `yaml:"max_attempts" json:"max_attempts"`
+}
+
+// FFmpegConfig holds the FFmpeg settings
+type FFmpegConfig struct {
+	SegmentConcurrency int     `yaml:"segment_concurrency" json:"segment_concurrency"`
+	MinSegmentDuration float64 `yaml:"min_segment_duration" json:"min_segment_duration"`
+}
+
+// WorkspaceConfig holds where the per-job workspaces holding the temp files are created
+type WorkspaceConfig struct {
+	Dir string `yaml:"dir" json:"dir"`
+}
+
+// SourceCacheConfig holds the local cache of downloaded videos, disabled when no directory is set
+type SourceCacheConfig struct {
+	Dir      string `yaml:"dir" json:"dir"`
+	MaxBytes int64  `yaml:"max_bytes" json:"max_bytes"`
+}
+
+// MetricsConfig holds where the Prometheus metrics are pushed when a job exits and served in long-running modes
+type MetricsConfig struct {
+	PushgatewayURL string `yaml:"pushgateway_url" json:"pushgateway_url"`
+	PushJob        string `yaml:"push_job" json:"push_job"`
+	ListenAddress  string `yaml:"listen_address" json:"listen_address"`
+}
+
+// TracingConfig holds where the OpenTelemetry spans are exported and the trace the job continues
+type TracingConfig struct {
+	Exporter    string `yaml:"exporter" json:"exporter"`
+	Endpoint    string `yaml:"endpoint" json:"endpoint"`
+	File        string `yaml:"file" json:"file"`
+	ServiceName string `yaml:"service_name" json:"service_name"`
+	TraceParent string `yaml:"traceparent" json:"traceparent"`
+}
+
+// LogConfig holds the level and format of the logs
+type LogConfig struct {
+	Level  string `yaml:"level" json:"level"`
+	Format string `yaml:"format" json:"format"`
+}
+
+// TerminationConfig holds where the summary of the result is written for the container orchestrator
+type TerminationConfig struct {
+	MessagePath string `yaml:"message_path" json:"message_path"`
+}
+
+// HasStaticCredentials reports whether explicit access keys were configured instead of the default credential chain
+func (c *Config) HasStaticCredentials() bool {
+	return c.AWS.AccessKey != "" && c.AWS.SecretAccessKey != ""
+}
+
+// HasJobSpec reports whether the video to process is described by a JSON job spec instead of the video settings
+func (c *Config) HasJobSpec() bool {
+	return c.Job.Spec != "" || c.Job.SpecFile != ""
+}
+
+// IsBatch reports whether the job was configured to process a list of keys or a prefix
+func (c *Config) IsBatch() bool {
+	return len(c.Batch.Keys) > 0 || c.Batch.Prefix != ""
+}
 
-	return nil
+// IsTriggered reports whether the job was started with an S3 event notification instead of a key
+func (c *Config) IsTriggered() bool {
+	return c.Trigger.Event != "" || c.Trigger.EventFile != ""
 }
 
-// ConfigValidationError represents a configuration validation error
+// IsLambda reports whether the job runs as a Lambda custom runtime serving invocations
+func (c *Config) IsLambda() bool {
+	return c.Lambda.RuntimeAPI != ""
+}
+
+// ConfigValidationError lists every problem found while loading and validating the configuration
 type ConfigValidationError struct {
+	// MissingFields are the required settings that were not set
 	MissingFields []string
+	// InvalidFields describe the settings whose value could not be parsed or is not allowed
+	InvalidFields []string
 }
 
 func (e *ConfigValidationError) Error() string {
-	return "missing required environment variables"
+	var parts []string
+	if len(e.MissingFields) > 0 {
+		parts = append(parts, "missing required settings: "+strings.Join(e.MissingFields, ", "))
+	}
+	if len(e.InvalidFields) > 0 {
+		parts = append(parts, "invalid settings: "+strings.Join(e.InvalidFields, "; "))
+	}
+	return "invalid configuration: " + strings.Join(parts, "; ")
+}
+
+// Code classifies configuration problems as invalid input, which a new run with the same settings does not fix
+func (e *ConfigValidationError) Code() domain.ErrorCode { return domain.ErrorCodeInvalidInput }
+
+func (e *ConfigValidationError) missing(s setting) {
+	e.MissingFields = append(e.MissingFields, s.label())
+}
+
+func (e *ConfigValidationError) invalid(s setting, reason string) {
+	e.InvalidFields = append(e.InvalidFields, s.label()+": "+reason)
 }
 
-// getEnv gets environment variable with fallback
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// errOrNil returns nil when no problem was recorded, so the result can be returned as an error
+func (e *ConfigValidationError) errOrNil() error {
+	if len(e.MissingFields) == 0 && len(e.InvalidFields) == 0 {
+		return nil
 	}
-	return defaultValue
+	return e
 }
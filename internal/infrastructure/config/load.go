@@ -0,0 +1,195 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv selects the config file when the --config flag is not given
+const ConfigFileEnv = "CONFIG_FILE"
+
+// Load builds the configuration from, in increasing order of precedence: the defaults, the config file (YAML or
+// JSON, from --config or CONFIG_FILE), the environment (including a .env file) and the flags in args.
+//
+// Load registers a flag per setting, plus --config, on fs before parsing args with it, so commands can add their
+// own flags and read the positional arguments from fs.Args(); flags may also follow the positional arguments.
+// Every invalid or missing setting is reported at once in a *ConfigValidationError, which is returned together
+// with the configuration so the caller can still inspect it. Malformed flags and -h return a nil configuration
+// (flag.ErrHelp for -h).
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	// Try to load .env file, but don't fail if it doesn't exist
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: .env file not found or error loading it: %v", err)
+	}
+	return load(fs, args, os.LookupEnv, false)
+}
+
+// LoadDiagnostics loads the configuration like Load for the diagnostic commands, which neither process the
+// configured video nor publish status events: the video to process and the status broker are not required.
+func LoadDiagnostics(fs *flag.FlagSet, args []string) (*Config, error) {
+	if err := godotenv.Load(); err != nil && !os.IsNotExist(err) {
+		log.Printf("Warning: .env file not found or error loading it: %v", err)
+	}
+	return load(fs, args, os.LookupEnv, true)
+}
+
+// flagValue records a flag so it is applied after the file and the environment, whatever its position in args
+type flagValue struct {
+	setting setting
+	pending *[]pendingFlag
+}
+
+type pendingFlag struct {
+	setting setting
+	raw     string
+}
+
+func (f flagValue) String() string { return "" }
+
+func (f flagValue) Set(raw string) error {
+	*f.pending = append(*f.pending, pendingFlag{setting: f.setting, raw: raw})
+	return nil
+}
+
+// IsBoolFlag lets boolean settings be given as a bare --flag
+func (f flagValue) IsBoolFlag() bool {
+	_, ok := f.setting.value.(*bool)
+	return ok
+}
+
+func load(fs *flag.FlagSet, args []string, lookupEnv func(string) (string, bool), diagnostics bool) (*Config, error) {
+	cfg := &Config{diagnostics: diagnostics}
+	settings := cfg.settings()
+
+	var pending []pendingFlag
+	configFile := fs.String("config", "", "YAML or JSON config file (env: "+ConfigFileEnv+")")
+	for _, s := range settings {
+		usage := s.Usage
+		if s.Env != "" {
+			usage += " (env: " + s.Env + ")"
+		}
+		fs.Var(flagValue{setting: s, pending: &pending}, s.flagName(), usage)
+		if s.Default != "" {
+			fs.Lookup(s.flagName()).DefValue = s.Default
+		}
+	}
+	if err := parseInterleaved(fs, args); err != nil {
+		return nil, err
+	}
+
+	problems := &ConfigValidationError{}
+
+	// 1. defaults
+	for _, s := range settings {
+		if s.Default != "" {
+			if err := s.set(s.Default); err != nil {
+				panic(fmt.Sprintf("config: invalid default of %s: %v", s.Name, err))
+			}
+		}
+	}
+
+	// 2. config file
+	path := *configFile
+	if path == "" {
+		path, _ = lookupEnv(ConfigFileEnv)
+	}
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			problems.InvalidFields = append(problems.InvalidFields, err.Error())
+		}
+	}
+
+	// 3. environment
+	for _, s := range settings {
+		raw, ok := lookupEnv(s.Env)
+		if !ok && s.LegacyEnv != "" {
+			raw, ok = lookupEnv(s.LegacyEnv)
+		}
+		// An empty variable clears a text setting but leaves a typed one to the lower layers
+		if !ok || (raw == "" && !s.isText()) {
+			continue
+		}
+		if err := s.set(raw); err != nil {
+			problems.invalid(s, err.Error())
+		}
+	}
+
+	// 4. flags
+	for _, f := range pending {
+		if err := f.setting.set(f.raw); err != nil {
+			problems.invalid(f.setting, fmt.Sprintf("--%s: %v", f.setting.flagName(), err))
+		}
+	}
+
+	cfg.normalize()
+	cfg.validate(problems)
+	return cfg, problems.errOrNil()
+}
+
+// parseInterleaved parses args with fs, letting flags follow the positional arguments (as in "probe video.mp4
+// --output json") until a "--"; the positional arguments are then available from fs.Args()
+func parseInterleaved(fs *flag.FlagSet, args []string) error {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		if len(rest) == 0 {
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+	// "--" ends the flags, so the positional arguments become fs.Args() whatever they look like
+	return fs.Parse(append([]string{"--"}, positional...))
+}
+
+// loadFile merges the config file into the configuration; unknown keys are rejected
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(c)
+	} else {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(c)
+		if errors.Is(err, io.EOF) {
+			// an empty file sets nothing
+			err = nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// normalize puts the enumerated settings in their canonical case
+func (c *Config) normalize() {
+	c.Video.IdFormat = strings.ToLower(strings.TrimSpace(c.Video.IdFormat))
+	c.Video.ExportFormat = strings.ToLower(strings.TrimSpace(c.Video.ExportFormat))
+	c.Status.Broker = strings.ToLower(strings.TrimSpace(c.Status.Broker))
+	c.Status.EventFormat = strings.ToLower(strings.TrimSpace(c.Status.EventFormat))
+}
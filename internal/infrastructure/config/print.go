@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces secrets in printed configurations
+const redactedValue = "REDACTED"
+
+// Redacted returns a copy of the configuration that is safe to print: secrets are replaced and the passwords
+// embedded in URLs are masked
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	for _, s := range redacted.settings() {
+		value, ok := s.value.(*string)
+		if !ok || *value == "" {
+			continue
+		}
+		switch {
+		case s.Secret:
+			*value = redactedValue
+		case s.URL:
+			*value = redactURL(*value)
+		}
+	}
+	return &redacted
+}
+
+// SecretValues returns the configured secrets and the passwords embedded in URLs, for the logs to scrub
+func (c *Config) SecretValues() []string {
+	var secrets []string
+	for _, s := range c.settings() {
+		value, ok := s.value.(*string)
+		if !ok || *value == "" {
+			continue
+		}
+		switch {
+		case s.Secret:
+			secrets = append(secrets, *value)
+		case s.URL:
+			if u, err := url.Parse(*value); err == nil {
+				if password, ok := u.User.Password(); ok && password != "" {
+					secrets = append(secrets, password)
+				}
+			}
+		}
+	}
+	return secrets
+}
+
+// Print writes the redacted configuration as yaml or json
+func (c *Config) Print(w io.Writer, format string) error {
+	redacted := c.Redacted()
+	switch format {
+	case "", "yaml":
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(redacted); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(redacted)
+	default:
+		return fmt.Errorf("unsupported output format %q (allowed: yaml, json)", format)
+	}
+}
+
+// redactURL masks the password of a URL; values that do not parse are redacted entirely
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redactedValue
+	}
+	return u.Redacted()
+}
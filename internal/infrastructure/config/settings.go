@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// setting describes one configuration value: where it lives in the config file, the environment variable and
+// flag that override it, and its default
+type setting struct {
+	// Name is the dotted path of the setting in the config file, e.g. video.export_fps
+	Name string
+	Env  string
+	// LegacyEnv is read when Env is not set, for variables that were renamed
+	LegacyEnv string
+	Default   string
+	Usage     string
+	// Secret values are redacted when the configuration is printed
+	Secret bool
+	// URL values may embed credentials, whose password is redacted when the configuration is printed
+	URL bool
+	// value points at the Config field: *string, *bool, *int, *int64, *float64 or *[]string
+	value any
+}
+
+// flagName is the CLI flag of the setting, e.g. --video.export-fps
+func (s setting) flagName() string {
+	return strings.ReplaceAll(s.Name, "_", "-")
+}
+
+// label identifies the setting in errors by its config path and environment variable
+func (s setting) label() string {
+	if s.Env == "" {
+		return s.Name
+	}
+	return s.Name + " (" + s.Env + ")"
+}
+
+// set parses raw into the setting's field
+func (s setting) set(raw string) error {
+	switch v := s.value.(type) {
+	case *string:
+		*v = raw
+	case *bool:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		*v = parsed
+	case *int:
+		parsed, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		*v = parsed
+	case *int64:
+		parsed, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		*v = parsed
+	case *float64:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		*v = parsed
+	case *[]string:
+		*v = splitList(raw)
+	default:
+		panic(fmt.Sprintf("config: unsupported type %T of setting %s", s.value, s.Name))
+	}
+	return nil
+}
+
+// isText reports whether the setting holds free text, where an empty value is meaningful
+func (s setting) isText() bool {
+	_, ok := s.value.(*string)
+	return ok
+}
+
+// settings is the schema of the configuration, bound to the fields of c
+func (c *Config) settings() []setting {
+	return []setting{
+		// AWS
+		{Name: "aws.region", Env: "AWS_REGION", Default: "us-east-1", Usage: "AWS region", value: &c.AWS.Region},
+		{Name: "aws.access_key_id", Env: "AWS_ACCESS_KEY_ID", Secret: true,
+			Usage: "static access key; the default credential chain is used when unset", value: &c.AWS.AccessKey},
+		{Name: "aws.secret_access_key", Env: "AWS_SECRET_ACCESS_KEY", Secret: true,
+			Usage: "static secret key, required with the access key", value: &c.AWS.SecretAccessKey},
+		{Name: "aws.session_token", Env: "AWS_SESSION_TOKEN", Secret: true,
+			Usage: "session token of temporary static keys", value: &c.AWS.SessionToken},
+		{Name: "aws.s3_endpoint_url", Env: "S3_ENDPOINT_URL", Usage: "S3 compatible endpoint (MinIO, LocalStack)", value: &c.AWS.S3Endpoint},
+		{Name: "aws.s3_force_path_style", Env: "S3_FORCE_PATH_STYLE", Default: "false",
+			Usage: "address buckets in the path instead of the host", value: &c.AWS.S3ForcePathStyle},
+		{Name: "aws.sns_endpoint_url", Env: "SNS_ENDPOINT_URL", Usage: "SNS compatible endpoint (LocalStack)", value: &c.AWS.SNSEndpoint},
+		{Name: "aws.sqs_endpoint_url", Env: "SQS_ENDPOINT_URL", Usage: "SQS compatible endpoint (LocalStack)", value: &c.AWS.SQSEndpoint},
+
+		// Video
+		{Name: "video.key", Env: "VIDEO_KEY", Usage: "key of the video to process", value: &c.Video.Key},
+		{Name: "video.id", Env: "VIDEO_ID", Usage: "video identifier reported in status events and callbacks", value: &c.Video.Id},
+		{Name: "video.user_id", Env: "VIDEO_USER_ID", Usage: "owner identifier reported in status events and callbacks", value: &c.Video.UserId},
+		{Name: "video.id_format", Env: "VIDEO_ID_FORMAT", Default: "any",
+			Usage: "format of the video and user IDs: any, numeric, uuid or ulid", value: &c.Video.IdFormat},
+		{Name: "video.bucket", Env: "VIDEO_BUCKET", Default: "video-processor-raw-videos", Usage: "bucket of the original videos", value: &c.Video.Bucket},
+		{Name: "video.processed_bucket", Env: "PROCESSED_BUCKET", Default: "video-processor-processed-images",
+			Usage: "bucket of the frame archives", value: &c.Video.ProcessedBucket},
+		{Name: "video.export_format", Env: "VIDEO_EXPORT_FORMAT", Default: "jpg", Usage: "frame format: jpg or png", value: &c.Video.ExportFormat},
+		{Name: "video.export_fps", Env: "VIDEO_EXPORT_FPS", Default: "1.0", Usage: "frames extracted per second", value: &c.Video.ExportFPS},
+		{Name: "video.retention_policy", Env: "VIDEO_RETENTION_POLICY", Default: "delete",
+			Usage: "what happens to the original video: delete, keep, move:<prefix> or tag:<key>=<value>", value: &c.Video.RetentionPolicy},
+
+		// Job; the spec can carry the callback secret, so it is never printed
+		{Name: "job.id", Env: "JOB_ID", Usage: "identifier of the run added to the logs, e.g. the Kubernetes Job name", value: &c.Job.ID},
+		{Name: "job.spec", Env: "VIDEO_JOB_SPEC", Secret: true, Usage: "JSON job spec of the video to process", value: &c.Job.Spec},
+		{Name: "job.spec_file", Env: "VIDEO_JOB_SPEC_FILE", Usage: "file with the JSON job spec; - reads stdin", value: &c.Job.SpecFile},
+
+		// Batch
+		{Name: "batch.keys", Env: "VIDEO_KEYS", Usage: "comma-separated keys processed as a batch", value: &c.Batch.Keys},
+		{Name: "batch.prefix", Env: "VIDEO_PREFIX", Usage: "prefix whose videos are processed as a batch", value: &c.Batch.Prefix},
+		{Name: "batch.concurrency", Env: "BATCH_CONCURRENCY", Default: "4", Usage: "videos processed concurrently", value: &c.Batch.Concurrency},
+		{Name: "batch.max_temp_bytes", Env: "BATCH_MAX_TEMP_BYTES", Default: "0",
+			Usage: "temp space shared by the batch, in bytes; 0 is unlimited", value: &c.Batch.MaxTempBytes},
+
+		// S3 event trigger
+		{Name: "trigger.event", Env: "S3_EVENT", Usage: "S3 ObjectCreated notification to process", value: &c.Trigger.Event},
+		{Name: "trigger.event_file", Env: "S3_EVENT_FILE", Usage: "file with the S3 notification; - reads stdin", value: &c.Trigger.EventFile},
+		{Name: "trigger.key_pattern", Env: "S3_EVENT_KEY_PATTERN",
+			Usage: "regexp with the video_id and user_id named groups read from the key in S3 event and batch modes",
+			value: &c.Trigger.KeyPattern},
+
+		// Lambda
+		{Name: "lambda.runtime_api", Env: "AWS_LAMBDA_RUNTIME_API", Usage: "Lambda Runtime API (set by Lambda)", value: &c.Lambda.RuntimeAPI},
+
+		// Status broker
+		{Name: "status.broker", Env: "STATUS_BROKER", Default: "sns", Usage: "status broker: sns, sqs, webhook, nats or file", value: &c.Status.Broker},
+		{Name: "status.broker_url", Env: "STATUS_BROKER_URL", LegacyEnv: "SNS_TOPIC_ARN", URL: true,
+			Usage: "destination of the status events", value: &c.Status.BrokerURL},
+		{Name: "status.event_format", Env: "STATUS_EVENT_FORMAT", Default: "cloudevents",
+			Usage: "status event format: cloudevents or legacy (numeric IDs only)", value: &c.Status.EventFormat},
+		{Name: "status.event_source", Env: "STATUS_EVENT_SOURCE", Default: "/video-processor-job",
+			Usage: "CloudEvents source attribute", value: &c.Status.EventSource},
+
+		// Result callback
+		{Name: "callback.url", Env: "CALLBACK_URL", URL: true, Usage: "endpoint notified with the final result", value: &c.Callback.URL},
+		{Name: "callback.secret", Env: "CALLBACK_SECRET", Secret: true, Usage: "HMAC-SHA256 key signing the callback", value: &c.Callback.Secret},
+		{Name: "callback.allowed_hosts", Env: "CALLBACK_ALLOWED_HOSTS", Usage: "comma-separated hosts callbacks may be sent to", value: &c.Callback.AllowedHosts},
+		{Name: "callback.insecure_hosts", Env: "CALLBACK_INSECURE_HOSTS", Usage: "comma-separated allowed hosts callbacks may be sent to over plain http", value: &c.Callback.InsecureHosts},
+		{Name: "callback.max_attempts", Env: "CALLBACK_MAX_ATTEMPTS", Default: "3", Usage: "callback delivery attempts", value: &c.Callback.MaxAttempts},
+
+		// Status outbox
+		{Name: "outbox.path", Env: "STATUS_OUTBOX_PATH",
+			Usage: "durable outbox of status events, an absolute path on a persistent volume; empty publishes directly",
+			value: &c.Outbox.Path},
+		{Name: "outbox.max_attempts", Env: "STATUS_OUTBOX_MAX_ATTEMPTS", Default: "5",
+			Usage: "publish attempts before an event is left for the next run", value: &c.Outbox.MaxAttempts},
+
+		// FFmpeg
+		{Name: "ffmpeg.segment_concurrency", Env: "FFMPEG_SEGMENT_CONCURRENCY", Default: "1",
+			Usage: "ffmpeg processes run concurrently for one video", value: &c.FFmpeg.SegmentConcurrency},
+		{Name: "ffmpeg.min_segment_duration", Env: "FFMPEG_SEGMENT_MIN_DURATION", Default: "60",
+			Usage: "shortest video, in seconds, split into parallel segments", value: &c.FFmpeg.MinSegmentDuration},
+
+		// Workspace
+		{Name: "workspace.dir", Env: "WORKSPACE_DIR",
+			Usage: "directory the per-job workspaces are created in; empty is the system temp directory", value: &c.Workspace.Dir},
+
+		// Source cache
+		{Name: "source_cache.dir", Env: "SOURCE_CACHE_DIR",
+			Usage: "directory downloaded videos are cached in by ETag; empty disables the cache", value: &c.SourceCache.Dir},
+		{Name: "source_cache.max_bytes", Env: "SOURCE_CACHE_MAX_BYTES", Default: "10737418240",
+			Usage: "size of the cached videos, in bytes, above which the least recently used are evicted", value: &c.SourceCache.MaxBytes},
+
+		// Termination
+		{Name: "termination.message_path", Env: "TERMINATION_MESSAGE_PATH", Default: "/dev/termination-log",
+			Usage: "existing file the JSON result is written to; empty disables it", value: &c.Termination.MessagePath},
+
+		// Metrics
+		{Name: "metrics.pushgateway_url", Env: "METRICS_PUSHGATEWAY_URL", URL: true,
+			Usage: "Pushgateway the metrics are pushed to when the job exits; empty disables it", value: &c.Metrics.PushgatewayURL},
+		{Name: "metrics.push_job", Env: "METRICS_PUSH_JOB", Default: "video-processor-job",
+			Usage: "job label of the pushed metrics", value: &c.Metrics.PushJob},
+		{Name: "metrics.listen_address", Env: "METRICS_LISTEN_ADDRESS",
+			Usage: "address serving /metrics in Lambda mode, e.g. :9090; empty disables it", value: &c.Metrics.ListenAddress},
+
+		// Tracing
+		{Name: "tracing.exporter", Env: "OTEL_TRACES_EXPORTER", Default: "none",
+			Usage: "where spans are exported: none, otlp, stdout or file", value: &c.Tracing.Exporter},
+		{Name: "tracing.endpoint", Env: "OTEL_EXPORTER_OTLP_ENDPOINT", URL: true,
+			Usage: "base URL of the OTLP/HTTP collector, e.g. http://collector:4318", value: &c.Tracing.Endpoint},
+		{Name: "tracing.file", Env: "OTEL_TRACES_FILE",
+			Usage: "file the spans are appended to with the file exporter", value: &c.Tracing.File},
+		{Name: "tracing.service_name", Env: "OTEL_SERVICE_NAME", Default: "video-processor-job",
+			Usage: "service name of the exported spans", value: &c.Tracing.ServiceName},
+		{Name: "tracing.traceparent", Env: "TRACEPARENT",
+			Usage: "W3C traceparent of the trace the job continues", value: &c.Tracing.TraceParent},
+
+		// Logs
+		{Name: "log.level", Env: "LOG_LEVEL", Default: "info", Usage: "lowest level logged: debug, info, warn or error", value: &c.Log.Level},
+		{Name: "log.format", Env: "LOG_FORMAT", Default: "json", Usage: "log format: json or text", value: &c.Log.Format},
+	}
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
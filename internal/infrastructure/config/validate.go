@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+// Validate checks the configuration and reports every problem in one *ConfigValidationError
+func (c *Config) Validate() error {
+	problems := &ConfigValidationError{}
+	c.validate(problems)
+	return problems.errOrNil()
+}
+
+func (c *Config) validate(problems *ConfigValidationError) {
+	settings := make(map[string]setting)
+	for _, s := range c.settings() {
+		settings[s.Name] = s
+	}
+	required := func(name, value string) {
+		if value == "" {
+			problems.missing(settings[name])
+		}
+	}
+	check := func(name string, ok bool, reason string, args ...any) {
+		if !ok {
+			problems.invalid(settings[name], fmt.Sprintf(reason, args...))
+		}
+	}
+
+	// Without static keys the default credential chain is used (web identity/IRSA, SSO, instance profiles...);
+	// a key without its secret is a misconfiguration rather than a request for the chain
+	if c.AWS.AccessKey == "" && c.AWS.SecretAccessKey != "" {
+		problems.missing(settings["aws.access_key_id"])
+	}
+	if c.AWS.AccessKey != "" && c.AWS.SecretAccessKey == "" {
+		problems.missing(settings["aws.secret_access_key"])
+	}
+	required("aws.region", c.AWS.Region)
+
+	// Batch, triggered, Lambda and job spec runs take the keys and IDs from the listing, the event, the invocation
+	// or the spec
+	if !c.diagnostics && !c.IsBatch() && !c.IsTriggered() && !c.IsLambda() && !c.HasJobSpec() {
+		required("video.key", c.Video.Key)
+		// Status events and callbacks identify the video by these IDs; there is no meaningful default
+		required("video.id", c.Video.Id)
+		required("video.user_id", c.Video.UserId)
+	}
+	required("video.bucket", c.Video.Bucket)
+	required("video.processed_bucket", c.Video.ProcessedBucket)
+	if _, err := entity.ParseIDFormat(c.Video.IdFormat); err != nil {
+		check("video.id_format", false, "%v", err)
+	}
+	check("video.export_format", c.Video.ExportFormat == "jpg" || c.Video.ExportFormat == "png",
+		"unsupported format %q (allowed: jpg, png)", c.Video.ExportFormat)
+	check("video.export_fps", c.Video.ExportFPS > 0, "must be greater than 0, got %v", c.Video.ExportFPS)
+	if _, err := entity.ParseRetentionPolicy(c.Video.RetentionPolicy); err != nil {
+		check("video.retention_policy", false, "%v", err)
+	}
+
+	check("job.spec_file", c.Job.Spec == "" || c.Job.SpecFile == "", "cannot be combined with %s", settings["job.spec"].label())
+	if c.HasJobSpec() {
+		name := "job.spec"
+		if c.Job.Spec == "" {
+			name = "job.spec_file"
+		}
+		check(name, !c.IsBatch() && !c.IsTriggered(), "cannot be combined with batch or S3 event mode")
+	}
+
+	check("batch.concurrency", c.Batch.Concurrency >= 1, "must be at least 1, got %d", c.Batch.Concurrency)
+	check("batch.max_temp_bytes", c.Batch.MaxTempBytes >= 0, "must not be negative, got %d", c.Batch.MaxTempBytes)
+
+	check("trigger.event_file", c.Trigger.Event == "" || c.Trigger.EventFile == "",
+		"cannot be combined with %s", settings["trigger.event"].label())
+	if c.Trigger.KeyPattern != "" {
+		if _, err := regexp.Compile(c.Trigger.KeyPattern); err != nil {
+			check("trigger.key_pattern", false, "%v", err)
+		}
+	}
+
+	if !c.diagnostics {
+		required("status.broker_url", c.Status.BrokerURL)
+	}
+	switch c.Status.Broker {
+	case "sns", "sqs", "webhook", "nats", "file":
+	default:
+		check("status.broker", false, "unsupported broker %q (allowed: sns, sqs, webhook, nats, file)", c.Status.Broker)
+	}
+	check("status.event_format", c.Status.EventFormat == "cloudevents" || c.Status.EventFormat == "legacy",
+		"unsupported format %q (allowed: cloudevents, legacy)", c.Status.EventFormat)
+	// The legacy format carries the IDs as integers
+	check("status.event_format", c.Status.EventFormat != "legacy" || c.Video.IdFormat == string(entity.IDFormatNumeric),
+		"legacy requires video.id_format (VIDEO_ID_FORMAT) numeric, got %q", c.Video.IdFormat)
+
+	if c.Callback.URL != "" {
+		u, err := url.Parse(c.Callback.URL)
+		check("callback.url", err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"expected an http(s) url")
+		// Receivers authenticate the result by its signature, so callbacks are never sent unsigned
+		check("callback.secret", c.Callback.Secret != "", "required with callback.url")
+	}
+	check("callback.max_attempts", c.Callback.MaxAttempts >= 1, "must be at least 1, got %d", c.Callback.MaxAttempts)
+	// Undelivered events must outlive the pod: a relative path lands in the container's ephemeral working directory
+	check("outbox.path", c.Outbox.Path == "" || filepath.IsAbs(c.Outbox.Path),
+		"must be an absolute path on a persistent volume, got %q", c.Outbox.Path)
+	check("outbox.max_attempts", c.Outbox.MaxAttempts >= 1, "must be at least 1, got %d", c.Outbox.MaxAttempts)
+
+	check("ffmpeg.segment_concurrency", c.FFmpeg.SegmentConcurrency >= 1,
+		"must be at least 1, got %d", c.FFmpeg.SegmentConcurrency)
+	check("ffmpeg.min_segment_duration", c.FFmpeg.MinSegmentDuration >= 0,
+		"must not be negative, got %v", c.FFmpeg.MinSegmentDuration)
+	check("source_cache.max_bytes", c.SourceCache.MaxBytes > 0, "must be greater than 0, got %d", c.SourceCache.MaxBytes)
+
+	if c.Metrics.PushgatewayURL != "" {
+		u, err := url.Parse(c.Metrics.PushgatewayURL)
+		check("metrics.pushgateway_url", err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"expected an http(s) url")
+		required("metrics.push_job", c.Metrics.PushJob)
+	}
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	case "file":
+		required("tracing.file", c.Tracing.File)
+	default:
+		check("tracing.exporter", false, "unsupported exporter %q (allowed: none, otlp, stdout, file)", c.Tracing.Exporter)
+	}
+	if c.Tracing.Endpoint != "" {
+		u, err := url.Parse(c.Tracing.Endpoint)
+		check("tracing.endpoint", err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			"expected an http(s) url")
+	}
+
+	if _, err := logger.ParseLevel(c.Log.Level); err != nil {
+		check("log.level", false, "unsupported level %q (allowed: debug, info, warn, error)", c.Log.Level)
+	}
+	check("log.format", c.Log.Format == logger.FormatJSON || c.Log.Format == logger.FormatText,
+		"unsupported format %q (allowed: json, text)", c.Log.Format)
+}
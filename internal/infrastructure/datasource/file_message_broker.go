@@ -0,0 +1,49 @@
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+)
+
+// FileMessageBroker appends every message as a line of a local JSON-lines file, which is handy for debugging
+type FileMessageBroker struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileMessageBroker creates a new file message broker writing to path
+func NewFileMessageBroker(path string) port.MessageBroker {
+	return &FileMessageBroker{path: path}
+}
+
+func (ds *FileMessageBroker) PublishMessage(ctx context.Context, message entity.Message) error {
+	var line bytes.Buffer
+	if err := json.Compact(&line, message.Body); err != nil {
+		return fmt.Errorf("message body is not valid JSON: %w", err)
+	}
+	line.WriteByte('\n')
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(ds.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create message file directory: %w", err)
+	}
+	file, err := os.OpenFile(ds.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open message file: %w", err)
+	}
+	if _, err := file.Write(line.Bytes()); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write message file: %w", err)
+	}
+	return file.Close()
+}
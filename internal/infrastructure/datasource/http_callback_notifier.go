@@ -0,0 +1,216 @@
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+)
+
+const (
+	// DefaultCallbackMaxAttempts is how many times a callback is sent before giving up
+	DefaultCallbackMaxAttempts = 3
+
+	// DefaultCallbackBackoff is the wait before the first retry, doubled on every attempt
+	DefaultCallbackBackoff = time.Second
+
+	// callbackTimeout bounds a single callback request
+	callbackTimeout = 10 * time.Second
+
+	// SignatureHeader carries "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>"
+	SignatureHeader = "X-Signature"
+
+	// SignatureTimestampHeader carries the Unix time the request was signed at, so receivers can reject replays
+	SignatureTimestampHeader = "X-Signature-Timestamp"
+)
+
+// errForbiddenAddress is returned when a callback host resolves to an address of the internal network
+var errForbiddenAddress = errors.New("callback address is not public")
+
+// CallbackConfig configures the callback notifier
+type CallbackConfig struct {
+	// AllowedHosts lists the hosts callbacks may be sent to; "*.example.com" matches any subdomain.
+	// An empty list rejects every callback.
+	AllowedHosts []string
+	// InsecureHosts lists the allowed hosts callbacks may be sent to over plain http, e.g. in a development cluster;
+	// every other host requires https
+	InsecureHosts []string
+	MaxAttempts   int
+	Backoff       time.Duration
+}
+
+// HTTPCallbackNotifier delivers signed callbacks with HTTP POST requests
+type HTTPCallbackNotifier struct {
+	client *http.Client
+	config CallbackConfig
+	now    func() time.Time
+}
+
+// NewHTTPCallbackNotifier creates a callback notifier. Redirects are never followed,
+// so an allowed host cannot bounce the request to an internal address.
+// A nil client uses one that refuses to connect to private, loopback and link-local addresses, so an allowed host
+// cannot resolve to an internal service either.
+func NewHTTPCallbackNotifier(client *http.Client, config CallbackConfig) port.CallbackNotifier {
+	if client == nil {
+		client = &http.Client{Transport: newPublicTransport(), Timeout: callbackTimeout}
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = DefaultCallbackMaxAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = DefaultCallbackBackoff
+	}
+	return &HTTPCallbackNotifier{
+		client: &noRedirects,
+		config: config,
+		now:    time.Now,
+	}
+}
+
+func (n *HTTPCallbackNotifier) Notify(ctx context.Context, rawURL, secret string, payload []byte) (entity.CallbackDelivery, error) {
+	var delivery entity.CallbackDelivery
+
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Hostname() == "" {
+		return delivery, domain.NewInvalidInputError(fmt.Sprintf("invalid callback url %q", rawURL))
+	}
+	if !n.hostAllowed(target.Hostname()) {
+		return delivery, domain.NewInvalidInputError(fmt.Sprintf("callback host %q is not allowed", target.Hostname()))
+	}
+	if target.Scheme != "https" && !matchHost(n.config.InsecureHosts, target.Hostname()) {
+		return delivery, domain.NewInvalidInputError(fmt.Sprintf("callback host %q requires https", target.Hostname()))
+	}
+	// The receiver must be able to authenticate the result, so a callback is never sent unsigned
+	if secret == "" {
+		return delivery, domain.NewInvalidInputError("callback secret is required")
+	}
+
+	backoff := n.config.Backoff
+	for {
+		delivery.Attempts++
+		statusCode, retryable, err := n.send(ctx, target.String(), secret, payload)
+		delivery.StatusCode = statusCode
+		if err == nil {
+			return delivery, nil
+		}
+		if !retryable || delivery.Attempts >= n.config.MaxAttempts {
+			return delivery, err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return delivery, fmt.Errorf("callback cancelled: %w", ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+// send performs one signed request and reports whether a failure is worth retrying
+func (n *HTTPCallbackNotifier) send(ctx context.Context, target, secret string, payload []byte) (int, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create callback request: %w", err)
+	}
+	timestamp := strconv.FormatInt(n.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureTimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, SignPayload(secret, timestamp, payload))
+
+	resp, err := n.client.Do(req)
+	if errors.Is(err, errForbiddenAddress) {
+		return 0, false, domain.NewInvalidInputError(err.Error())
+	}
+	if err != nil {
+		return 0, true, fmt.Errorf("failed to call callback: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return resp.StatusCode, false, nil
+	}
+	retryable := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout
+	return resp.StatusCode, retryable, fmt.Errorf("callback responded with status %d", resp.StatusCode)
+}
+
+func (n *HTTPCallbackNotifier) hostAllowed(host string) bool {
+	return matchHost(n.config.AllowedHosts, host)
+}
+
+// matchHost reports whether host is one of hosts; "*.example.com" matches any subdomain
+func matchHost(hosts []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range hosts {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if suffix, ok := strings.CutPrefix(allowed, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// newPublicTransport returns a transport whose dialer checks every address the host resolves to before connecting,
+// so a DNS answer cannot point an allowed host at the internal network. Proxies are not used, as the dialer would
+// only check the address of the proxy.
+func newPublicTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: callbackTimeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", errForbiddenAddress, address)
+			}
+			if !publicAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errForbiddenAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// publicAddress reports whether addr is neither private, loopback, link-local, multicast nor unspecified
+func publicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !addr.IsPrivate() && !addr.IsLoopback() && !addr.IsLinkLocalUnicast() && !addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() && !addr.IsMulticast() && !addr.IsUnspecified()
+}
+
+// SignPayload returns the X-Signature value of a payload signed at timestamp
+func SignPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,214 @@
+package datasource
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+)
+
+func TestHTTPCallbackNotifier(t *testing.T) {
+	newNotifier := func(srv *httptest.Server, hosts ...string) *HTTPCallbackNotifier {
+		if hosts == nil {
+			u, _ := url.Parse(srv.URL)
+			hosts = []string{u.Hostname()}
+		}
+		n := NewHTTPCallbackNotifier(srv.Client(), CallbackConfig{
+			AllowedHosts:  hosts,
+			InsecureHosts: hosts,
+			MaxAttempts:   3,
+			Backoff:       time.Millisecond,
+		})
+		return n.(*HTTPCallbackNotifier)
+	}
+
+	t.Run("signs_payload_with_timestamp", func(t *testing.T) {
+		r := require.New(t)
+		var body []byte
+		var headers http.Header
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			body, _ = io.ReadAll(req.Body)
+			headers = req.Header
+		}))
+		defer srv.Close()
+
+		n := newNotifier(srv)
+		n.now = func() time.Time { return time.Unix(1700000000, 0) }
+		payload := []byte(`{"success":true}`)
+
+		delivery, err := n.Notify(context.Background(), srv.URL+"/hook", "s3cr3t", payload)
+		r.NoError(err)
+		r.Equal(1, delivery.Attempts)
+		r.Equal(http.StatusOK, delivery.StatusCode)
+		r.Equal(payload, body)
+		r.Equal("1700000000", headers.Get(SignatureTimestampHeader))
+		// sha256=HMAC-SHA256("s3cr3t", "1700000000." + body)
+		r.Equal(SignPayload("s3cr3t", "1700000000", payload), headers.Get(SignatureHeader))
+		r.NotEqual(SignPayload("s3cr3t", strconv.Itoa(1700000001), payload), headers.Get(SignatureHeader))
+		r.Regexp(`^sha256=[0-9a-f]{64}$`, headers.Get(SignatureHeader))
+	})
+
+	t.Run("missing_secret_is_rejected", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			t.Error("unsigned callbacks must not be sent")
+		}))
+		defer srv.Close()
+
+		delivery, err := newNotifier(srv).Notify(context.Background(), srv.URL, "", []byte(`{}`))
+		var inv *domain.InvalidInputError
+		require.ErrorAs(t, err, &inv)
+		require.Zero(t, delivery.Attempts)
+	})
+
+	t.Run("http_requires_an_insecure_host", func(t *testing.T) {
+		n := NewHTTPCallbackNotifier(nil, CallbackConfig{AllowedHosts: []string{"hooks.example.com"}})
+		delivery, err := n.Notify(context.Background(), "http://hooks.example.com/hook", "k", []byte(`{}`))
+		var inv *domain.InvalidInputError
+		require.ErrorAs(t, err, &inv)
+		require.ErrorContains(t, err, "requires https")
+		require.Zero(t, delivery.Attempts)
+	})
+
+	t.Run("internal_addresses_are_refused", func(t *testing.T) {
+		r := require.New(t)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			t.Error("callbacks to internal addresses must not be sent")
+		}))
+		defer srv.Close()
+		u, _ := url.Parse(srv.URL)
+		hosts := []string{u.Hostname()}
+
+		// The default client dials the resolved address only when it is public
+		n := NewHTTPCallbackNotifier(nil, CallbackConfig{AllowedHosts: hosts, InsecureHosts: hosts, MaxAttempts: 3})
+		delivery, err := n.Notify(context.Background(), srv.URL, "k", []byte(`{}`))
+		var inv *domain.InvalidInputError
+		r.ErrorAs(err, &inv)
+		r.Equal(1, delivery.Attempts)
+
+		for address, public := range map[string]bool{
+			"93.184.216.34":   true,
+			"2606:4700::1111": true,
+			"127.0.0.1":       false,
+			"10.1.2.3":        false,
+			"172.16.0.1":      false,
+			"192.168.1.1":     false,
+			"169.254.169.254": false,
+			"0.0.0.0":         false,
+			"::1":             false,
+			"fe80::1":         false,
+			"fd00::1":         false,
+			"::ffff:10.0.0.1": false,
+		} {
+			r.Equal(public, publicAddress(netip.MustParseAddr(address)), address)
+		}
+	})
+
+	t.Run("retries_server_errors", func(t *testing.T) {
+		r := require.New(t)
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		delivery, err := newNotifier(srv).Notify(context.Background(), srv.URL, "k", []byte(`{}`))
+		r.NoError(err)
+		r.Equal(3, delivery.Attempts)
+		r.Equal(http.StatusNoContent, delivery.StatusCode)
+	})
+
+	t.Run("gives_up_after_max_attempts", func(t *testing.T) {
+		r := require.New(t)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		delivery, err := newNotifier(srv).Notify(context.Background(), srv.URL, "k", []byte(`{}`))
+		r.ErrorContains(err, "503")
+		r.Equal(3, delivery.Attempts)
+	})
+
+	t.Run("client_errors_are_not_retried", func(t *testing.T) {
+		r := require.New(t)
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer srv.Close()
+
+		delivery, err := newNotifier(srv).Notify(context.Background(), srv.URL, "k", []byte(`{}`))
+		r.Error(err)
+		r.Equal(1, delivery.Attempts)
+		r.Equal(int32(1), calls.Load())
+	})
+
+	t.Run("redirects_are_not_followed", func(t *testing.T) {
+		r := require.New(t)
+		var internalCalls atomic.Int32
+		internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) { internalCalls.Add(1) }))
+		defer internal.Close()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			http.Redirect(w, req, internal.URL, http.StatusFound)
+		}))
+		defer srv.Close()
+
+		delivery, err := newNotifier(srv).Notify(context.Background(), srv.URL, "k", []byte(`{}`))
+		r.Error(err)
+		r.Equal(http.StatusFound, delivery.StatusCode)
+		r.Zero(internalCalls.Load())
+	})
+
+	t.Run("host_allowlist", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			t.Error("rejected callbacks must not be sent")
+		}))
+		defer srv.Close()
+		n := newNotifier(srv, "hooks.example.com", "*.partner.io")
+
+		for _, target := range []string{
+			srv.URL,
+			"http://169.254.169.254/latest/meta-data",
+			"https://evil.example.com/hook",
+			"https://partner.io/hook",
+			"ftp://hooks.example.com/hook",
+			"://bad",
+		} {
+			t.Run(target, func(t *testing.T) {
+				delivery, err := n.Notify(context.Background(), target, "k", []byte(`{}`))
+				var inv *domain.InvalidInputError
+				require.ErrorAs(t, err, &inv)
+				require.Zero(t, delivery.Attempts)
+			})
+		}
+
+		r := require.New(t)
+		r.True(n.hostAllowed("hooks.example.com"))
+		r.True(n.hostAllowed("HOOKS.example.com."))
+		r.True(n.hostAllowed("eu.partner.io"))
+		r.False(n.hostAllowed("partner.io"))
+		r.False(n.hostAllowed("notpartner.io"))
+	})
+
+	t.Run("empty_allowlist_rejects_everything", func(t *testing.T) {
+		n := NewHTTPCallbackNotifier(nil, CallbackConfig{})
+		_, err := n.Notify(context.Background(), "https://hooks.example.com", "k", []byte(`{}`))
+		var inv *domain.InvalidInputError
+		require.ErrorAs(t, err, &inv)
+	})
+}
@@ -0,0 +1,253 @@
+//go:build integration
+
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+)
+
+// The integration suite runs against S3/SNS/SQS compatible stand-ins (MinIO, LocalStack) selected with the same
+// variables as the job: S3_ENDPOINT_URL, S3_FORCE_PATH_STYLE, SNS_ENDPOINT_URL and SQS_ENDPOINT_URL. Credentials
+// come from the default chain, e.g. AWS_ACCESS_KEY_ID=test AWS_SECRET_ACCESS_KEY=test for LocalStack.
+// Every test creates its own buckets, topics and queues.
+
+func integrationEndpoint(t *testing.T, names ...string) []string {
+	endpoints := make([]string, 0, len(names))
+	for _, name := range names {
+		endpoint := os.Getenv(name)
+		if endpoint == "" {
+			t.Skipf("%s not set", name)
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+func integrationAWSConfig(t *testing.T) aws.Config {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithRegion(region))
+	require.NoError(t, err)
+	return awsCfg
+}
+
+// integrationName returns a bucket, topic or queue name unique to this run
+func integrationName(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
+}
+
+func createBucket(t *testing.T, client *s3.Client, name string) {
+	ctx := context.Background()
+	_, err := client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(name)})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		objects, err := client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{Bucket: aws.String(name)})
+		if err == nil {
+			for _, obj := range objects.Contents {
+				_, _ = client.DeleteObject(ctx, &s3.DeleteObjectInput{Bucket: aws.String(name), Key: obj.Key})
+			}
+		}
+		_, _ = client.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: aws.String(name)})
+	})
+}
+
+func TestIntegration_S3StorageDataSource(t *testing.T) {
+	endpoint := integrationEndpoint(t, "S3_ENDPOINT_URL")[0]
+	r := require.New(t)
+	ctx := context.Background()
+
+	client := NewS3Client(integrationAWSConfig(t), endpoint, os.Getenv("S3_FORCE_PATH_STYLE") == "true")
+	videoBucket, processedBucket := integrationName("videos"), integrationName("processed")
+	createBucket(t, client, videoBucket)
+	createBucket(t, client, processedBucket)
+	ds := NewS3StorageDataSource(client, videoBucket, processedBucket)
+
+	key := "uploads/u-1/my video.mp4"
+	content := []byte("not really a video")
+	_, err := client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:   aws.String(videoBucket),
+		Key:      aws.String(key),
+		Body:     bytes.NewReader(content),
+		Metadata: map[string]string{"Video-Id": "v-1", "User-Id": "u-1"},
+	})
+	r.NoError(err)
+
+	// stat and list
+	object, err := ds.StatVideo(ctx, key)
+	r.NoError(err)
+	r.Equal(int64(len(content)), object.Size)
+	r.Equal(map[string]string{"video-id": "v-1", "user-id": "u-1"}, object.Metadata)
+	r.NotEmpty(object.Version.ETag)
+
+	listed, err := ds.ListVideos(ctx, "uploads/")
+	r.NoError(err)
+	r.Len(listed, 1)
+	r.Equal(key, listed[0].Key)
+
+	_, err = ds.StatVideo(ctx, "missing.mp4")
+	var nf *domain.NotFoundError
+	r.ErrorAs(err, &nf)
+
+	// pinned downloads
+	body, version, err := ds.DownloadVideo(ctx, key, entity.ObjectVersion{ETag: object.Version.ETag})
+	r.NoError(err)
+	data, err := io.ReadAll(body)
+	r.NoError(body.Close())
+	r.NoError(err)
+	r.Equal(content, data)
+	r.Equal(object.Version.ETag, version.ETag)
+
+	_, _, err = ds.DownloadVideo(ctx, key, entity.ObjectVersion{ETag: `"0123456789abcdef0123456789abcdef"`})
+	var conflict *domain.ConflictError
+	r.ErrorAs(err, &conflict)
+
+	// processed files
+	frames := []byte("zip")
+	uploaded, err := ds.UploadProcessedFile(ctx, "u-1/v-1/frames.zip", bytes.NewReader(frames), "application/zip", int64(len(frames)))
+	r.NoError(err)
+	size, err := ds.StatProcessedFile(ctx, uploaded)
+	r.NoError(err)
+	r.Equal(int64(len(frames)), size)
+	r.NoError(ds.DeleteProcessedFile(ctx, uploaded))
+	_, err = ds.StatProcessedFile(ctx, uploaded)
+	r.ErrorAs(err, &nf)
+
+	// retention operations
+	r.NoError(ds.TagVideo(ctx, key, object.Version, map[string]string{"processed": "true"}))
+	tags, err := client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{Bucket: aws.String(videoBucket), Key: aws.String(key)})
+	r.NoError(err)
+	r.Len(tags.TagSet, 1)
+	r.Equal("processed", aws.ToString(tags.TagSet[0].Key))
+
+	r.NoError(ds.CopyVideo(ctx, key, object.Version, processedBucket, "archive/"+key))
+	_, err = client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(processedBucket), Key: aws.String("archive/" + key)})
+	r.NoError(err)
+
+	r.NoError(ds.DeleteVideo(ctx, key, entity.ObjectVersion{}))
+	_, err = ds.StatVideo(ctx, key)
+	r.ErrorAs(err, &nf)
+}
+
+// createQueue creates a queue and returns its URL and ARN
+func createQueue(t *testing.T, client *sqs.Client) (string, string) {
+	ctx := context.Background()
+	created, err := client.CreateQueue(ctx, &sqs.CreateQueueInput{QueueName: aws.String(integrationName("status"))})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = client.DeleteQueue(ctx, &sqs.DeleteQueueInput{QueueUrl: created.QueueUrl}) })
+
+	attributes, err := client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+		QueueUrl:       created.QueueUrl,
+		AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNameQueueArn},
+	})
+	require.NoError(t, err)
+	return aws.ToString(created.QueueUrl), attributes.Attributes[string(sqstypes.QueueAttributeNameQueueArn)]
+}
+
+// receiveOne waits for a single message on the queue
+func receiveOne(t *testing.T, client *sqs.Client, queueURL string) sqstypes.Message {
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		out, err := client.ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(queueURL),
+			MaxNumberOfMessages:   1,
+			WaitTimeSeconds:       2,
+			MessageAttributeNames: []string{"All"},
+		})
+		require.NoError(t, err)
+		if len(out.Messages) > 0 {
+			return out.Messages[0]
+		}
+	}
+	t.Fatal("no message received")
+	return sqstypes.Message{}
+}
+
+func TestIntegration_SQSMessageBroker(t *testing.T) {
+	endpoint := integrationEndpoint(t, "SQS_ENDPOINT_URL")[0]
+	r := require.New(t)
+	awsCfg := integrationAWSConfig(t)
+	client := sqs.NewFromConfig(awsCfg, func(o *sqs.Options) { o.BaseEndpoint = aws.String(endpoint) })
+	queueURL, _ := createQueue(t, client)
+
+	broker, err := NewMessageBroker(BrokerConfig{Kind: BrokerSQS, URL: queueURL, SQSEndpoint: endpoint}, awsCfg)
+	r.NoError(err)
+	r.NoError(broker.PublishMessage(context.Background(), entity.Message{
+		ID:         "m-1",
+		Body:       []byte(`{"status":"COMPLETED"}`),
+		Attributes: map[string]string{"status": "COMPLETED"},
+	}))
+
+	msg := receiveOne(t, client, queueURL)
+	r.JSONEq(`{"status":"COMPLETED"}`, aws.ToString(msg.Body))
+	r.Equal("COMPLETED", aws.ToString(msg.MessageAttributes["status"].StringValue))
+}
+
+// createTopic creates a topic delivering raw messages to the queue and returns its ARN
+func createTopic(t *testing.T, awsCfg aws.Config, endpoint, queueARN string) string {
+	ctx := context.Background()
+	client := sns.NewFromConfig(awsCfg, func(o *sns.Options) { o.BaseEndpoint = aws.String(endpoint) })
+	created, err := client.CreateTopic(ctx, &sns.CreateTopicInput{Name: aws.String(integrationName("status"))})
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = client.DeleteTopic(ctx, &sns.DeleteTopicInput{TopicArn: created.TopicArn}) })
+
+	_, err = client.Subscribe(ctx, &sns.SubscribeInput{
+		TopicArn:   created.TopicArn,
+		Protocol:   aws.String("sqs"),
+		Endpoint:   aws.String(queueARN),
+		Attributes: map[string]string{"RawMessageDelivery": "true"},
+	})
+	require.NoError(t, err)
+	return aws.ToString(created.TopicArn)
+}
+
+func TestIntegration_SNSMessageBroker(t *testing.T) {
+	endpoints := integrationEndpoint(t, "SNS_ENDPOINT_URL", "SQS_ENDPOINT_URL")
+	r := require.New(t)
+	ctx := context.Background()
+	awsCfg := integrationAWSConfig(t)
+
+	// the topic is observed through a raw-delivery SQS subscription
+	sqsClient := sqs.NewFromConfig(awsCfg, func(o *sqs.Options) { o.BaseEndpoint = aws.String(endpoints[1]) })
+	queueURL, queueARN := createQueue(t, sqsClient)
+
+	topicARN := createTopic(t, awsCfg, endpoints[0], queueARN)
+
+	broker, err := NewMessageBroker(BrokerConfig{Kind: BrokerSNS, URL: topicARN, SNSEndpoint: endpoints[0]}, awsCfg)
+	r.NoError(err)
+	r.NoError(broker.PublishMessage(ctx, entity.Message{
+		ID:         "m-1",
+		Body:       []byte(`{"status":"PROCESSING"}`),
+		Attributes: map[string]string{"status": "PROCESSING", "video_id": "v-1"},
+	}))
+
+	msg := receiveOne(t, sqsClient, queueURL)
+	body := aws.ToString(msg.Body)
+	// LocalStack may ignore RawMessageDelivery; unwrap the SNS envelope in that case
+	if strings.Contains(body, `"TopicArn"`) {
+		var envelope struct{ Message string }
+		r.NoError(json.Unmarshal([]byte(body), &envelope))
+		body = envelope.Message
+	}
+	r.JSONEq(`{"status":"PROCESSING"}`, body)
+}
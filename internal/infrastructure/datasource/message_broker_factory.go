@@ -0,0 +1,137 @@
+package datasource
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+)
+
+// Supported status broker backends
+const (
+	BrokerSNS     = "sns"
+	BrokerSQS     = "sqs"
+	BrokerWebhook = "webhook"
+	BrokerNATS    = "nats"
+	BrokerFile    = "file"
+)
+
+// BrokerConfig selects the status broker backend and its destination.
+// URL is backend specific:
+//
+//	sns:     topic ARN (arn:aws:sns:<region>:<account>:<topic>)
+//	sqs:     queue URL (https://sqs.<region>.amazonaws.com/<account>/<queue>)
+//	webhook: endpoint (http:// or https://)
+//	nats:    server and subject (nats://[user:pass@]host:port/<subject>)
+//	file:    local path (file:///path/to/events.jsonl or /path/to/events.jsonl)
+//
+// SNSEndpoint and SQSEndpoint override the AWS endpoints of the sns and sqs backends, e.g. for LocalStack.
+type BrokerConfig struct {
+	Kind        string
+	URL         string
+	SNSEndpoint string
+	SQSEndpoint string
+}
+
+// NewMessageBroker creates the message broker selected by config
+func NewMessageBroker(config BrokerConfig, awsCfg aws.Config) (port.MessageBroker, error) {
+	kind := strings.ToLower(strings.TrimSpace(config.Kind))
+	if kind == "" {
+		kind = BrokerSNS
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("%s broker requires a destination url", kind)
+	}
+
+	switch kind {
+	case BrokerSNS:
+		if !strings.HasPrefix(config.URL, "arn:") {
+			return nil, fmt.Errorf("sns broker requires a topic arn, got %q", config.URL)
+		}
+		client := sns.NewFromConfig(awsCfg, func(o *sns.Options) {
+			if config.SNSEndpoint != "" {
+				o.BaseEndpoint = aws.String(config.SNSEndpoint)
+			}
+		})
+		return NewSnsMessageBroker(client, config.URL), nil
+
+	case BrokerSQS:
+		u, err := parseHTTPURL(config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("sqs broker requires a queue url: %w", err)
+		}
+		client := sqs.NewFromConfig(awsCfg, func(o *sqs.Options) {
+			if config.SQSEndpoint != "" {
+				o.BaseEndpoint = aws.String(config.SQSEndpoint)
+			}
+		})
+		return NewSqsMessageBroker(client, u.String()), nil
+
+	case BrokerWebhook:
+		u, err := parseHTTPURL(config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("webhook broker requires an http url: %w", err)
+		}
+		return NewWebhookMessageBroker(nil, u.String()), nil
+
+	case BrokerNATS:
+		server, subject, err := parseNatsURL(config.URL)
+		if err != nil {
+			return nil, err
+		}
+		return NewNatsMessageBroker(server, subject), nil
+
+	case BrokerFile:
+		path := config.URL
+		if strings.HasPrefix(path, "file://") {
+			u, err := url.Parse(path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid file broker url: %w", err)
+			}
+			path = u.Path
+		}
+		if path == "" {
+			return nil, fmt.Errorf("file broker requires a path, got %q", config.URL)
+		}
+		return NewFileMessageBroker(path), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported status broker %q (allowed: sns, sqs, webhook, nats, file)", config.Kind)
+	}
+}
+
+func parseHTTPURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("expected http(s)://host/..., got %q", raw)
+	}
+	return u, nil
+}
+
+// parseNatsURL splits nats://host:port/subject into the server url and the subject
+func parseNatsURL(raw string) (string, string, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid nats broker url: %w", err)
+	}
+	if (u.Scheme != "nats" && u.Scheme != "tls") || u.Host == "" {
+		return "", "", fmt.Errorf("nats broker requires nats://host:port/<subject>, got %q", raw)
+	}
+	subject := strings.TrimPrefix(u.Path, "/")
+	if subject == "" || strings.ContainsAny(subject, "/ ") {
+		return "", "", fmt.Errorf("nats broker requires a subject in the url path, got %q", raw)
+	}
+
+	server := *u
+	server.Path = ""
+	server.RawPath = ""
+	return server.String(), subject, nil
+}
@@ -0,0 +1,86 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+)
+
+// natsFlushTimeout bounds the wait for the server acknowledgement when the caller sets no deadline
+const natsFlushTimeout = 10 * time.Second
+
+// NATSMessageBroker implements message publishing operations using a NATS subject
+type NATSMessageBroker struct {
+	mu      sync.Mutex
+	url     string
+	subject string
+	conn    *nats.Conn
+}
+
+// NewNatsMessageBroker creates a new NATS message broker. The connection is opened on the first publish,
+// so an unreachable server fails the publish (and lets the outbox retry it) instead of the job startup.
+func NewNatsMessageBroker(url, subject string) port.MessageBroker {
+	return &NATSMessageBroker{
+		url:     url,
+		subject: subject,
+	}
+}
+
+func (ds *NATSMessageBroker) PublishMessage(ctx context.Context, message entity.Message) error {
+	conn, err := ds.connect()
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(ds.subject)
+	msg.Data = message.Body
+	for name, value := range message.Attributes {
+		msg.Header.Set(name, value)
+	}
+	// JetStream de-duplicates messages with the same Nats-Msg-Id
+	msg.Header.Set(nats.MsgIdHdr, message.ID)
+	if err := conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("failed to publish to nats: %w", err)
+	}
+	// Flush waits for the server to acknowledge everything published so far
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, natsFlushTimeout)
+		defer cancel()
+	}
+	if err := conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to flush nats connection: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection, if one was opened
+func (ds *NATSMessageBroker) Close() error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.conn != nil {
+		ds.conn.Close()
+		ds.conn = nil
+	}
+	return nil
+}
+
+func (ds *NATSMessageBroker) connect() (*nats.Conn, error) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	if ds.conn != nil && !ds.conn.IsClosed() {
+		return ds.conn, nil
+	}
+	conn, err := nats.Connect(ds.url, nats.Name("video-processor-job"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	ds.conn = conn
+	return conn, nil
+}
@@ -0,0 +1,445 @@
+package datasource
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+const (
+	// DefaultOutboxMaxAttempts is how many times an event is published before it is left for the next replay
+	DefaultOutboxMaxAttempts = 5
+
+	// DefaultOutboxBackoff is the wait before the first retry, doubled on every attempt
+	DefaultOutboxBackoff = time.Second
+
+	// deliveredRetention is how long delivered markers are kept to suppress replays of an event across runs
+	deliveredRetention = 24 * time.Hour
+
+	// outboxQueueSize is the number of events waiting for delivery beyond the replayed ones
+	outboxQueueSize = 256
+
+	outboxRecordEvent     = "event"
+	outboxRecordDelivered = "delivered"
+)
+
+// OutboxConfig configures the durable outbox
+type OutboxConfig struct {
+	Path        string
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+// outboxRecord is one line of the outbox file: either an event or the marker of its delivery
+type outboxRecord struct {
+	Type       string            `json:"type"`
+	ID         string            `json:"id"`
+	Body       string            `json:"body,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	GroupID    string            `json:"group_id,omitempty"`
+	Time       time.Time         `json:"time"`
+}
+
+func newOutboxEvent(message entity.Message, at time.Time) outboxRecord {
+	return outboxRecord{
+		Type:       outboxRecordEvent,
+		ID:         message.ID,
+		Body:       string(message.Body),
+		Attributes: message.Attributes,
+		GroupID:    message.GroupID,
+		Time:       at.UTC(),
+	}
+}
+
+// outboxEntry is the delivery state of an event known to the outbox
+type outboxEntry struct {
+	message   entity.Message
+	delivered bool
+	// done is closed when the running delivery ends; nil when none is running
+	done chan struct{}
+	// err is why the last delivery failed
+	err error
+}
+
+// OutboxMessageBroker decorates a MessageBroker with a durable JSON-lines outbox. Every event is appended to the
+// outbox before PublishMessage returns and is then delivered asynchronously with retries, unless the message awaits
+// its delivery. Events that are still undelivered when the process exits are replayed on the next start. Events are
+// de-duplicated by their ID.
+type OutboxMessageBroker struct {
+	next   port.MessageBroker
+	config OutboxConfig
+	logger logger.Logger
+
+	mu      sync.Mutex
+	file    *os.File
+	entries map[string]*outboxEntry
+	closed  bool
+	// queued counts the events waiting for or in delivery; idle is closed whenever it drops to zero
+	queued int
+	idle   chan struct{}
+
+	queue  chan *outboxEntry
+	done   chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewOutboxMessageBroker opens (or creates) the outbox at config.Path, compacts it and starts replaying undelivered events
+func NewOutboxMessageBroker(next port.MessageBroker, config OutboxConfig, logger logger.Logger) (*OutboxMessageBroker, error) {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = DefaultOutboxMaxAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = DefaultOutboxBackoff
+	}
+	log := logger.With("outbox_path", config.Path)
+
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	pending, delivered, err := readOutbox(config.Path, log)
+	if err != nil {
+		return nil, err
+	}
+	if err := compactOutbox(config.Path, pending, delivered, time.Now()); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(config.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open outbox: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &OutboxMessageBroker{
+		next:    next,
+		config:  config,
+		logger:  log,
+		file:    file,
+		entries: make(map[string]*outboxEntry, len(pending)+len(delivered)),
+		queue:   make(chan *outboxEntry, len(pending)+outboxQueueSize),
+		done:    make(chan struct{}),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+	for id := range delivered {
+		b.entries[id] = &outboxEntry{delivered: true}
+	}
+	b.idle = make(chan struct{})
+	close(b.idle)
+	for _, msg := range pending {
+		entry := &outboxEntry{message: msg}
+		b.entries[msg.ID] = entry
+		b.schedule(entry)
+	}
+	if len(pending) > 0 {
+		log.Info("Replaying undelivered outbox events", "count", len(pending))
+	}
+
+	go b.run()
+	return b, nil
+}
+
+// PublishMessage durably records the event and schedules its delivery. Events whose ID is already in the outbox
+// are not recorded again; events without an ID get a new one. A message awaiting its delivery returns once the
+// next broker acknowledged it, or with the error that made the outbox give up on it; publishing it again retries
+// an event that was given up on.
+func (b *OutboxMessageBroker) PublishMessage(ctx context.Context, message entity.Message) error {
+	if message.ID == "" {
+		message.ID = uuid.NewString()
+	}
+	log := b.logger.WithContext(ctx).With("message_id", message.ID)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return errors.New("outbox is closed")
+	}
+	entry, ok := b.entries[message.ID]
+	switch {
+	case !ok:
+		if err := b.append(newOutboxEvent(message, time.Now())); err != nil {
+			b.mu.Unlock()
+			return fmt.Errorf("failed to append event to outbox: %w", err)
+		}
+		entry = &outboxEntry{message: message}
+		b.entries[message.ID] = entry
+		b.schedule(entry)
+	case message.AwaitDelivery && !entry.delivered && entry.done == nil:
+		log.Info("Retrying undelivered outbox event")
+		b.schedule(entry)
+	default:
+		log.Info("Duplicate event suppressed by outbox")
+	}
+	done := entry.done
+	b.mu.Unlock()
+
+	if !message.AwaitDelivery {
+		return nil
+	}
+	if done != nil {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return fmt.Errorf("interrupted while awaiting event delivery, left for replay: %w", ctx.Err())
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if entry.delivered {
+		return nil
+	}
+	if entry.err != nil {
+		return fmt.Errorf("event not delivered, left for replay: %w", entry.err)
+	}
+	return errors.New("event not delivered, left for replay")
+}
+
+// Flush waits until every queued event was delivered or given up on, or ctx is done.
+// Unlike Close the outbox keeps accepting events; it suits processes frozen between runs, like Lambda.
+func (b *OutboxMessageBroker) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	idle := b.idle
+	b.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("outbox flush interrupted, pending events are left for replay: %w", ctx.Err())
+	}
+}
+
+// Close stops accepting events and waits for queued deliveries until ctx is done. It fails when an event is left
+// undelivered; such events stay in the outbox and are replayed on the next start.
+func (b *OutboxMessageBroker) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.queue)
+	b.mu.Unlock()
+
+	var drainErr error
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		drainErr = fmt.Errorf("outbox drain interrupted, pending events are left for replay: %w", ctx.Err())
+	}
+	b.cancel()
+	<-b.done
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if undelivered := b.undelivered(); drainErr == nil && undelivered > 0 {
+		drainErr = fmt.Errorf("%d outbox events left undelivered for replay", undelivered)
+	}
+	return errors.Join(drainErr, b.file.Close())
+}
+
+// undelivered counts the events not delivered yet; callers must hold b.mu
+func (b *OutboxMessageBroker) undelivered() int {
+	count := 0
+	for _, entry := range b.entries {
+		if !entry.delivered {
+			count++
+		}
+	}
+	return count
+}
+
+// run delivers queued events one at a time, preserving their order
+func (b *OutboxMessageBroker) run() {
+	defer close(b.done)
+	for entry := range b.queue {
+		err := b.deliver(entry.message)
+
+		b.mu.Lock()
+		b.finished(entry, err)
+		if b.queued--; b.queued == 0 {
+			close(b.idle)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// schedule hands an event to the worker; callers must hold b.mu or own b exclusively
+func (b *OutboxMessageBroker) schedule(entry *outboxEntry) {
+	entry.done = make(chan struct{})
+	select {
+	case b.queue <- entry:
+		if b.queued++; b.queued == 1 {
+			b.idle = make(chan struct{})
+		}
+	default:
+		// The event is durable; it is delivered by the next replay
+		b.logger.Warn("Outbox queue is full, event left for replay", "message_id", entry.message.ID)
+		b.finished(entry, errors.New("outbox queue is full"))
+	}
+}
+
+// finished records the outcome of a delivery and releases its waiters; callers must hold b.mu
+func (b *OutboxMessageBroker) finished(entry *outboxEntry, err error) {
+	entry.delivered = err == nil
+	entry.err = err
+	close(entry.done)
+	entry.done = nil
+}
+
+// deliver publishes an event with exponential backoff and records its delivery
+func (b *OutboxMessageBroker) deliver(message entity.Message) error {
+	log := b.logger.With("message_id", message.ID)
+	backoff := b.config.Backoff
+
+	for attempt := 1; ; attempt++ {
+		if b.ctx.Err() != nil {
+			return b.ctx.Err()
+		}
+		err := b.next.PublishMessage(b.ctx, message)
+		if err == nil {
+			break
+		}
+		if attempt >= b.config.MaxAttempts {
+			log.Error("Failed to deliver outbox event, left for replay", "error", err, "attempts", attempt)
+			return err
+		}
+		log.Warn("Failed to deliver outbox event, retrying", "error", err, "attempt", attempt, "backoff", backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-b.ctx.Done():
+			return b.ctx.Err()
+		}
+		backoff *= 2
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.append(outboxRecord{Type: outboxRecordDelivered, ID: message.ID, Time: time.Now().UTC()}); err != nil {
+		// The event was published; at worst it is published again on replay
+		log.Warn("Failed to mark outbox event as delivered", "error", err)
+		return nil
+	}
+	log.Info("Outbox event delivered")
+	return nil
+}
+
+// append writes a record and flushes it to disk; callers must hold b.mu
+func (b *OutboxMessageBroker) append(record outboxRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := b.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return b.file.Sync()
+}
+
+// readOutbox returns the undelivered events in order and the delivery time of every delivered event
+func readOutbox(path string, log logger.Logger) ([]entity.Message, map[string]time.Time, error) {
+	delivered := make(map[string]time.Time)
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, delivered, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open outbox: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var events []outboxRecord
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var record outboxRecord
+			if jsonErr := json.Unmarshal(line, &record); jsonErr != nil || record.ID == "" {
+				// A crash while appending leaves a truncated last line
+				log.Warn("Skipping unreadable outbox record", "error", jsonErr)
+			} else if record.Type == outboxRecordDelivered {
+				delivered[record.ID] = record.Time
+			} else {
+				events = append(events, record)
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read outbox: %w", err)
+		}
+	}
+
+	var pending []entity.Message
+	seen := make(map[string]struct{}, len(events))
+	for _, record := range events {
+		if _, ok := delivered[record.ID]; ok {
+			continue
+		}
+		if _, ok := seen[record.ID]; ok {
+			continue
+		}
+		seen[record.ID] = struct{}{}
+		pending = append(pending, entity.Message{
+			ID:         record.ID,
+			Body:       []byte(record.Body),
+			Attributes: record.Attributes,
+			GroupID:    record.GroupID,
+		})
+	}
+	return pending, delivered, nil
+}
+
+// compactOutbox atomically rewrites the outbox with the pending events and the recent delivered markers only
+func compactOutbox(path string, pending []entity.Message, delivered map[string]time.Time, now time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to compact outbox: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	for id, at := range delivered {
+		if now.Sub(at) > deliveredRetention {
+			continue
+		}
+		if err := encoder.Encode(outboxRecord{Type: outboxRecordDelivered, ID: id, Time: at}); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("failed to compact outbox: %w", err)
+		}
+	}
+	for _, msg := range pending {
+		if err := encoder.Encode(newOutboxEvent(msg, now)); err != nil {
+			_ = tmp.Close()
+			return fmt.Errorf("failed to compact outbox: %w", err)
+		}
+	}
+
+	if err := errors.Join(writer.Flush(), tmp.Sync(), tmp.Close()); err != nil {
+		return fmt.Errorf("failed to compact outbox: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to compact outbox: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+// fakeBroker records published messages and fails while fail is set
+type fakeBroker struct {
+	mu        sync.Mutex
+	fail      bool
+	attempts  int
+	published []entity.Message
+}
+
+func (f *fakeBroker) PublishMessage(ctx context.Context, message entity.Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.attempts++
+	if f.fail {
+		return errors.New("broker unreachable")
+	}
+	f.published = append(f.published, message)
+	return nil
+}
+
+func (f *fakeBroker) ids() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var ids []string
+	for _, m := range f.published {
+		ids = append(ids, m.ID)
+	}
+	return ids
+}
+
+func TestOutboxMessageBroker(t *testing.T) {
+	config := func(t *testing.T) OutboxConfig {
+		return OutboxConfig{Path: filepath.Join(t.TempDir(), "outbox", "events.jsonl"), MaxAttempts: 2, Backoff: time.Millisecond}
+	}
+
+	t.Run("delivers_and_suppresses_duplicates", func(t *testing.T) {
+		r := require.New(t)
+		cfg := config(t)
+		next := &fakeBroker{}
+
+		outbox, err := NewOutboxMessageBroker(next, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "a", Body: []byte(`{"status":"FINISHED"}`)}))
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "a", Body: []byte(`{"status":"FINISHED"}`)}))
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "b", Body: []byte(`{}`)}))
+		r.NoError(outbox.Close(context.Background()))
+		r.Equal([]string{"a", "b"}, next.ids())
+		r.Error(outbox.PublishMessage(context.Background(), entity.Message{ID: "c"}))
+
+		// delivered events are neither replayed nor published again after a restart
+		again := &fakeBroker{}
+		outbox, err = NewOutboxMessageBroker(again, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "a", Body: []byte(`{"status":"FINISHED"}`)}))
+		r.NoError(outbox.Close(context.Background()))
+		r.Empty(again.ids())
+	})
+
+	t.Run("separate_runs_of_one_video_are_both_delivered", func(t *testing.T) {
+		r := require.New(t)
+		cfg := config(t)
+		body := []byte(`{"video_id":"42","status":"FINISHED"}`)
+
+		first := &fakeBroker{}
+		outbox, err := NewOutboxMessageBroker(first, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "run-1", Body: body}))
+		r.NoError(outbox.Close(context.Background()))
+
+		// A re-run publishes the same data under its own event ID
+		second := &fakeBroker{}
+		outbox, err = NewOutboxMessageBroker(second, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "run-2", Body: body}))
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{Body: body}))
+		r.NoError(outbox.Close(context.Background()))
+		r.Equal([]string{"run-1"}, first.ids())
+		r.Len(second.ids(), 2)
+		r.Equal("run-2", second.ids()[0])
+		r.NotEmpty(second.ids()[1])
+	})
+
+	t.Run("replays_undelivered_events_on_startup", func(t *testing.T) {
+		r := require.New(t)
+		cfg := config(t)
+		down := &fakeBroker{fail: true}
+
+		outbox, err := NewOutboxMessageBroker(down, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{
+			ID: "a", Body: []byte(`{"n":1}`), Attributes: map[string]string{"status": "FINISHED"}, GroupID: "42",
+		}))
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "b", Body: []byte(`{"n":2}`)}))
+		r.ErrorContains(outbox.Close(context.Background()), "2 outbox events left undelivered")
+		r.Equal(4, down.attempts)
+
+		up := &fakeBroker{}
+		outbox, err = NewOutboxMessageBroker(up, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+		r.NoError(outbox.Close(context.Background()))
+		r.Equal([]string{"a", "b"}, up.ids())
+		r.Equal(`{"n":1}`, string(up.published[0].Body))
+		r.Equal(map[string]string{"status": "FINISHED"}, up.published[0].Attributes)
+		r.Equal("42", up.published[0].GroupID)
+	})
+
+	t.Run("skips_truncated_record", func(t *testing.T) {
+		r := require.New(t)
+		cfg := config(t)
+		r.NoError(os.MkdirAll(filepath.Dir(cfg.Path), 0o755))
+		content := `{"type":"event","id":"a","body":"{}","time":"2026-01-01T00:00:00Z"}` + "\n" + `{"type":"event","id":"b","bo`
+		r.NoError(os.WriteFile(cfg.Path, []byte(content), 0o644))
+
+		next := &fakeBroker{}
+		outbox, err := NewOutboxMessageBroker(next, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+		r.NoError(outbox.Close(context.Background()))
+		r.Equal([]string{"a"}, next.ids())
+
+		data, err := os.ReadFile(cfg.Path)
+		r.NoError(err)
+		r.NotContains(string(data), `"id":"b"`)
+		r.True(strings.HasSuffix(string(data), "\n"))
+	})
+
+	t.Run("flush_waits_for_delivery_and_keeps_accepting", func(t *testing.T) {
+		r := require.New(t)
+		next := &fakeBroker{}
+		outbox, err := NewOutboxMessageBroker(next, config(t), logger.NewSlogLogger())
+		r.NoError(err)
+		defer func() { _ = outbox.Close(context.Background()) }()
+
+		r.NoError(outbox.Flush(context.Background()))
+		for _, id := range []string{"a", "b"} {
+			r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: id, Body: []byte(`{}`)}))
+			r.NoError(outbox.Flush(context.Background()))
+			r.Contains(next.ids(), id)
+		}
+	})
+
+	t.Run("awaited_event_returns_once_delivered", func(t *testing.T) {
+		r := require.New(t)
+		next := &fakeBroker{}
+		outbox, err := NewOutboxMessageBroker(next, config(t), logger.NewSlogLogger())
+		r.NoError(err)
+		defer func() { _ = outbox.Close(context.Background()) }()
+
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "a", Body: []byte(`{}`), AwaitDelivery: true}))
+		r.Equal([]string{"a"}, next.ids())
+		// an event that is already delivered is acknowledged at once
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "a", Body: []byte(`{}`), AwaitDelivery: true}))
+		r.Equal([]string{"a"}, next.ids())
+	})
+
+	t.Run("awaited_event_fails_until_delivered", func(t *testing.T) {
+		r := require.New(t)
+		next := &fakeBroker{fail: true}
+		outbox, err := NewOutboxMessageBroker(next, config(t), logger.NewSlogLogger())
+		r.NoError(err)
+		defer func() { _ = outbox.Close(context.Background()) }()
+
+		msg := entity.Message{ID: "a", Body: []byte(`{}`), AwaitDelivery: true}
+		r.ErrorContains(outbox.PublishMessage(context.Background(), msg), "broker unreachable")
+		r.Equal(2, next.attempts)
+
+		// publishing it again retries the event given up on
+		next.mu.Lock()
+		next.fail = false
+		next.mu.Unlock()
+		r.NoError(outbox.PublishMessage(context.Background(), msg))
+		r.Equal([]string{"a"}, next.ids())
+	})
+
+	t.Run("awaited_event_is_interrupted_by_ctx", func(t *testing.T) {
+		r := require.New(t)
+		cfg := config(t)
+		cfg.MaxAttempts = 100
+		cfg.Backoff = time.Hour
+		outbox, err := NewOutboxMessageBroker(&fakeBroker{fail: true}, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err = outbox.PublishMessage(ctx, entity.Message{ID: "a", Body: []byte(`{}`), AwaitDelivery: true})
+		r.ErrorIs(err, context.DeadlineExceeded)
+		r.ErrorIs(outbox.Close(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("flush_timeout", func(t *testing.T) {
+		r := require.New(t)
+		cfg := config(t)
+		cfg.MaxAttempts = 100
+		cfg.Backoff = time.Hour
+		outbox, err := NewOutboxMessageBroker(&fakeBroker{fail: true}, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "a", Body: []byte(`{}`)}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		r.ErrorIs(outbox.Flush(ctx), context.DeadlineExceeded)
+		r.ErrorIs(outbox.Close(ctx), context.DeadlineExceeded)
+	})
+
+	t.Run("close_timeout_leaves_events_for_replay", func(t *testing.T) {
+		r := require.New(t)
+		cfg := config(t)
+		cfg.MaxAttempts = 100
+		cfg.Backoff = time.Hour
+		down := &fakeBroker{fail: true}
+
+		outbox, err := NewOutboxMessageBroker(down, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+		r.NoError(outbox.PublishMessage(context.Background(), entity.Message{ID: "a", Body: []byte(`{}`)}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		r.ErrorIs(outbox.Close(ctx), context.DeadlineExceeded)
+
+		up := &fakeBroker{}
+		outbox, err = NewOutboxMessageBroker(up, cfg, logger.NewSlogLogger())
+		r.NoError(err)
+		r.NoError(outbox.Close(context.Background()))
+		r.Equal([]string{"a"}, up.ids())
+	})
+}
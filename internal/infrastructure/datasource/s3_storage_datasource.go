@@ -2,20 +2,54 @@ package datasource
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
 )
 
+const (
+	// maxCopyObjectSize is the largest object S3 copies in a single CopyObject request
+	maxCopyObjectSize = 5 << 30
+
+	// defaultCopyPartSize is the size of the parts of a multipart copy
+	defaultCopyPartSize = 512 << 20
+
+	// maxUploadParts is the most parts a multipart upload may have
+	maxUploadParts = 10000
+)
+
 // S3StorageDataSource implements storage operations using AWS S3
 type S3StorageDataSource struct {
 	client          *s3.Client
 	videoBucket     string
 	processedBucket string
+	// copyThreshold is the size above which objects are copied in parts of copyPartSize
+	copyThreshold int64
+	copyPartSize  int64
+}
+
+// NewS3Client creates the S3 client; endpoint overrides the AWS endpoint (e.g. http://localhost:9000 for MinIO)
+// and usePathStyle addresses buckets in the path instead of the host, as most S3 compatible stores require
+func NewS3Client(awsCfg aws.Config, endpoint string, usePathStyle bool) *s3.Client {
+	return s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = usePathStyle
+	})
 }
 
 // NewS3StorageDataSource creates a new S3 storage datasource
@@ -24,19 +58,40 @@ func NewS3StorageDataSource(client *s3.Client, videoBucket, processedBucket stri
 		client:          client,
 		videoBucket:     videoBucket,
 		processedBucket: processedBucket,
+		copyThreshold:   maxCopyObjectSize,
+		copyPartSize:    defaultCopyPartSize,
 	}
 }
 
-// DownloadVideo downloads data from the video bucket in S3
-func (ds *S3StorageDataSource) DownloadVideo(ctx context.Context, key string) (io.ReadCloser, error) {
-	result, err := ds.client.GetObject(ctx, &s3.GetObjectInput{
+// DownloadVideo downloads data from the video bucket in S3, returning the ETag and version that were read.
+// When a version is given the read is pinned to it, so a re-read never returns a different upload.
+func (ds *S3StorageDataSource) DownloadVideo(ctx context.Context, key string, version entity.ObjectVersion) (io.ReadCloser, entity.ObjectVersion, error) {
+	input := &s3.GetObjectInput{
 		Bucket: aws.String(ds.videoBucket),
 		Key:    aws.String(key),
-	})
+	}
+	if version.ETag != "" {
+		input.IfMatch = aws.String(version.ETag)
+	}
+	if version.VersionId != "" {
+		input.VersionId = aws.String(version.VersionId)
+	}
+
+	result, err := ds.client.GetObject(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download from S3: %w", err)
+		if isPreconditionFailed(err) {
+			return nil, entity.ObjectVersion{}, domain.NewConflictError(domain.ErrSourceChanged)
+		}
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, entity.ObjectVersion{}, domain.NewNotFoundError(domain.ErrNotFound)
+		}
+		return nil, entity.ObjectVersion{}, fmt.Errorf("failed to download from S3: %w", err)
 	}
-	return result.Body, nil
+	return result.Body, entity.ObjectVersion{
+		ETag:      aws.ToString(result.ETag),
+		VersionId: aws.ToString(result.VersionId),
+	}, nil
 }
 
 // UploadProcessedFile uploads data to the processed bucket in S3. Returns the object key that was uploaded.
@@ -54,14 +109,286 @@ func (ds *S3StorageDataSource) UploadProcessedFile(ctx context.Context, key stri
 	return key, nil
 }
 
-// DeleteVideo deletes an object from the video bucket in S3
-func (ds *S3StorageDataSource) DeleteVideo(ctx context.Context, key string) error {
+// StatProcessedFile returns the size of an object in the processed bucket in S3
+func (ds *S3StorageDataSource) StatProcessedFile(ctx context.Context, key string) (int64, error) {
+	result, err := ds.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(ds.processedBucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return 0, domain.NewNotFoundError(domain.ErrNotFound)
+		}
+		return 0, fmt.Errorf("failed to stat processed S3 object: %w", err)
+	}
+	return aws.ToInt64(result.ContentLength), nil
+}
+
+// DeleteProcessedFile deletes an object from the processed bucket in S3
+func (ds *S3StorageDataSource) DeleteProcessedFile(ctx context.Context, key string) error {
 	_, err := ds.client.DeleteObject(ctx, &s3.DeleteObjectInput{
-		Bucket: aws.String(ds.videoBucket),
+		Bucket: aws.String(ds.processedBucket),
 		Key:    aws.String(key),
 	})
 	if err != nil {
+		return fmt.Errorf("failed to delete processed file from S3: %w", err)
+	}
+	return nil
+}
+
+// DeleteVideo deletes an object from the video bucket in S3. With an ETag the delete only succeeds while the
+// object is unchanged, so a newer upload under the same key is never removed.
+func (ds *S3StorageDataSource) DeleteVideo(ctx context.Context, key string, version entity.ObjectVersion) error {
+	input := &s3.DeleteObjectInput{
+		Bucket: aws.String(ds.videoBucket),
+		Key:    aws.String(key),
+	}
+	if version.ETag != "" {
+		input.IfMatch = aws.String(version.ETag)
+	}
+
+	_, err := ds.client.DeleteObject(ctx, input)
+	if err != nil {
+		if isPreconditionFailed(err) {
+			return domain.NewConflictError(domain.ErrSourceChanged)
+		}
 		return fmt.Errorf("failed to delete from S3: %w", err)
 	}
 	return nil
 }
+
+// CopyVideo copies an object of the video bucket to another key, optionally in another bucket.
+// An empty destination bucket copies within the video bucket. Objects too large for a single CopyObject are
+// copied in parts, each checked against the ETag.
+func (ds *S3StorageDataSource) CopyVideo(ctx context.Context, key string, version entity.ObjectVersion, destinationBucket, destinationKey string) error {
+	if destinationBucket == "" {
+		destinationBucket = ds.videoBucket
+	}
+	head := &s3.HeadObjectInput{
+		Bucket: aws.String(ds.videoBucket),
+		Key:    aws.String(key),
+	}
+	if version.ETag != "" {
+		head.IfMatch = aws.String(version.ETag)
+	}
+	if version.VersionId != "" {
+		head.VersionId = aws.String(version.VersionId)
+	}
+	source, err := ds.client.HeadObject(ctx, head)
+	if err != nil {
+		if isPreconditionFailed(err) {
+			return domain.NewConflictError(domain.ErrSourceChanged)
+		}
+		return fmt.Errorf("failed to stat S3 object to copy: %w", err)
+	}
+	if aws.ToInt64(source.ContentLength) > ds.copyThreshold {
+		err = ds.copyInParts(ctx, key, version, source, destinationBucket, destinationKey)
+	} else {
+		input := &s3.CopyObjectInput{
+			Bucket:     aws.String(destinationBucket),
+			Key:        aws.String(destinationKey),
+			CopySource: aws.String(copySource(ds.videoBucket, key, version.VersionId)),
+		}
+		if version.ETag != "" {
+			input.CopySourceIfMatch = aws.String(version.ETag)
+		}
+		_, err = ds.client.CopyObject(ctx, input)
+	}
+	if err != nil {
+		if isPreconditionFailed(err) {
+			return domain.NewConflictError(domain.ErrSourceChanged)
+		}
+		return fmt.Errorf("failed to copy S3 object: %w", err)
+	}
+	return nil
+}
+
+// copyInParts copies an object with a multipart upload of UploadPartCopy requests, aborting it on failure
+func (ds *S3StorageDataSource) copyInParts(ctx context.Context, key string, version entity.ObjectVersion, source *s3.HeadObjectOutput, destinationBucket, destinationKey string) error {
+	size := aws.ToInt64(source.ContentLength)
+	partSize := max(ds.copyPartSize, (size+maxUploadParts-1)/maxUploadParts)
+
+	upload, err := ds.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:      aws.String(destinationBucket),
+		Key:         aws.String(destinationKey),
+		ContentType: source.ContentType,
+		Metadata:    source.Metadata,
+	})
+	if err != nil {
+		return err
+	}
+
+	var parts []types.CompletedPart
+	for offset, number := int64(0), int32(1); offset < size; offset, number = offset+partSize, number+1 {
+		input := &s3.UploadPartCopyInput{
+			Bucket:          aws.String(destinationBucket),
+			Key:             aws.String(destinationKey),
+			UploadId:        upload.UploadId,
+			PartNumber:      aws.Int32(number),
+			CopySource:      aws.String(copySource(ds.videoBucket, key, version.VersionId)),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", offset, min(offset+partSize, size)-1)),
+		}
+		if version.ETag != "" {
+			input.CopySourceIfMatch = aws.String(version.ETag)
+		}
+		part, err := ds.client.UploadPartCopy(ctx, input)
+		if err != nil {
+			ds.abortUpload(ctx, destinationBucket, destinationKey, upload.UploadId)
+			return err
+		}
+		parts = append(parts, types.CompletedPart{ETag: part.CopyPartResult.ETag, PartNumber: aws.Int32(number)})
+	}
+
+	_, err = ds.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(destinationBucket),
+		Key:             aws.String(destinationKey),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		ds.abortUpload(ctx, destinationBucket, destinationKey, upload.UploadId)
+		return err
+	}
+	return nil
+}
+
+// abortUpload discards the parts of a failed multipart upload; a failure is left to the bucket's lifecycle rules
+func (ds *S3StorageDataSource) abortUpload(ctx context.Context, bucket, key string, uploadId *string) {
+	_, _ = ds.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: uploadId,
+	})
+}
+
+// TagVideo merges the given tags into the tag set of an object in the video bucket. Tagging is pinned to the
+// version ID when known; otherwise the ETag is checked right before tagging.
+func (ds *S3StorageDataSource) TagVideo(ctx context.Context, key string, version entity.ObjectVersion, tags map[string]string) error {
+	var versionId *string
+	if version.VersionId != "" {
+		versionId = aws.String(version.VersionId)
+	} else if version.ETag != "" {
+		_, err := ds.client.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket:  aws.String(ds.videoBucket),
+			Key:     aws.String(key),
+			IfMatch: aws.String(version.ETag),
+		})
+		if err != nil {
+			if isPreconditionFailed(err) {
+				return domain.NewConflictError(domain.ErrSourceChanged)
+			}
+			return fmt.Errorf("failed to check S3 object: %w", err)
+		}
+	}
+
+	current, err := ds.client.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket:    aws.String(ds.videoBucket),
+		Key:       aws.String(key),
+		VersionId: versionId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get S3 object tags: %w", err)
+	}
+
+	tagSet := make([]types.Tag, 0, len(current.TagSet)+len(tags))
+	for _, tag := range current.TagSet {
+		if _, overridden := tags[aws.ToString(tag.Key)]; !overridden {
+			tagSet = append(tagSet, tag)
+		}
+	}
+	for k, v := range tags {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+
+	_, err = ds.client.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:    aws.String(ds.videoBucket),
+		Key:       aws.String(key),
+		VersionId: versionId,
+		Tagging:   &types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to tag S3 object: %w", err)
+	}
+	return nil
+}
+
+// StatVideo returns the metadata of an object in the video bucket in S3
+func (ds *S3StorageDataSource) StatVideo(ctx context.Context, key string) (*entity.StorageObject, error) {
+	result, err := ds.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(ds.videoBucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return nil, domain.NewNotFoundError(domain.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to stat S3 object: %w", err)
+	}
+	metadata := make(map[string]string, len(result.Metadata))
+	for name, value := range result.Metadata {
+		metadata[strings.ToLower(name)] = value
+	}
+	return &entity.StorageObject{
+		Key:  key,
+		Size: aws.ToInt64(result.ContentLength),
+		Version: entity.ObjectVersion{
+			ETag:      aws.ToString(result.ETag),
+			VersionId: aws.ToString(result.VersionId),
+		},
+		Metadata: metadata,
+	}, nil
+}
+
+// ListVideos lists every object under the given prefix in the video bucket in S3, skipping folder markers
+func (ds *S3StorageDataSource) ListVideos(ctx context.Context, prefix string) ([]entity.StorageObject, error) {
+	paginator := s3.NewListObjectsV2Paginator(ds.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(ds.videoBucket),
+		Prefix: aws.String(prefix),
+	})
+
+	var objects []entity.StorageObject
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list S3 objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			if strings.HasSuffix(key, "/") {
+				continue
+			}
+			objects = append(objects, entity.StorageObject{
+				Key:     key,
+				Size:    aws.ToInt64(obj.Size),
+				Version: entity.ObjectVersion{ETag: aws.ToString(obj.ETag)},
+			})
+		}
+	}
+	return objects, nil
+}
+
+// copySource builds the URL-encoded "bucket/key[?versionId=]" value expected by CopyObject and UploadPartCopy
+func copySource(bucket, key, versionId string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	source := bucket + "/" + strings.Join(segments, "/")
+	if versionId != "" {
+		source += "?versionId=" + url.QueryEscape(versionId)
+	}
+	return source
+}
+
+// isPreconditionFailed reports whether S3 rejected a conditional request because the object changed
+func isPreconditionFailed(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "PreconditionFailed" {
+		return true
+	}
+	// HEAD responses carry no error body, only the status code
+	var respErr *smithyhttp.ResponseError
+	return errors.As(err, &respErr) && respErr.HTTPStatusCode() == http.StatusPreconditionFailed
+}
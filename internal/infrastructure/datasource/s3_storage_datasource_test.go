@@ -0,0 +1,113 @@
+package datasource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+)
+
+// fakeS3 answers the copy requests of a 10-byte object and records them
+type fakeS3 struct {
+	mu       sync.Mutex
+	requests []string
+	// failPart makes UploadPartCopy of that part fail its precondition
+	failPart string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	query := req.URL.Query()
+	record := req.Method + " " + req.URL.Path
+	switch {
+	case req.Method == http.MethodHead:
+		w.Header().Set("Content-Length", "10")
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "video/mp4")
+	case req.Method == http.MethodPost && query.Has("uploads"):
+		record += " create"
+		_, _ = fmt.Fprint(w, `<InitiateMultipartUploadResult><UploadId>u1</UploadId></InitiateMultipartUploadResult>`)
+	case req.Method == http.MethodPut && query.Has("partNumber"):
+		record += fmt.Sprintf(" part=%s range=%s if-match=%s", query.Get("partNumber"),
+			req.Header.Get("X-Amz-Copy-Source-Range"), req.Header.Get("X-Amz-Copy-Source-If-Match"))
+		if query.Get("partNumber") == f.failPart {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			_, _ = fmt.Fprint(w, `<Error><Code>PreconditionFailed</Code></Error>`)
+			break
+		}
+		_, _ = fmt.Fprintf(w, `<CopyPartResult><ETag>"p%s"</ETag></CopyPartResult>`, query.Get("partNumber"))
+	case req.Method == http.MethodPost && query.Has("uploadId"):
+		record += " complete"
+		_, _ = fmt.Fprint(w, `<CompleteMultipartUploadResult><ETag>"c"</ETag></CompleteMultipartUploadResult>`)
+	case req.Method == http.MethodDelete && query.Has("uploadId"):
+		record += " abort"
+		w.WriteHeader(http.StatusNoContent)
+	case req.Method == http.MethodPut:
+		record += " copy if-match=" + req.Header.Get("X-Amz-Copy-Source-If-Match")
+		_, _ = fmt.Fprint(w, `<CopyObjectResult><ETag>"c"</ETag></CopyObjectResult>`)
+	}
+	f.requests = append(f.requests, record)
+}
+
+func TestS3StorageDataSource_CopyVideo(t *testing.T) {
+	newDataSource := func(t *testing.T, f *fakeS3, threshold int64) *S3StorageDataSource {
+		srv := httptest.NewServer(f)
+		t.Cleanup(srv.Close)
+		client := NewS3Client(aws.Config{
+			Region:      "us-east-1",
+			Credentials: credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		}, srv.URL, true)
+		ds := NewS3StorageDataSource(client, "videos", "processed").(*S3StorageDataSource)
+		ds.copyThreshold = threshold
+		ds.copyPartSize = 4
+		return ds
+	}
+
+	t.Run("small_object_is_copied_at_once", func(t *testing.T) {
+		r := require.New(t)
+		f := &fakeS3{}
+		ds := newDataSource(t, f, 10)
+
+		r.NoError(ds.CopyVideo(context.Background(), "a.mp4", entity.ObjectVersion{ETag: `"v1"`}, "archive", "a.mp4"))
+		r.Equal([]string{
+			"HEAD /videos/a.mp4",
+			`PUT /archive/a.mp4 copy if-match="v1"`,
+		}, f.requests)
+	})
+
+	t.Run("large_object_is_copied_in_parts", func(t *testing.T) {
+		r := require.New(t)
+		f := &fakeS3{}
+		ds := newDataSource(t, f, 9)
+
+		r.NoError(ds.CopyVideo(context.Background(), "a.mp4", entity.ObjectVersion{ETag: `"v1"`}, "archive", "a.mp4"))
+		r.Equal([]string{
+			"HEAD /videos/a.mp4",
+			"POST /archive/a.mp4 create",
+			`PUT /archive/a.mp4 part=1 range=bytes=0-3 if-match="v1"`,
+			`PUT /archive/a.mp4 part=2 range=bytes=4-7 if-match="v1"`,
+			`PUT /archive/a.mp4 part=3 range=bytes=8-9 if-match="v1"`,
+			"POST /archive/a.mp4 complete",
+		}, f.requests)
+	})
+
+	t.Run("changed_source_aborts_the_upload", func(t *testing.T) {
+		r := require.New(t)
+		f := &fakeS3{failPart: "2"}
+		ds := newDataSource(t, f, 9)
+
+		err := ds.CopyVideo(context.Background(), "a.mp4", entity.ObjectVersion{ETag: `"v1"`}, "archive", "a.mp4")
+		r.Equal(domain.ErrorCodeConflict, domain.CodeOf(err))
+		r.Equal("DELETE /archive/a.mp4 abort", f.requests[len(f.requests)-1])
+	})
+}
@@ -2,10 +2,13 @@ package datasource
 
 import (
 	"context"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
 
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
 )
 
@@ -23,10 +26,41 @@ func NewSnsMessageBroker(client *sns.Client, topic string) port.MessageBroker {
 	}
 }
 
-func (ds *SNSMessageBroker) PublishMessage(ctx context.Context, message []byte) error {
-	_, err := ds.client.Publish(ctx, &sns.PublishInput{
-		TopicArn: aws.String(ds.topic),
-		Message:  aws.String(string(message)),
-	})
+func (ds *SNSMessageBroker) PublishMessage(ctx context.Context, message entity.Message) error {
+	input := &sns.PublishInput{
+		TopicArn:          aws.String(ds.topic),
+		Message:           aws.String(string(message.Body)),
+		MessageAttributes: snsMessageAttributes(message.Attributes),
+	}
+	// FIFO topics deliver each group in order and drop messages with a deduplication ID seen in the last 5 minutes
+	if strings.HasSuffix(ds.topic, ".fifo") {
+		input.MessageGroupId = aws.String(fifoGroupID(message))
+		input.MessageDeduplicationId = aws.String(message.ID)
+	}
+
+	_, err := ds.client.Publish(ctx, input)
 	return err
 }
+
+// snsMessageAttributes maps message attributes to SNS string attributes, skipping empty values SNS rejects
+func snsMessageAttributes(attributes map[string]string) map[string]types.MessageAttributeValue {
+	if len(attributes) == 0 {
+		return nil
+	}
+	out := make(map[string]types.MessageAttributeValue, len(attributes))
+	for name, value := range attributes {
+		if value == "" {
+			continue
+		}
+		out[name] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+	}
+	return out
+}
+
+// fifoGroupID returns the ordering group of a message; ungrouped messages share one group
+func fifoGroupID(message entity.Message) string {
+	if message.GroupID != "" {
+		return message.GroupID
+	}
+	return "default"
+}
@@ -0,0 +1,51 @@
+package datasource
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+)
+
+// SQSMessageBroker implements message publishing operations using AWS SQS
+type SQSMessageBroker struct {
+	client   *sqs.Client
+	queueURL string
+}
+
+// NewSqsMessageBroker creates a new SQS message broker
+func NewSqsMessageBroker(client *sqs.Client, queueURL string) port.MessageBroker {
+	return &SQSMessageBroker{
+		client:   client,
+		queueURL: queueURL,
+	}
+}
+
+func (ds *SQSMessageBroker) PublishMessage(ctx context.Context, message entity.Message) error {
+	attributes := map[string]types.MessageAttributeValue{
+		"message_id": {DataType: aws.String("String"), StringValue: aws.String(message.ID)},
+	}
+	for name, value := range message.Attributes {
+		if value != "" {
+			attributes[name] = types.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(value)}
+		}
+	}
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:          aws.String(ds.queueURL),
+		MessageBody:       aws.String(string(message.Body)),
+		MessageAttributes: attributes,
+	}
+	if strings.HasSuffix(ds.queueURL, ".fifo") {
+		input.MessageGroupId = aws.String(fifoGroupID(message))
+		input.MessageDeduplicationId = aws.String(message.ID)
+	}
+
+	_, err := ds.client.SendMessage(ctx, input)
+	return err
+}
@@ -0,0 +1,55 @@
+package datasource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+)
+
+// webhookTimeout bounds a single webhook delivery
+const webhookTimeout = 10 * time.Second
+
+// WebhookMessageBroker implements message publishing operations as HTTP POST requests
+type WebhookMessageBroker struct {
+	client *http.Client
+	url    string
+}
+
+// NewWebhookMessageBroker creates a new webhook message broker posting to url
+func NewWebhookMessageBroker(client *http.Client, url string) port.MessageBroker {
+	if client == nil {
+		client = &http.Client{Timeout: webhookTimeout}
+	}
+	return &WebhookMessageBroker{
+		client: client,
+		url:    url,
+	}
+}
+
+func (ds *WebhookMessageBroker) PublishMessage(ctx context.Context, message entity.Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ds.url, bytes.NewReader(message.Body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Receivers de-duplicate redeliveries of the same event with this key
+	req.Header.Set("Idempotency-Key", message.ID)
+
+	resp, err := ds.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package lambda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+const (
+	runtimeAPIVersion = "2018-06-01"
+
+	// responseMargin is kept from the invocation deadline to post the response before Lambda times out
+	responseMargin = 500 * time.Millisecond
+)
+
+// Handler processes the payload of one invocation and returns the response payload
+type Handler func(ctx context.Context, payload []byte) ([]byte, error)
+
+// Invocation is an event fetched from the Runtime API
+type Invocation struct {
+	RequestID   string
+	FunctionARN string
+	TraceID     string
+	Deadline    time.Time
+	Payload     []byte
+}
+
+// Runtime implements the Lambda Runtime API loop for a custom runtime
+type Runtime struct {
+	client  *http.Client
+	baseURL string
+	logger  logger.Logger
+}
+
+// NewRuntime creates a runtime talking to the Runtime API at api (host:port). A nil client uses one without
+// timeout, as fetching the next invocation blocks until there is one.
+func NewRuntime(api string, client *http.Client, logger logger.Logger) *Runtime {
+	if client == nil {
+		client = &http.Client{}
+	}
+	return &Runtime{
+		client:  client,
+		baseURL: "http://" + api + "/" + runtimeAPIVersion + "/runtime",
+		logger:  logger,
+	}
+}
+
+// Run processes invocations one at a time until ctx is done or the Runtime API fails. Handler errors are reported
+// to Lambda as invocation errors and do not stop the loop.
+func (r *Runtime) Run(ctx context.Context, handler Handler) error {
+	for {
+		invocation, err := r.next(ctx)
+		if err == nil {
+			err = r.invoke(ctx, invocation, handler)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// InitError reports a failure to initialize the function; Lambda then discards the execution environment
+func (r *Runtime) InitError(ctx context.Context, err error) error {
+	return r.postError(ctx, r.baseURL+"/init/error", err)
+}
+
+func (r *Runtime) invoke(ctx context.Context, invocation *Invocation, handler Handler) error {
+	log := r.logger.With("request_id", invocation.RequestID)
+	if invocation.TraceID != "" {
+		// Read by the AWS SDK to propagate X-Ray tracing
+		_ = os.Setenv("_X_AMZN_TRACE_ID", invocation.TraceID)
+	}
+
+	ctx = logger.SetTraceIDOnContext(ctx, invocation.RequestID)
+	ctx = logger.WithAttributes(ctx, "job_id", invocation.RequestID)
+	invokeCtx, cancel := context.WithDeadline(ctx, invocation.Deadline.Add(-responseMargin))
+	defer cancel()
+
+	log.Info("Invocation received", "deadline", invocation.Deadline)
+	response, err := r.handle(invokeCtx, handler, invocation.Payload)
+	if err != nil {
+		log.Error("Invocation failed", "error", err)
+		return r.postError(ctx, r.baseURL+"/invocation/"+invocation.RequestID+"/error", err)
+	}
+	log.Info("Invocation completed")
+	return r.post(ctx, r.baseURL+"/invocation/"+invocation.RequestID+"/response", response, nil)
+}
+
+// handle runs the handler, turning a panic into an invocation error so the runtime keeps serving
+func (r *Runtime) handle(ctx context.Context, handler Handler, payload []byte) (response []byte, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return handler(ctx, payload)
+}
+
+func (r *Runtime) next(ctx context.Context) (*Invocation, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.baseURL+"/invocation/next", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the next invocation: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	payload, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the next invocation: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch the next invocation: status %d", resp.StatusCode)
+	}
+
+	invocation := &Invocation{
+		RequestID:   resp.Header.Get("Lambda-Runtime-Aws-Request-Id"),
+		FunctionARN: resp.Header.Get("Lambda-Runtime-Invoked-Function-Arn"),
+		TraceID:     resp.Header.Get("Lambda-Runtime-Trace-Id"),
+		Payload:     payload,
+	}
+	if invocation.RequestID == "" {
+		return nil, errors.New("invocation without a request id")
+	}
+	deadlineMs, err := strconv.ParseInt(resp.Header.Get("Lambda-Runtime-Deadline-Ms"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid invocation deadline: %w", err)
+	}
+	invocation.Deadline = time.UnixMilli(deadlineMs)
+	return invocation, nil
+}
+
+// runtimeError is the error document the Runtime API expects
+type runtimeError struct {
+	ErrorMessage string `json:"errorMessage"`
+	ErrorType    string `json:"errorType"`
+}
+
+func (r *Runtime) postError(ctx context.Context, url string, err error) error {
+	errorType := errorType(err)
+	body, mErr := json.Marshal(runtimeError{ErrorMessage: err.Error(), ErrorType: errorType})
+	if mErr != nil {
+		return mErr
+	}
+	return r.post(ctx, url, body, map[string]string{"Lambda-Runtime-Function-Error-Type": errorType})
+}
+
+func (r *Runtime) post(ctx context.Context, url string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post to the Runtime API: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("failed to post to the Runtime API: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// errorType names the error by its Go type, e.g. domain.InvalidInputError; the failing stage recorded around a
+// domain error is not a type of its own
+func errorType(err error) string {
+	if sErr, ok := err.(*domain.StageError); ok {
+		err = sErr.Err
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", err), "*")
+}
@@ -0,0 +1,260 @@
+package lambda
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/adapter/trigger"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/logger"
+)
+
+// fakeInvocation is an event queued on the fake Runtime API
+type fakeInvocation struct {
+	id       string
+	deadline time.Time
+	payload  string
+}
+
+// fakeResult is what the runtime posted for an invocation, or for init when id is empty
+type fakeResult struct {
+	id        string
+	body      []byte
+	isError   bool
+	errorType string
+}
+
+// fakeRuntimeAPI serves queued invocations the way the Lambda Runtime API does and records the results
+type fakeRuntimeAPI struct {
+	server      *httptest.Server
+	invocations chan fakeInvocation
+	results     chan fakeResult
+}
+
+func newFakeRuntimeAPI(t *testing.T) *fakeRuntimeAPI {
+	f := &fakeRuntimeAPI{invocations: make(chan fakeInvocation, 8), results: make(chan fakeResult, 8)}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeRuntimeAPI) serve(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/2018-06-01/runtime")
+	switch {
+	case req.Method == http.MethodGet && path == "/invocation/next":
+		select {
+		case inv := <-f.invocations:
+			w.Header().Set("Lambda-Runtime-Aws-Request-Id", inv.id)
+			w.Header().Set("Lambda-Runtime-Deadline-Ms", strconv.FormatInt(inv.deadline.UnixMilli(), 10))
+			w.Header().Set("Lambda-Runtime-Invoked-Function-Arn", "arn:aws:lambda:us-east-1:1:function:video")
+			w.Header().Set("Lambda-Runtime-Trace-Id", "Root=1-5759e988-bd862e3fe1be46a994272793")
+			_, _ = io.WriteString(w, inv.payload)
+		case <-req.Context().Done():
+		}
+	case req.Method == http.MethodPost && (strings.HasSuffix(path, "/response") || strings.HasSuffix(path, "/error")):
+		body, _ := io.ReadAll(req.Body)
+		id := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(path, "/invocation/"), "/response"), "/error")
+		if path == "/init/error" {
+			id = ""
+		}
+		f.results <- fakeResult{
+			id:        id,
+			body:      body,
+			isError:   strings.HasSuffix(path, "/error"),
+			errorType: req.Header.Get("Lambda-Runtime-Function-Error-Type"),
+		}
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// api is the host:port Lambda exposes in AWS_LAMBDA_RUNTIME_API
+func (f *fakeRuntimeAPI) api() string {
+	return strings.TrimPrefix(f.server.URL, "http://")
+}
+
+// run starts the runtime loop and returns a function that stops it and waits for Run to return
+func (f *fakeRuntimeAPI) run(t *testing.T, handler Handler) func() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- NewRuntime(f.api(), nil, logger.NewSlogLogger()).Run(ctx, handler) }()
+	return func() error {
+		cancel()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("runtime did not stop")
+			return nil
+		}
+	}
+}
+
+func (f *fakeRuntimeAPI) result(t *testing.T) fakeResult {
+	select {
+	case res := <-f.results:
+		return res
+	case <-time.After(5 * time.Second):
+		t.Fatal("no result posted")
+		return fakeResult{}
+	}
+}
+
+func TestRuntime(t *testing.T) {
+	t.Run("responds_and_reports_errors", func(t *testing.T) {
+		r := require.New(t)
+		api := newFakeRuntimeAPI(t)
+		deadline := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+
+		var mu sync.Mutex
+		var deadlines []time.Time
+		stop := api.run(t, func(ctx context.Context, payload []byte) ([]byte, error) {
+			d, _ := ctx.Deadline()
+			mu.Lock()
+			deadlines = append(deadlines, d)
+			mu.Unlock()
+			switch string(payload) {
+			case `"panic"`:
+				panic("boom")
+			case `"fail"`:
+				return nil, domain.WithStage(domain.StageInput, domain.NewInvalidInputError("bad request"))
+			}
+			return []byte(`{"echo":` + string(payload) + `}`), nil
+		})
+
+		api.invocations <- fakeInvocation{id: "req-1", deadline: deadline, payload: `"hello"`}
+		res := api.result(t)
+		r.Equal(fakeResult{id: "req-1", body: []byte(`{"echo":"hello"}`)}, res)
+
+		api.invocations <- fakeInvocation{id: "req-2", deadline: deadline, payload: `"fail"`}
+		res = api.result(t)
+		r.Equal("req-2", res.id)
+		r.True(res.isError)
+		r.Equal("domain.InvalidInputError", res.errorType)
+		r.JSONEq(`{"errorMessage":"bad request","errorType":"domain.InvalidInputError"}`, string(res.body))
+
+		// a panicking handler is reported and the runtime keeps serving
+		api.invocations <- fakeInvocation{id: "req-3", deadline: deadline, payload: `"panic"`}
+		res = api.result(t)
+		r.True(res.isError)
+		r.Contains(string(res.body), "panic: boom")
+
+		r.NoError(stop())
+		r.Equal([]time.Time{deadline.Add(-responseMargin), deadline.Add(-responseMargin), deadline.Add(-responseMargin)}, deadlines)
+	})
+
+	t.Run("deadline_cancels_handler", func(t *testing.T) {
+		r := require.New(t)
+		api := newFakeRuntimeAPI(t)
+		stop := api.run(t, func(ctx context.Context, payload []byte) ([]byte, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+		api.invocations <- fakeInvocation{id: "slow", deadline: time.Now().Add(responseMargin + 50*time.Millisecond), payload: `{}`}
+		res := api.result(t)
+		r.True(res.isError)
+		r.Contains(string(res.body), context.DeadlineExceeded.Error())
+		r.NoError(stop())
+	})
+
+	t.Run("init_error", func(t *testing.T) {
+		r := require.New(t)
+		api := newFakeRuntimeAPI(t)
+		runtime := NewRuntime(api.api(), nil, logger.NewSlogLogger())
+
+		r.NoError(runtime.InitError(context.Background(), errors.New("missing STATUS_BROKER_URL")))
+		res := api.result(t)
+		r.Equal("", res.id)
+		r.True(res.isError)
+		r.Contains(string(res.body), "missing STATUS_BROKER_URL")
+	})
+
+	t.Run("runtime_api_failure_stops_loop", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		err := NewRuntime(strings.TrimPrefix(srv.URL, "http://"), nil, logger.NewSlogLogger()).
+			Run(context.Background(), func(ctx context.Context, payload []byte) ([]byte, error) { return nil, nil })
+		require.ErrorContains(t, err, "status 500")
+	})
+}
+
+// TestRuntime_VideoInvocations runs the Lambda handler wiring (trigger.LambdaHandler in front of the controller)
+// against the fake Runtime API
+func TestRuntime_VideoInvocations(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	vc := pmocks.NewMockVideoController(ctrl)
+	vg := pmocks.NewMockVideoGateway(ctrl)
+	template := dto.ProcessVideoInput{RetentionPolicy: "delete", IdFormat: "any"}
+	limit := int64(100)
+	s3Trigger := trigger.NewS3EventTrigger(vc, vg, trigger.S3EventTriggerConfig{Bucket: "videos", MaxObjectSize: func() int64 { return limit }, Input: template}, logger.NewSlogLogger())
+	handler := trigger.NewLambdaHandler(s3Trigger)
+
+	api := newFakeRuntimeAPI(t)
+	stop := api.run(t, handler.Handle)
+	deadline := time.Now().Add(time.Minute)
+
+	// direct invocation
+	want := template
+	want.VideoKey = "a.mp4"
+	want.VideoId = "v-1"
+	want.UserId = "u-1"
+	vc.EXPECT().ProcessVideo(gomock.Any(), want).DoAndReturn(func(ctx context.Context, in dto.ProcessVideoInput) ([]byte, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return nil, errors.New("invocation without deadline")
+		}
+		return []byte(`{"success":true}`), nil
+	})
+	api.invocations <- fakeInvocation{id: "direct", deadline: deadline, payload: `{"video_key":"a.mp4","video_id":"v-1","user_id":"u-1"}`}
+	res := api.result(t)
+	r.False(res.isError)
+	r.JSONEq(`{"success":true}`, string(res.body))
+
+	// S3 notification with one object that fits and one that exceeds the temp space
+	vg.EXPECT().Stat(gomock.Any(), "b.mp4").Return(&entity.StorageObject{Metadata: map[string]string{"video-id": "v-2", "user-id": "u-2"}}, nil)
+	vc.EXPECT().ProcessVideo(gomock.Any(), gomock.Cond(func(in dto.ProcessVideoInput) bool {
+		return in.VideoKey == "b.mp4" && in.VideoId == "v-2" && in.SourceETag == `"e2"`
+	})).Return([]byte(`{"success":true}`), nil)
+	event := `{"Records":[
+		{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"videos"},"object":{"key":"b.mp4","size":10,"eTag":"e2"}}},
+		{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"videos"},"object":{"key":"huge.mp4","size":1000}}}]}`
+	api.invocations <- fakeInvocation{id: "s3", deadline: deadline, payload: event}
+	res = api.result(t)
+	r.True(res.isError)
+	var doc runtimeError
+	r.NoError(json.Unmarshal(res.body, &doc))
+	r.Contains(doc.ErrorMessage, "s3://videos/huge.mp4: object of 1000 bytes exceeds the limit of 100 bytes")
+
+	// the limit is read again for every event, e.g. once temp space was reclaimed
+	limit = 2000
+	vg.EXPECT().Stat(gomock.Any(), "huge.mp4").Return(&entity.StorageObject{Metadata: map[string]string{"video-id": "v-3", "user-id": "u-3"}}, nil)
+	vc.EXPECT().ProcessVideo(gomock.Any(), gomock.Cond(func(in dto.ProcessVideoInput) bool {
+		return in.VideoKey == "huge.mp4"
+	})).Return([]byte(`{"success":true}`), nil)
+	api.invocations <- fakeInvocation{id: "s3-after-sweep", deadline: deadline, payload: `{"Records":[
+		{"eventSource":"aws:s3","eventName":"ObjectCreated:Put","s3":{"bucket":{"name":"videos"},"object":{"key":"huge.mp4","size":1000}}}]}`}
+	res = api.result(t)
+	r.False(res.isError)
+
+	r.NoError(stop())
+}
@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// typed keys to avoid context key collisions
+type (
+	traceIDKey    struct{}
+	attributesKey struct{}
+)
+
+// SetTraceIDOnContext returns a new context with the given trace id stored safely.
+func SetTraceIDOnContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// GetTraceIDFromContext retrieves a trace id from context in a type-safe way.
+func GetTraceIDFromContext(ctx context.Context) string {
+	if traceID := ctx.Value(traceIDKey{}); traceID != nil {
+		if id, ok := traceID.(string); ok {
+			return id
+		}
+	}
+	return "unknown"
+}
+
+// WithAttributes returns a copy of ctx carrying attributes, given as key-value pairs or slog.Attr like With, that
+// the loggers of the context add to every record, e.g. the job, video and user IDs, the stage or the attempt.
+// An attribute replaces the one of the same key carried by ctx.
+func WithAttributes(ctx context.Context, args ...any) context.Context {
+	current := attributesFromContext(ctx)
+	attrs := make([]slog.Attr, 0, len(current)+len(args)/2)
+	added := toAttrs(args)
+	for _, attr := range current {
+		if !hasKey(added, attr.Key) {
+			attrs = append(attrs, attr)
+		}
+	}
+	return context.WithValue(ctx, attributesKey{}, append(attrs, added...))
+}
+
+func attributesFromContext(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attributesKey{}).([]slog.Attr)
+	return attrs
+}
+
+// toAttrs converts the arguments of With, alternating keys and values or slog.Attr, to attributes
+func toAttrs(args []any) []slog.Attr {
+	var attrs []slog.Attr
+	for len(args) > 0 {
+		switch key := args[0].(type) {
+		case slog.Attr:
+			attrs = append(attrs, key)
+			args = args[1:]
+		case string:
+			if len(args) == 1 {
+				attrs = append(attrs, slog.Any("!BADKEY", key))
+				args = nil
+				continue
+			}
+			attrs = append(attrs, slog.Any(key, args[1]))
+			args = args[2:]
+		default:
+			attrs = append(attrs, slog.Any("!BADKEY", key))
+			args = args[1:]
+		}
+	}
+	return attrs
+}
+
+func hasKey(attrs []slog.Attr, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// contextHandler adds the trace of the record's context and the attributes it carries: the IDs of its OpenTelemetry
+// span, so logs join the exported traces, or else the trace id set on it
+type contextHandler struct {
+	next slog.Handler
+}
+
+func (h *contextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	} else if traceID, ok := ctx.Value(traceIDKey{}).(string); ok {
+		record.AddAttrs(slog.String("trace_id", traceID))
+	}
+	record.AddAttrs(attributesFromContext(ctx)...)
+	return h.next.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{next: h.next.WithGroup(name)}
+}
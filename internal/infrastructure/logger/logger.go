@@ -2,8 +2,18 @@ package logger
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
+)
+
+const (
+	// FormatJSON writes one JSON object per record (default)
+	FormatJSON = "json"
+	// FormatText writes key=value records, easier to read in a terminal
+	FormatText = "text"
 )
 
 // Logger interface for structured logging
@@ -16,69 +26,116 @@ type Logger interface {
 	WithContext(ctx context.Context) Logger
 }
 
+// Options configures the records a logger writes
+type Options struct {
+	// Level is debug, info (default), warn or error
+	Level string
+	// Format is FormatJSON (default) or FormatText
+	Format string
+	// Writer defaults to stdout
+	Writer io.Writer
+	// Redactor scrubs the message and the string values of every record; nil uses NewRedactor()
+	Redactor Redactor
+}
+
 // SlogLogger implements Logger using slog
 type SlogLogger struct {
 	logger *slog.Logger
+	// ctx carries the trace and the attributes added to every record, see WithContext
+	ctx context.Context
+}
+
+// New creates a structured logger with the given options
+func New(options Options) (Logger, error) {
+	level, err := ParseLevel(options.Level)
+	if err != nil {
+		return nil, err
+	}
+	w := options.Writer
+	if w == nil {
+		w = os.Stdout
+	}
+	redactor := options.Redactor
+	if redactor == nil {
+		redactor = NewRedactor()
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch options.Format {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(w, handlerOptions)
+	case FormatText:
+		handler = slog.NewTextHandler(w, handlerOptions)
+	default:
+		return nil, fmt.Errorf("unsupported log format %q (allowed: json, text)", options.Format)
+	}
+	handler = &contextHandler{next: &redactingHandler{next: handler, redact: redactor}}
+	return &SlogLogger{logger: slog.New(handler), ctx: context.Background()}, nil
+}
+
+// ParseLevel parses a level name, case-insensitively; empty is info
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unsupported log level %q (allowed: debug, info, warn, error)", name)
+	}
 }
 
 // NewSlogLogger creates a new structured logger
 func NewSlogLogger() Logger {
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	})
-	return &SlogLogger{
-		logger: slog.New(handler),
-	}
+	return NewSlogLoggerWithWriter(os.Stdout)
+}
+
+// NewSlogLoggerWithWriter creates a new structured logger writing to w, e.g. stderr when stdout carries the output
+// of a command
+func NewSlogLoggerWithWriter(w io.Writer) Logger {
+	// The default options are always valid
+	logger, _ := New(Options{Writer: w})
+	return logger
 }
 
 // Info logs an info message
 func (l *SlogLogger) Info(msg string, args ...any) {
-	l.logger.Info(msg, args...)
+	l.logger.InfoContext(l.ctx, msg, args...)
 }
 
 // Warn logs a warning message
 func (l *SlogLogger) Warn(msg string, args ...any) {
-	l.logger.Warn(msg, args...)
+	l.logger.WarnContext(l.ctx, msg, args...)
 }
 
 // Error logs an error message
 func (l *SlogLogger) Error(msg string, args ...any) {
-	l.logger.Error(msg, args...)
+	l.logger.ErrorContext(l.ctx, msg, args...)
 }
 
 // Debug logs a debug message
 func (l *SlogLogger) Debug(msg string, args ...any) {
-	l.logger.Debug(msg, args...)
+	l.logger.DebugContext(l.ctx, msg, args...)
 }
 
 // With returns a new logger with additional attributes
 func (l *SlogLogger) With(args ...any) Logger {
 	return &SlogLogger{
 		logger: l.logger.With(args...),
+		ctx:    l.ctx,
 	}
 }
 
-// WithContext returns a new logger with context
+// WithContext returns a new logger adding the trace and the attributes of ctx (see WithAttributes) to every record.
+// The context replaces the one of l, so loggers derived again from a later context do not repeat attributes.
 func (l *SlogLogger) WithContext(ctx context.Context) Logger {
 	return &SlogLogger{
-		logger: l.logger.With("trace_id", GetTraceIDFromContext(ctx)),
-	}
-}
-
-// typed key to avoid context key collisions
-type traceIDKey struct{}
-
-// SetTraceIDOnContext returns a new context with the given trace id stored safely.
-func SetTraceIDOnContext(ctx context.Context, id string) context.Context {
-	return context.WithValue(ctx, traceIDKey{}, id)
-}
-
-// GetTraceIDFromContext retrieves a trace id from context in a type-safe way.
-func GetTraceIDFromContext(ctx context.Context) string {
-	if traceID := ctx.Value(traceIDKey{}); traceID != nil {
-		if id, ok := traceID.(string); ok {
-			return id
-		}
+		logger: l.logger,
+		ctx:    ctx,
 	}
-	return "unknown"
 }
@@ -0,0 +1,102 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces the secrets found in log records
+const redacted = "[REDACTED]"
+
+// minSecretLength keeps short values, e.g. a one-character password, from scrubbing unrelated text
+const minSecretLength = 4
+
+var (
+	// awsAccessKeyID matches long-term (AKIA) and temporary (ASIA) access key IDs
+	awsAccessKeyID = regexp.MustCompile(`\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`)
+	// presignedParams matches the signature, credential and session token of SigV4 presigned URLs
+	presignedParams = regexp.MustCompile(`(?i)(X-Amz-(?:Signature|Credential|Security-Token))=[^&\s"']+`)
+	// sensitiveKey matches the attribute keys whose value is a secret as a whole
+	sensitiveKey = regexp.MustCompile(`(?i)secret|password|passwd|token|authorization|api_?key|access_key|signature`)
+)
+
+// Redactor returns value with the secrets it contains scrubbed; key is the attribute key, empty for the message
+type Redactor func(key, value string) string
+
+// NewRedactor returns a Redactor scrubbing the given secret values, e.g. the configured credentials, AWS access key
+// IDs, the signature and credentials of presigned URLs, and the values of attributes named like secrets
+func NewRedactor(secrets ...string) Redactor {
+	var known []string
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			known = append(known, secret)
+		}
+	}
+	return func(key, value string) string {
+		if value == "" {
+			return value
+		}
+		if key != "" && sensitiveKey.MatchString(key) {
+			return redacted
+		}
+		for _, secret := range known {
+			value = strings.ReplaceAll(value, secret, redacted)
+		}
+		value = awsAccessKeyID.ReplaceAllString(value, redacted)
+		return presignedParams.ReplaceAllString(value, "$1="+redacted)
+	}
+}
+
+// redactingHandler scrubs the message and the string and error values of every record before it is written
+type redactingHandler struct {
+	next   slog.Handler
+	redact Redactor
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	scrubbed := slog.NewRecord(record.Time, record.Level, h.redact("", record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		scrubbed.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, scrubbed)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		scrubbed = append(scrubbed, h.redactAttr(attr))
+	}
+	return &redactingHandler{next: h.next.WithAttrs(scrubbed), redact: h.redact}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name), redact: h.redact}
+}
+
+// redactAttr scrubs strings, errors and groups; other values, e.g. numbers, cannot carry a secret
+func (h *redactingHandler) redactAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.redact(attr.Key, attr.Value.String()))
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		scrubbed := make([]slog.Attr, 0, len(group))
+		for _, member := range group {
+			scrubbed = append(scrubbed, h.redactAttr(member))
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(scrubbed...)}
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, h.redact(attr.Key, err.Error()))
+		}
+	}
+	return attr
+}
@@ -0,0 +1,261 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+)
+
+const (
+	portGateway   = "video_gateway"
+	portProcessor = "video_processor"
+	portCache     = "source_cache"
+)
+
+// InstrumentVideoUseCase counts the processed videos, their duration and their failures by error code and stage
+func InstrumentVideoUseCase(next port.VideoUseCase, m *Metrics) port.VideoUseCase {
+	return &videoUseCase{next: next, metrics: m}
+}
+
+type videoUseCase struct {
+	next    port.VideoUseCase
+	metrics *Metrics
+}
+
+func (u *videoUseCase) ProcessVideo(ctx context.Context, input dto.ProcessVideoInput) (*dto.ProcessVideoOutput, error) {
+	start := time.Now()
+	output, err := u.next.ProcessVideo(ctx, input)
+	u.metrics.observeVideo(start, err)
+	return output, err
+}
+
+// InstrumentVideoGateway times every gateway call and counts the bytes downloaded and uploaded. The download stage
+// lasts until the video body is closed, as the transfer happens while it is read.
+func InstrumentVideoGateway(next port.VideoGateway, m *Metrics) port.VideoGateway {
+	return &videoGateway{next: next, metrics: m}
+}
+
+type videoGateway struct {
+	next    port.VideoGateway
+	metrics *Metrics
+}
+
+func (g *videoGateway) observe(operation string, stage domain.Stage, start time.Time, err error) {
+	g.metrics.observeCall(portGateway, operation, stage, start, err)
+}
+
+func (g *videoGateway) Download(ctx context.Context, key string, version entity.ObjectVersion) (io.ReadCloser, entity.ObjectVersion, error) {
+	start := time.Now()
+	body, read, err := g.next.Download(ctx, key, version)
+	if err != nil {
+		g.observe("Download", domain.StageDownload, start, err)
+		return body, read, err
+	}
+	return &countingBody{ReadCloser: body, bytes: g.metrics.downloadBytes.Add, done: func(err error) {
+		g.observe("Download", domain.StageDownload, start, err)
+	}}, read, nil
+}
+
+func (g *videoGateway) Upload(ctx context.Context, key string, data io.Reader, contentType string, size int64) (string, error) {
+	start := time.Now()
+	location, err := g.next.Upload(ctx, key, data, contentType, size)
+	g.observe("Upload", domain.StageUpload, start, err)
+	if err == nil && size > 0 {
+		g.metrics.uploadBytes.Add(float64(size))
+	}
+	return location, err
+}
+
+func (g *videoGateway) StatOutput(ctx context.Context, key string) (int64, error) {
+	start := time.Now()
+	size, err := g.next.StatOutput(ctx, key)
+	g.observe("StatOutput", domain.StageVerify, start, err)
+	return size, err
+}
+
+func (g *videoGateway) DeleteOutput(ctx context.Context, key string) error {
+	start := time.Now()
+	err := g.next.DeleteOutput(ctx, key)
+	g.observe("DeleteOutput", "", start, err)
+	return err
+}
+
+func (g *videoGateway) Delete(ctx context.Context, key string, version entity.ObjectVersion) error {
+	start := time.Now()
+	err := g.next.Delete(ctx, key, version)
+	g.observe("Delete", "", start, err)
+	return err
+}
+
+func (g *videoGateway) Copy(ctx context.Context, key string, version entity.ObjectVersion, destinationBucket, destinationKey string) error {
+	start := time.Now()
+	err := g.next.Copy(ctx, key, version, destinationBucket, destinationKey)
+	g.observe("Copy", "", start, err)
+	return err
+}
+
+func (g *videoGateway) Tag(ctx context.Context, key string, version entity.ObjectVersion, tags map[string]string) error {
+	start := time.Now()
+	err := g.next.Tag(ctx, key, version, tags)
+	g.observe("Tag", "", start, err)
+	return err
+}
+
+func (g *videoGateway) Stat(ctx context.Context, key string) (*entity.StorageObject, error) {
+	start := time.Now()
+	object, err := g.next.Stat(ctx, key)
+	g.observe("Stat", "", start, err)
+	return object, err
+}
+
+func (g *videoGateway) List(ctx context.Context, prefix string) ([]entity.StorageObject, error) {
+	start := time.Now()
+	objects, err := g.next.List(ctx, prefix)
+	g.observe("List", "", start, err)
+	return objects, err
+}
+
+func (g *videoGateway) UpdateStatus(ctx context.Context, update dto.VideoStatusUpdate) error {
+	start := time.Now()
+	err := g.next.UpdateStatus(ctx, update)
+	g.observe("UpdateStatus", domain.StagePublish, start, err)
+	return err
+}
+
+func (g *videoGateway) UploadBatchReport(ctx context.Context, key string, report *dto.ProcessBatchOutput) error {
+	start := time.Now()
+	err := g.next.UploadBatchReport(ctx, key, report)
+	g.observe("UploadBatchReport", "", start, err)
+	return err
+}
+
+func (g *videoGateway) UploadProcessingReport(ctx context.Context, key string, report *dto.ProcessingReport) error {
+	start := time.Now()
+	err := g.next.UploadProcessingReport(ctx, key, report)
+	g.observe("UploadProcessingReport", "", start, err)
+	return err
+}
+
+func (g *videoGateway) NotifyCallback(ctx context.Context, notification dto.CallbackNotification) (entity.CallbackDelivery, error) {
+	start := time.Now()
+	delivery, err := g.next.NotifyCallback(ctx, notification)
+	g.observe("NotifyCallback", "", start, err)
+	return delivery, err
+}
+
+// countingBody counts the bytes read from a download and reports the end of the transfer when it is closed, with
+// the read error if any
+type countingBody struct {
+	io.ReadCloser
+	bytes   func(float64)
+	done    func(error)
+	readErr error
+	once    sync.Once
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.bytes(float64(n))
+	}
+	if err != nil && err != io.EOF && b.readErr == nil {
+		b.readErr = err
+	}
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.readErr) })
+	return err
+}
+
+// InstrumentVideoProcessor times every video processor call and counts the frames produced
+func InstrumentVideoProcessor(next port.VideoProcessor, m *Metrics) port.VideoProcessor {
+	return &videoProcessor{next: next, metrics: m}
+}
+
+type videoProcessor struct {
+	next    port.VideoProcessor
+	metrics *Metrics
+}
+
+func (p *videoProcessor) observe(operation string, stage domain.Stage, start time.Time, err error) {
+	p.metrics.observeCall(portProcessor, operation, stage, start, err)
+}
+
+func (p *videoProcessor) ProcessVideo(ctx context.Context, videoPath string, config entity.ProcessingConfig) (*entity.FrameExtraction, error) {
+	start := time.Now()
+	extraction, err := p.next.ProcessVideo(ctx, videoPath, config)
+	p.observe("ProcessVideo", domain.StageExtract, start, err)
+	if err == nil {
+		p.metrics.framesProduced.Add(float64(extraction.FrameCount))
+	}
+	return extraction, err
+}
+
+func (p *videoProcessor) ValidateVideo(ctx context.Context, videoPath string) error {
+	start := time.Now()
+	err := p.next.ValidateVideo(ctx, videoPath)
+	p.observe("ValidateVideo", domain.StageValidate, start, err)
+	return err
+}
+
+func (p *videoProcessor) ProbeVideo(ctx context.Context, videoPath string) (*entity.VideoMetadata, error) {
+	start := time.Now()
+	metadata, err := p.next.ProbeVideo(ctx, videoPath)
+	p.observe("ProbeVideo", "", start, err)
+	return metadata, err
+}
+
+func (p *videoProcessor) VerifyArchive(ctx context.Context, archivePath string) (*entity.FrameArchive, error) {
+	start := time.Now()
+	archive, err := p.next.VerifyArchive(ctx, archivePath)
+	p.observe("VerifyArchive", "", start, err)
+	return archive, err
+}
+
+func (p *videoProcessor) Capabilities(ctx context.Context) (*entity.ProcessorCapabilities, error) {
+	start := time.Now()
+	capabilities, err := p.next.Capabilities(ctx)
+	p.observe("Capabilities", "", start, err)
+	return capabilities, err
+}
+
+// InstrumentSourceCache times every source cache call and counts the lookups by result: hit, miss or error
+func InstrumentSourceCache(next port.SourceCache, m *Metrics) port.SourceCache {
+	return &sourceCache{next: next, metrics: m}
+}
+
+type sourceCache struct {
+	next    port.SourceCache
+	metrics *Metrics
+}
+
+func (c *sourceCache) Fetch(ctx context.Context, key string, version entity.ObjectVersion, path string) (string, bool, error) {
+	start := time.Now()
+	hash, found, err := c.next.Fetch(ctx, key, version, path)
+	c.metrics.observeCall(portCache, "Fetch", "", start, err)
+	result := "miss"
+	switch {
+	case err != nil:
+		result = outcomeError
+	case found:
+		result = "hit"
+	}
+	c.metrics.sourceCache.WithLabelValues(result).Inc()
+	return hash, found, err
+}
+
+func (c *sourceCache) Store(ctx context.Context, key string, version entity.ObjectVersion, path, hash string) error {
+	start := time.Now()
+	err := c.next.Store(ctx, key, version, path, hash)
+	c.metrics.observeCall(portCache, "Store", "", start, err)
+	return err
+}
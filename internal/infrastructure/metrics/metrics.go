@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+)
+
+const namespace = "video_processor"
+
+const (
+	outcomeSuccess = "success"
+	outcomeError   = "error"
+)
+
+// durationBuckets cover port calls of milliseconds up to the extraction of long videos
+var durationBuckets = []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800}
+
+// Metrics holds the Prometheus collectors of the job in a registry of its own, exposed on /metrics in long-running
+// modes and pushed to a Pushgateway when a job exits
+type Metrics struct {
+	registry *prometheus.Registry
+
+	videosProcessed  *prometheus.CounterVec
+	videoDuration    prometheus.Histogram
+	errors           *prometheus.CounterVec
+	stageDuration    *prometheus.HistogramVec
+	portCalls        *prometheus.CounterVec
+	portCallDuration *prometheus.HistogramVec
+	downloadBytes    prometheus.Counter
+	uploadBytes      prometheus.Counter
+	framesProduced   prometheus.Counter
+	ffmpegDuration   *prometheus.HistogramVec
+	ffmpegCPU        *prometheus.CounterVec
+	ffmpegFailedRuns *prometheus.CounterVec
+	sourceCache      *prometheus.CounterVec
+	lastPush         prometheus.Gauge
+}
+
+// New creates the collectors, with the Go runtime and process collectors, in a new registry
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		videosProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "videos_processed_total", Help: "Videos processed, by outcome.",
+		}, []string{"outcome"}),
+		videoDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "video_duration_seconds", Help: "Time to process one video, from download to status.",
+			Buckets: durationBuckets,
+		}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "errors_total", Help: "Failed videos, by error code and failing stage.",
+		}, []string{"code", "stage"}),
+		stageDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "stage_duration_seconds", Help: "Duration of the processing stages, by stage and outcome.",
+			Buckets: durationBuckets,
+		}, []string{"stage", "outcome"}),
+		portCalls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "port_calls_total", Help: "Calls to the gateway and the video processor, by outcome.",
+		}, []string{"port", "operation", "outcome"}),
+		portCallDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "port_call_duration_seconds", Help: "Duration of the calls to the gateway and the video processor.",
+			Buckets: durationBuckets,
+		}, []string{"port", "operation"}),
+		downloadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "download_bytes_total", Help: "Bytes of original videos downloaded.",
+		}),
+		uploadBytes: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "upload_bytes_total", Help: "Bytes of frame archives uploaded.",
+		}),
+		framesProduced: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace, Name: "frames_produced_total", Help: "Frames extracted from videos.",
+		}),
+		ffmpegDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "ffmpeg_wall_seconds", Help: "Wall time of the ffmpeg and ffprobe processes, by tool.",
+			Buckets: durationBuckets,
+		}, []string{"tool"}),
+		ffmpegCPU: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "ffmpeg_cpu_seconds_total", Help: "User and system CPU time of the ffmpeg and ffprobe processes, by tool.",
+		}, []string{"tool"}),
+		ffmpegFailedRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "ffmpeg_failures_total", Help: "ffmpeg and ffprobe processes that failed, by tool.",
+		}, []string{"tool"}),
+		sourceCache: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "source_cache_lookups_total", Help: "Lookups of original videos in the source cache, by result.",
+		}, []string{"result"}),
+		lastPush: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "last_push_timestamp_seconds", Help: "Time of the last push to the Pushgateway.",
+		}),
+	}
+	m.registry.MustRegister(
+		m.videosProcessed, m.videoDuration, m.errors, m.stageDuration, m.portCalls, m.portCallDuration,
+		m.downloadBytes, m.uploadBytes, m.framesProduced, m.ffmpegDuration, m.ffmpegCPU, m.ffmpegFailedRuns,
+		m.sourceCache, m.lastPush,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// NewServer returns a server exposing the metrics on /metrics at addr
+func (m *Metrics) NewServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+}
+
+// PushConfig identifies the group the metrics replace on the Pushgateway
+type PushConfig struct {
+	URL string
+	Job string
+	// Grouping labels, e.g. the instance, tell apart the groups of jobs running at the same time
+	Grouping map[string]string
+	// Client defaults to http.DefaultClient
+	Client *http.Client
+}
+
+// Push replaces the metrics of the configured group on a Pushgateway compatible endpoint
+func (m *Metrics) Push(ctx context.Context, config PushConfig) error {
+	m.lastPush.SetToCurrentTime()
+	pusher := push.New(config.URL, config.Job).Gatherer(m.registry)
+	for name, value := range config.Grouping {
+		pusher = pusher.Grouping(name, value)
+	}
+	if config.Client != nil {
+		pusher = pusher.Client(config.Client)
+	}
+	if err := pusher.PushContext(ctx); err != nil {
+		return fmt.Errorf("failed to push metrics to %s: %w", config.URL, err)
+	}
+	return nil
+}
+
+// ObserveCommand records an ffmpeg or ffprobe process once it exited
+func (m *Metrics) ObserveCommand(tool string, wall, cpu time.Duration, err error) {
+	m.ffmpegDuration.WithLabelValues(tool).Observe(wall.Seconds())
+	m.ffmpegCPU.WithLabelValues(tool).Add(cpu.Seconds())
+	if err != nil {
+		m.ffmpegFailedRuns.WithLabelValues(tool).Inc()
+	}
+}
+
+// observeVideo records the outcome of one video; failures are counted by error code and stage
+func (m *Metrics) observeVideo(start time.Time, err error) {
+	m.videoDuration.Observe(time.Since(start).Seconds())
+	if err == nil {
+		m.videosProcessed.WithLabelValues(outcomeSuccess).Inc()
+		return
+	}
+	m.videosProcessed.WithLabelValues(outcomeError).Inc()
+	m.errors.WithLabelValues(string(domain.CodeOf(err)), string(domain.StageOf(err))).Inc()
+}
+
+// observeCall records a port call, and the stage it stands for when it has one
+func (m *Metrics) observeCall(port, operation string, stage domain.Stage, start time.Time, err error) {
+	elapsed := time.Since(start).Seconds()
+	m.portCalls.WithLabelValues(port, operation, outcome(err)).Inc()
+	m.portCallDuration.WithLabelValues(port, operation).Observe(elapsed)
+	if stage != "" {
+		m.stageDuration.WithLabelValues(string(stage), outcome(err)).Observe(elapsed)
+	}
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return outcomeError
+	}
+	return outcomeSuccess
+}
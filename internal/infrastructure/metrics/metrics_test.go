@@ -0,0 +1,181 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/dto"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+)
+
+func TestMetrics_Push(t *testing.T) {
+	t.Run("replaces_the_group_on_the_pushgateway", func(t *testing.T) {
+		r := require.New(t)
+		var method, path string
+		var body []byte
+		pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			method, path = req.Method, req.URL.Path
+			body, _ = io.ReadAll(req.Body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer pushgateway.Close()
+
+		m := New()
+		m.framesProduced.Add(12)
+		m.ObserveCommand("ffmpeg", 2*time.Second, 3*time.Second, nil)
+
+		err := m.Push(context.Background(), PushConfig{
+			URL:      pushgateway.URL,
+			Job:      "video-processor-job",
+			Grouping: map[string]string{"instance": "pod-1"},
+			Client:   pushgateway.Client(),
+		})
+		r.NoError(err)
+		r.Equal(http.MethodPut, method)
+		r.Equal("/metrics/job/video-processor-job/instance/pod-1", path)
+		// The body is in the protobuf delimited format, where metric names appear verbatim
+		r.Contains(string(body), "video_processor_frames_produced_total")
+		r.Contains(string(body), "video_processor_ffmpeg_cpu_seconds_total")
+	})
+
+	t.Run("reports_a_rejected_push", func(t *testing.T) {
+		pushgateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}))
+		defer pushgateway.Close()
+
+		err := New().Push(context.Background(), PushConfig{URL: pushgateway.URL, Job: "job"})
+		require.ErrorContains(t, err, "failed to push metrics")
+	})
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	r := require.New(t)
+	m := New()
+	m.uploadBytes.Add(1024)
+
+	rec := httptest.NewRecorder()
+	m.NewServer(":0").Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	r.Equal(http.StatusOK, rec.Code)
+	r.Contains(rec.Body.String(), "video_processor_upload_bytes_total 1024")
+	r.Contains(rec.Body.String(), "go_goroutines")
+}
+
+func TestInstrumentVideoUseCase(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	uc := pmocks.NewMockVideoUseCase(ctrl)
+	m := New()
+	instrumented := InstrumentVideoUseCase(uc, m)
+
+	failure := domain.WithStage(domain.StageDownload, domain.NewNotFoundError(domain.ErrNotFound))
+	uc.EXPECT().ProcessVideo(gomock.Any(), gomock.Any()).Return(&dto.ProcessVideoOutput{Success: true}, nil)
+	uc.EXPECT().ProcessVideo(gomock.Any(), gomock.Any()).Return(nil, failure)
+
+	_, err := instrumented.ProcessVideo(context.Background(), dto.ProcessVideoInput{})
+	r.NoError(err)
+	_, err = instrumented.ProcessVideo(context.Background(), dto.ProcessVideoInput{})
+	r.ErrorIs(err, failure)
+
+	r.Equal(1.0, testutil.ToFloat64(m.videosProcessed.WithLabelValues("success")))
+	r.Equal(1.0, testutil.ToFloat64(m.videosProcessed.WithLabelValues("error")))
+	r.Equal(1.0, testutil.ToFloat64(m.errors.WithLabelValues("NOT_FOUND", "download")))
+}
+
+func TestInstrumentVideoGateway(t *testing.T) {
+	t.Run("counts_downloaded_bytes_until_closed", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		m := New()
+		instrumented := InstrumentVideoGateway(vg, m)
+
+		vg.EXPECT().Download(gomock.Any(), "a.mp4", entity.ObjectVersion{}).
+			Return(io.NopCloser(strings.NewReader("0123456789")), entity.ObjectVersion{ETag: "e"}, nil)
+
+		body, version, err := instrumented.Download(context.Background(), "a.mp4", entity.ObjectVersion{})
+		r.NoError(err)
+		r.Equal("e", version.ETag)
+		_, err = io.Copy(io.Discard, body)
+		r.NoError(err)
+		r.Equal(10.0, testutil.ToFloat64(m.downloadBytes))
+		r.Equal(0, testutil.CollectAndCount(m.stageDuration), "the stage ends when the body is closed")
+
+		r.NoError(body.Close())
+		r.NoError(body.Close())
+		r.Equal(1.0, testutil.ToFloat64(m.portCalls.WithLabelValues(portGateway, "Download", "success")))
+		r.Equal(1, testutil.CollectAndCount(m.stageDuration))
+	})
+
+	t.Run("counts_uploaded_bytes_and_failures", func(t *testing.T) {
+		r := require.New(t)
+		ctrl := gomock.NewController(t)
+		vg := pmocks.NewMockVideoGateway(ctrl)
+		m := New()
+		instrumented := InstrumentVideoGateway(vg, m)
+
+		vg.EXPECT().Upload(gomock.Any(), "processed/a.zip", gomock.Any(), "application/zip", int64(300)).Return("processed/a.zip", nil)
+		vg.EXPECT().UpdateStatus(gomock.Any(), gomock.Any()).Return(errors.New("broker down"))
+
+		_, err := instrumented.Upload(context.Background(), "processed/a.zip", strings.NewReader(""), "application/zip", 300)
+		r.NoError(err)
+		r.Error(instrumented.UpdateStatus(context.Background(), dto.VideoStatusUpdate{}))
+
+		r.Equal(300.0, testutil.ToFloat64(m.uploadBytes))
+		r.Equal(1.0, testutil.ToFloat64(m.portCalls.WithLabelValues(portGateway, "UpdateStatus", "error")))
+	})
+}
+
+func TestInstrumentVideoProcessor(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	vp := pmocks.NewMockVideoProcessor(ctrl)
+	m := New()
+	instrumented := InstrumentVideoProcessor(vp, m)
+
+	vp.EXPECT().ProcessVideo(gomock.Any(), "a.mp4", gomock.Any()).Return(&entity.FrameExtraction{FrameCount: 7, ArchivePath: "frames.zip"}, nil)
+	vp.EXPECT().ProcessVideo(gomock.Any(), "b.mp4", gomock.Any()).Return(nil, errors.New("ffmpeg failed"))
+
+	_, err := instrumented.ProcessVideo(context.Background(), "a.mp4", entity.ProcessingConfig{})
+	r.NoError(err)
+	_, err = instrumented.ProcessVideo(context.Background(), "b.mp4", entity.ProcessingConfig{})
+	r.Error(err)
+
+	r.Equal(7.0, testutil.ToFloat64(m.framesProduced))
+	r.Equal(1.0, testutil.ToFloat64(m.portCalls.WithLabelValues(portProcessor, "ProcessVideo", "error")))
+}
+
+func TestInstrumentSourceCache(t *testing.T) {
+	r := require.New(t)
+	ctrl := gomock.NewController(t)
+	sc := pmocks.NewMockSourceCache(ctrl)
+	m := New()
+	instrumented := InstrumentSourceCache(sc, m)
+
+	version := entity.ObjectVersion{ETag: `"e"`}
+	sc.EXPECT().Fetch(gomock.Any(), "a.mp4", version, "/tmp/a").Return("h", true, nil)
+	sc.EXPECT().Fetch(gomock.Any(), "b.mp4", version, "/tmp/b").Return("", false, nil)
+	sc.EXPECT().Fetch(gomock.Any(), "c.mp4", version, "/tmp/c").Return("", false, errors.New("corrupt entry"))
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, _, _ = instrumented.Fetch(context.Background(), key+".mp4", version, "/tmp/"+key)
+	}
+
+	r.Equal(1.0, testutil.ToFloat64(m.sourceCache.WithLabelValues("hit")))
+	r.Equal(1.0, testutil.ToFloat64(m.sourceCache.WithLabelValues("miss")))
+	r.Equal(1.0, testutil.ToFloat64(m.sourceCache.WithLabelValues("error")))
+	r.Equal(1.0, testutil.ToFloat64(m.portCalls.WithLabelValues(portCache, "Fetch", "error")))
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // registers the decoder used to check jpg frames
+	_ "image/png"  // registers the decoder used to check png frames
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+)
+
+// maxArchiveProblems bounds the problems listed for one archive; the rest are summarized
+const maxArchiveProblems = 20
+
+// frameEncoders are the ffmpeg encoders the supported output formats rely on
+var frameEncoders = []string{"mjpeg", "png"}
+
+// frameNamePattern matches the names ProcessVideo gives to the frames in the archive
+var frameNamePattern = regexp.MustCompile(`^frame_(\d+)\.(jpg|png)$`)
+
+// ffprobeOutput is the part of `ffprobe -show_format -show_streams -of json` that is reported
+type ffprobeOutput struct {
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		Size       string `json:"size"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+	Streams []struct {
+		Index        int    `json:"index"`
+		CodecType    string `json:"codec_type"`
+		CodecName    string `json:"codec_name"`
+		Width        int    `json:"width"`
+		Height       int    `json:"height"`
+		AvgFrameRate string `json:"avg_frame_rate"`
+		Duration     string `json:"duration"`
+	} `json:"streams"`
+}
+
+// ProbeVideo returns the container and stream metadata reported by ffprobe
+func (s *FFmpegService) ProbeVideo(ctx context.Context, videoPath string) (*entity.VideoMetadata, error) {
+	if _, err := os.Stat(videoPath); err != nil {
+		return nil, fmt.Errorf("video file is not readable: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_format",
+		"-show_streams",
+		"-of", "json",
+		videoPath,
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := s.run(ctx, cmd, false)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w\nffprobe output:\n%s", err, stderr.String())
+	}
+
+	return parseFFprobeOutput(output)
+}
+
+func parseFFprobeOutput(data []byte) (*entity.VideoMetadata, error) {
+	var probe ffprobeOutput
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+
+	// ffprobe reports numbers as strings and omits the unknown ones, which parse as zero
+	metadata := &entity.VideoMetadata{
+		FormatName: probe.Format.FormatName,
+		Duration:   parseFloat(probe.Format.Duration),
+		Size:       int64(parseFloat(probe.Format.Size)),
+		BitRate:    int64(parseFloat(probe.Format.BitRate)),
+		Streams:    make([]entity.StreamMetadata, 0, len(probe.Streams)),
+	}
+	for _, stream := range probe.Streams {
+		metadata.Streams = append(metadata.Streams, entity.StreamMetadata{
+			Index:     stream.Index,
+			CodecType: stream.CodecType,
+			CodecName: stream.CodecName,
+			Width:     stream.Width,
+			Height:    stream.Height,
+			FrameRate: parseRational(stream.AvgFrameRate),
+			Duration:  parseFloat(stream.Duration),
+		})
+	}
+	return metadata, nil
+}
+
+func parseFloat(value string) float64 {
+	f, _ := strconv.ParseFloat(value, 64)
+	return f
+}
+
+// parseRational parses ffprobe rates such as 30000/1001; 0/0 (unknown) is zero
+func parseRational(value string) float64 {
+	num, den, ok := strings.Cut(value, "/")
+	if !ok {
+		return parseFloat(value)
+	}
+	d := parseFloat(den)
+	if d == 0 {
+		return 0
+	}
+	return parseFloat(num) / d
+}
+
+// VerifyArchive reads every frame of the archive, so corrupted entries fail their checksum, and checks that the
+// frames are decodable images of one format and size numbered without gaps. A missing or unreadable file is an
+// error; every other problem is listed in the returned archive.
+func (s *FFmpegService) VerifyArchive(ctx context.Context, archivePath string) (*entity.FrameArchive, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	hasher := sha256.New()
+	size, err := io.Copy(hasher, file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	archive := &entity.FrameArchive{Size: size, SHA256: hex.EncodeToString(hasher.Sum(nil))}
+
+	reader, err := zip.NewReader(file, size)
+	if err != nil {
+		archive.Problems = []string{fmt.Sprintf("not a zip archive: %v", err)}
+		return archive, nil
+	}
+
+	var problems []string
+	var numbers []int
+	for _, f := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		match := frameNamePattern.FindStringSubmatch(f.Name)
+		if match == nil {
+			problems = append(problems, fmt.Sprintf("%s: unexpected entry", f.Name))
+			continue
+		}
+		if archive.Format == "" {
+			archive.Format = match[2]
+		} else if match[2] != archive.Format {
+			problems = append(problems, fmt.Sprintf("%s: format differs from %s", f.Name, archive.Format))
+		}
+		number, _ := strconv.Atoi(match[1])
+		numbers = append(numbers, number)
+
+		width, height, err := readFrame(f, match[2])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", f.Name, err))
+			continue
+		}
+		if archive.Width == 0 && archive.Height == 0 {
+			archive.Width, archive.Height = width, height
+		} else if width != archive.Width || height != archive.Height {
+			problems = append(problems, fmt.Sprintf("%s: size %dx%d differs from %dx%d", f.Name, width, height, archive.Width, archive.Height))
+		}
+	}
+
+	archive.FrameCount = len(numbers)
+	if len(numbers) == 0 {
+		problems = append(problems, "archive contains no frames")
+	} else {
+		sort.Ints(numbers)
+		archive.FirstFrame, archive.LastFrame = numbers[0], numbers[len(numbers)-1]
+		for i := 1; i < len(numbers); i++ {
+			switch gap := numbers[i] - numbers[i-1]; {
+			case gap == 0:
+				problems = append(problems, fmt.Sprintf("frame %d is duplicated", numbers[i]))
+			case gap > 1:
+				problems = append(problems, fmt.Sprintf("frames %d to %d are missing", numbers[i-1]+1, numbers[i]-1))
+			}
+		}
+	}
+
+	if len(problems) > maxArchiveProblems {
+		problems = append(problems[:maxArchiveProblems], fmt.Sprintf("and %d more problems", len(problems)-maxArchiveProblems))
+	}
+	archive.Problems = problems
+	return archive, nil
+}
+
+// readFrame reads a whole archive entry, which verifies its checksum, and returns the size of the image it holds;
+// the image must be in the format of its extension
+func readFrame(f *zip.File, format string) (int, int, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() {
+		_ = rc.Close()
+	}()
+
+	content, err := io.ReadAll(rc)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(content) == 0 {
+		return 0, 0, errors.New("empty frame")
+	}
+	config, decoded, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return 0, 0, fmt.Errorf("not a decodable image: %w", err)
+	}
+	if decoded == "jpeg" {
+		decoded = "jpg"
+	}
+	if decoded != format {
+		return 0, 0, fmt.Errorf("holds a %s image", decoded)
+	}
+	return config.Width, config.Height, nil
+}
+
+// Capabilities reports the ffmpeg and ffprobe versions, the frame encoders and the hardware accelerations available.
+// What could be detected is returned together with the error of what could not.
+func (s *FFmpegService) Capabilities(ctx context.Context) (*entity.ProcessorCapabilities, error) {
+	capabilities := &entity.ProcessorCapabilities{}
+	var errs []error
+
+	if output, err := s.runTool(ctx, "ffmpeg", "-version"); err != nil {
+		errs = append(errs, err)
+	} else {
+		capabilities.FFmpegVersion = firstLine(output)
+		if output, err := s.runTool(ctx, "ffmpeg", "-encoders"); err != nil {
+			errs = append(errs, err)
+		} else {
+			capabilities.Encoders = parseEncoders(output)
+		}
+		if output, err := s.runTool(ctx, "ffmpeg", "-hwaccels"); err != nil {
+			errs = append(errs, err)
+		} else {
+			capabilities.HWAccels = parseHWAccels(output)
+		}
+	}
+	if output, err := s.runTool(ctx, "ffprobe", "-version"); err != nil {
+		errs = append(errs, err)
+	} else {
+		capabilities.FFprobeVersion = firstLine(output)
+	}
+
+	return capabilities, errors.Join(errs...)
+}
+
+func (s *FFmpegService) runTool(ctx context.Context, name string, arg string) (string, error) {
+	output, err := s.run(ctx, exec.CommandContext(ctx, name, "-hide_banner", arg), false)
+	if err != nil {
+		return "", fmt.Errorf("%s %s failed: %w", name, arg, err)
+	}
+	return string(output), nil
+}
+
+func firstLine(output string) string {
+	line, _, _ := strings.Cut(output, "\n")
+	return strings.TrimSpace(line)
+}
+
+// parseEncoders returns the frame encoders listed by `ffmpeg -encoders` ("V....D mjpeg  MJPEG (Motion JPEG)")
+func parseEncoders(output string) []string {
+	available := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 {
+			available[fields[1]] = true
+		}
+	}
+
+	var encoders []string
+	for _, encoder := range frameEncoders {
+		if available[encoder] {
+			encoders = append(encoders, encoder)
+		}
+	}
+	return encoders
+}
+
+// parseHWAccels returns the methods listed by `ffmpeg -hwaccels` after its header line
+func parseHWAccels(output string) []string {
+	var methods []string
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		if i == 0 && strings.HasSuffix(strings.TrimSpace(line), ":") {
+			continue
+		}
+		if method := strings.TrimSpace(line); method != "" {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
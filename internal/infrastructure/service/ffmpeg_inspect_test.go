@@ -0,0 +1,174 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFFprobeOutput(t *testing.T) {
+	t.Run("reports_format_and_streams", func(t *testing.T) {
+		r := require.New(t)
+		output := []byte(`{
+			"streams": [
+				{"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1280, "height": 720, "avg_frame_rate": "30000/1001", "duration": "10.010000"},
+				{"index": 1, "codec_type": "audio", "codec_name": "aac", "avg_frame_rate": "0/0"}
+			],
+			"format": {"format_name": "mov,mp4,m4a,3gp,3g2,mj2", "duration": "10.010000", "size": "1048576", "bit_rate": "838860"}
+		}`)
+
+		metadata, err := parseFFprobeOutput(output)
+		r.NoError(err)
+		r.Equal("mov,mp4,m4a,3gp,3g2,mj2", metadata.FormatName)
+		r.InDelta(10.01, metadata.Duration, 1e-9)
+		r.Equal(int64(1048576), metadata.Size)
+		r.Equal(int64(838860), metadata.BitRate)
+		r.Len(metadata.Streams, 2)
+		r.Equal("h264", metadata.Streams[0].CodecName)
+		r.Equal(1280, metadata.Streams[0].Width)
+		r.InDelta(29.97, metadata.Streams[0].FrameRate, 0.01)
+		r.Equal("audio", metadata.Streams[1].CodecType)
+		r.Zero(metadata.Streams[1].FrameRate)
+		r.Zero(metadata.Streams[1].Duration)
+	})
+
+	t.Run("invalid_json", func(t *testing.T) {
+		_, err := parseFFprobeOutput([]byte("not json"))
+		require.ErrorContains(t, err, "invalid ffprobe output")
+	})
+}
+
+func TestParseRational(t *testing.T) {
+	r := require.New(t)
+	r.Equal(25.0, parseRational("25/1"))
+	r.Equal(24.0, parseRational("24"))
+	r.Zero(parseRational("0/0"))
+	r.Zero(parseRational(""))
+}
+
+func TestParseCapabilities(t *testing.T) {
+	t.Run("encoders", func(t *testing.T) {
+		output := "Encoders:\n V..... = Video\n ------\n V....D mjpeg                MJPEG (Motion JPEG)\n V....D libx264              H.264\n"
+		require.Equal(t, []string{"mjpeg"}, parseEncoders(output))
+	})
+
+	t.Run("hwaccels", func(t *testing.T) {
+		output := "Hardware acceleration methods:\nvdpau\ncuda\n\n"
+		require.Equal(t, []string{"vdpau", "cuda"}, parseHWAccels(output))
+	})
+
+	t.Run("first_line", func(t *testing.T) {
+		require.Equal(t, "ffmpeg version 6.1", firstLine("ffmpeg version 6.1\nbuilt with gcc\n"))
+	})
+}
+
+// writeArchive writes a zip with the given entries, in order, to a temp dir and returns its path
+func writeArchive(t *testing.T, entries map[string][]byte, order ...string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, name := range order {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write(entries[name])
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	path := filepath.Join(t.TempDir(), "frames.zip")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+	return path
+}
+
+func pngFrame(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestFFmpegService_VerifyArchive(t *testing.T) {
+	s := &FFmpegService{}
+	ctx := context.Background()
+
+	t.Run("valid_archive", func(t *testing.T) {
+		r := require.New(t)
+		frame := pngFrame(t, 4, 3)
+		path := writeArchive(t, map[string][]byte{
+			"frame_0001.png": frame,
+			"frame_0002.png": frame,
+			"frame_0003.png": frame,
+		}, "frame_0001.png", "frame_0002.png", "frame_0003.png")
+
+		archive, err := s.VerifyArchive(ctx, path)
+		r.NoError(err)
+		r.True(archive.Valid(), archive.Problems)
+		r.Equal(3, archive.FrameCount)
+		r.Equal("png", archive.Format)
+		r.Equal(4, archive.Width)
+		r.Equal(3, archive.Height)
+		r.Equal(1, archive.FirstFrame)
+		r.Equal(3, archive.LastFrame)
+		r.Len(archive.SHA256, 64)
+		r.Positive(archive.Size)
+	})
+
+	t.Run("reports_gaps_sizes_and_undecodable_frames", func(t *testing.T) {
+		r := require.New(t)
+		path := writeArchive(t, map[string][]byte{
+			"frame_0001.png": pngFrame(t, 4, 3),
+			"frame_0002.png": pngFrame(t, 8, 6),
+			"frame_0005.png": []byte("not an image"),
+			"notes.txt":      []byte("hello"),
+		}, "frame_0001.png", "frame_0002.png", "frame_0005.png", "notes.txt")
+
+		archive, err := s.VerifyArchive(ctx, path)
+		r.NoError(err)
+		r.False(archive.Valid())
+		r.Equal(3, archive.FrameCount)
+		r.Contains(archive.Problems, "frame_0002.png: size 8x6 differs from 4x3")
+		r.Contains(archive.Problems, "frames 3 to 4 are missing")
+		r.Contains(archive.Problems, "notes.txt: unexpected entry")
+		r.Len(archive.Problems, 4)
+	})
+
+	t.Run("frame_in_another_format", func(t *testing.T) {
+		r := require.New(t)
+		path := writeArchive(t, map[string][]byte{"frame_0001.jpg": pngFrame(t, 4, 3)}, "frame_0001.jpg")
+
+		archive, err := s.VerifyArchive(ctx, path)
+		r.NoError(err)
+		r.Equal([]string{"frame_0001.jpg: holds a png image"}, archive.Problems)
+	})
+
+	t.Run("empty_archive", func(t *testing.T) {
+		archive, err := s.VerifyArchive(ctx, writeArchive(t, nil))
+		require.NoError(t, err)
+		require.Equal(t, []string{"archive contains no frames"}, archive.Problems)
+	})
+
+	t.Run("not_a_zip", func(t *testing.T) {
+		r := require.New(t)
+		path := filepath.Join(t.TempDir(), "frames.zip")
+		r.NoError(os.WriteFile(path, []byte("garbage"), 0o644))
+
+		archive, err := s.VerifyArchive(ctx, path)
+		r.NoError(err)
+		r.False(archive.Valid())
+		r.Equal(int64(7), archive.Size)
+		r.Len(archive.Problems, 1)
+		r.Contains(archive.Problems[0], "not a zip archive")
+	})
+
+	t.Run("missing_file", func(t *testing.T) {
+		_, err := s.VerifyArchive(ctx, filepath.Join(t.TempDir(), "missing.zip"))
+		require.ErrorContains(t, err, "failed to open archive")
+	})
+}
@@ -3,64 +3,125 @@ package service
 import (
 	"archive/zip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
 	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/infrastructure/tracing"
 )
 
 const (
 	// DefaultJPEGQuality defines the quality level for JPEG output (1-31, lower = better quality)
 	// Value 2 provides high quality with reasonable file size
 	DefaultJPEGQuality = "2"
+
+	// DefaultMinSegmentDuration is the shortest video (in seconds) split into parallel segments
+	DefaultMinSegmentDuration = 60.0
+
+	// segmentSeekLead is how far before its first frame a segment starts decoding, so the frames around its first
+	// sample time are read like in a single run
+	segmentSeekLead = time.Second
 )
 
+// FFmpegConfig controls how frame extraction is executed
+type FFmpegConfig struct {
+	// SegmentConcurrency is the number of ffmpeg processes run concurrently for one video.
+	// Values <= 1 keep the single-process extraction.
+	SegmentConcurrency int
+	// MinSegmentDuration is the shortest probed duration, in seconds, that is split into segments
+	MinSegmentDuration float64
+	// Observer, when set, is told about every ffmpeg and ffprobe process run while processing a video
+	Observer CommandObserver
+}
+
+// CommandObserver receives the wall and CPU (user and system) time of the external processes once they exit
+type CommandObserver interface {
+	ObserveCommand(tool string, wall, cpu time.Duration, err error)
+}
+
 type FFmpegService struct {
 	fileManager port.FileManager
+	config      FFmpegConfig
+
+	versionOnce sync.Once
+	version     string
 }
 
-func NewFFmpegService(fileManager port.FileManager) port.VideoProcessor {
+func NewFFmpegService(fileManager port.FileManager, config FFmpegConfig) port.VideoProcessor {
 	return &FFmpegService{
 		fileManager: fileManager,
+		config:      config,
 	}
 }
 
+// frameSegment is a contiguous range of output frames extracted by a single ffmpeg process
+type frameSegment struct {
+	Index      int
+	StartFrame int
+	// FrameCount limits the frames produced by the segment; zero means "until the end of the video"
+	FrameCount int
+}
+
 // ProcessVideo processes video and extracts frames using FFmpeg
-func (s *FFmpegService) ProcessVideo(ctx context.Context, videoPath string, frameRate float64, outputFormat string) (int, string, error) {
+func (s *FFmpegService) ProcessVideo(ctx context.Context, videoPath string, config entity.ProcessingConfig) (*entity.FrameExtraction, error) {
 	// Create temporary directory for frames
 	tempDir, err := s.fileManager.CreateTempDir(ctx, "frames_")
 	if err != nil {
-		return 0, "", fmt.Errorf("failed to create temp directory: %w", err)
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
 	defer func() {
 		_ = s.fileManager.DeleteDir(ctx, tempDir)
 	}()
 
 	// Extract frames
-	framePaths, err := s.extractFrames(ctx, videoPath, frameRate, outputFormat, tempDir)
+	framePaths, commands, err := s.extract(ctx, videoPath, config, tempDir)
 	if err != nil {
-		return 0, "", fmt.Errorf("failed to extract frames: %w", err)
+		return nil, fmt.Errorf("failed to extract frames: %w", err)
 	}
 
 	if len(framePaths) == 0 {
-		return 0, "", fmt.Errorf("no frames extracted from video")
+		return nil, fmt.Errorf("no frames extracted from video")
 	}
 
 	// Create ZIP file
 	zipPath, err := s.fileManager.CreateTempFile(ctx, "frames_", ".zip")
 	if err != nil {
-		return 0, "", fmt.Errorf("failed to create temp zip file: %w", err)
+		return nil, fmt.Errorf("failed to create temp zip file: %w", err)
 	}
 
 	if err := s.createZipFromFiles(framePaths, zipPath); err != nil {
-		return 0, "", fmt.Errorf("failed to create zip file: %w", err)
+		return nil, fmt.Errorf("failed to create zip file: %w", err)
 	}
 
-	return len(framePaths), zipPath, nil
+	return &entity.FrameExtraction{
+		FrameCount:    len(framePaths),
+		ArchivePath:   zipPath,
+		Commands:      commands,
+		FFmpegVersion: s.ffmpegVersion(ctx),
+	}, nil
+}
+
+// ffmpegVersion returns the first line of `ffmpeg -version`, detected once; empty when it cannot be run
+func (s *FFmpegService) ffmpegVersion(ctx context.Context) string {
+	s.versionOnce.Do(func() {
+		if output, err := s.runTool(ctx, "ffmpeg", "-version"); err == nil {
+			s.version = firstLine(output)
+		}
+	})
+	return s.version
 }
 
 // ValidateVideo checks if video file is valid and can be processed
@@ -80,7 +141,7 @@ func (s *FFmpegService) ValidateVideo(ctx context.Context, videoPath string) err
 		videoPath,
 	)
 
-	output, err := cmd.CombinedOutput()
+	output, err := s.run(ctx, cmd, true)
 	if err != nil {
 		return fmt.Errorf("video validation failed: %w\nffprobe output:\n%s", err, string(output))
 	}
@@ -95,24 +156,122 @@ func (s *FFmpegService) ValidateVideo(ctx context.Context, videoPath string) err
 	return nil
 }
 
-func (s *FFmpegService) extractFrames(ctx context.Context, videoPath string, frameRate float64, outputFormat string, outputDir string) ([]string, error) {
-	framePattern := filepath.Join(outputDir, fmt.Sprintf("frame_%%04d.%s", outputFormat))
+// extract chooses between single-process and segmented extraction based on the probed duration. It returns the
+// frames and the ffmpeg command lines that were run.
+func (s *FFmpegService) extract(ctx context.Context, videoPath string, config entity.ProcessingConfig, outputDir string) ([]string, []string, error) {
+	if s.config.SegmentConcurrency <= 1 {
+		return s.extractSingle(ctx, videoPath, config, outputDir)
+	}
+
+	duration, err := s.probeDuration(ctx, videoPath)
+	if err == nil {
+		duration = windowDuration(duration, config)
+	}
+	if err != nil || duration < s.config.MinSegmentDuration {
+		// Unknown or short durations are not worth splitting
+		return s.extractSingle(ctx, videoPath, config, outputDir)
+	}
+
+	segments := planSegments(duration, config.FrameRate, s.config.SegmentConcurrency)
+	if len(segments) <= 1 {
+		return s.extractSingle(ctx, videoPath, config, outputDir)
+	}
+
+	return s.extractSegments(ctx, videoPath, config, outputDir, segments)
+}
+
+// extractSingle runs the whole extraction in one ffmpeg process
+func (s *FFmpegService) extractSingle(ctx context.Context, videoPath string, config entity.ProcessingConfig, outputDir string) ([]string, []string, error) {
+	paths, command, err := s.extractFrames(ctx, videoPath, config, outputDir, nil)
+	return paths, []string{command}, err
+}
+
+// windowDuration is the part of a video of the given duration covered by the configured time window
+func windowDuration(duration float64, config entity.ProcessingConfig) float64 {
+	if config.EndTime > 0 && config.EndTime < duration {
+		duration = config.EndTime
+	}
+	return math.Max(duration-config.StartTime, 0)
+}
+
+// extractSegments runs one ffmpeg process per segment concurrently and merges their frames
+func (s *FFmpegService) extractSegments(ctx context.Context, videoPath string, config entity.ProcessingConfig, outputDir string, segments []frameSegment) ([]string, []string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]string, len(segments))
+	commands := make([]string, len(segments))
+	errs := make([]error, len(segments))
+
+	var wg sync.WaitGroup
+	for i := range segments {
+		wg.Add(1)
+		go func(seg frameSegment) {
+			defer wg.Done()
+
+			segmentDir := filepath.Join(outputDir, fmt.Sprintf("segment_%03d", seg.Index))
+			if err := os.MkdirAll(segmentDir, 0o755); err != nil {
+				errs[seg.Index] = fmt.Errorf("failed to create segment directory: %w", err)
+				cancel()
+				return
+			}
+
+			paths, command, err := s.extractFrames(ctx, videoPath, config, segmentDir, &seg)
+			commands[seg.Index] = command
+			if err != nil {
+				errs[seg.Index] = fmt.Errorf("segment %d failed: %w", seg.Index, err)
+				cancel()
+				return
+			}
+			results[seg.Index] = paths
+		}(segments[i])
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, nil, err
+	}
+
+	return mergeSegmentFrames(results), commands, nil
+}
+
+// extractFrames runs one ffmpeg process, returning the frames it wrote and its command line. Frames are named after
+// their sample number in the time window, so segments and single runs name the same frame alike.
+func (s *FFmpegService) extractFrames(ctx context.Context, videoPath string, config entity.ProcessingConfig, outputDir string, segment *frameSegment) ([]string, string, error) {
+	framePattern := filepath.Join(outputDir, fmt.Sprintf("frame_%%04d.%s", config.OutputFormat))
 
 	args := []string{
 		"-nostdin",
 		"-loglevel", "error",
 		"-y",
+	}
+
+	// Input seeking is frame accurate when transcoding; timestamps restart at zero after the seek point
+	seek := toMicroseconds(config.StartTime) + segmentOffset(segment, config.FrameRate)
+	if seek > 0 {
+		args = append(args, "-ss", formatSeconds(seek.Seconds()))
+	}
+	if config.EndTime > 0 {
+		args = append(args, "-t", formatSeconds((toMicroseconds(config.EndTime) - seek).Seconds()))
+	}
+
+	args = append(args,
 		"-i", videoPath,
 		"-map", "0:v:0",
 		"-an",
-		"-vf", fmt.Sprintf("fps=%g", frameRate),
-		"-start_number", "0",
+		"-vf", videoFilter(config, segment),
+		// Every sampled frame is written under its sample number, without the gaps before a segment being filled
+		"-fps_mode", "passthrough",
 		"-f", "image2",
 		"-frame_pts", "1",
-	}
+	)
 
-	if outputFormat == "jpg" {
-		args = append(args, "-vcodec", "mjpeg", "-q:v", DefaultJPEGQuality)
+	if config.OutputFormat == "jpg" {
+		quality := DefaultJPEGQuality
+		if config.JPEGQuality > 0 {
+			quality = strconv.Itoa(config.JPEGQuality)
+		}
+		args = append(args, "-vcodec", "mjpeg", "-q:v", quality)
 	} else { // png
 		args = append(args, "-vcodec", "png")
 	}
@@ -120,19 +279,178 @@ func (s *FFmpegService) extractFrames(ctx context.Context, videoPath string, fra
 	args = append(args, framePattern)
 
 	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
-	output, err := cmd.CombinedOutput()
+	command := strings.Join(cmd.Args, " ")
+	output, err := s.run(ctx, cmd, true)
 	if err != nil {
-		return nil, fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, string(output))
+		return nil, command, fmt.Errorf("ffmpeg failed: %w\nOutput: %s", err, string(output))
 	}
 
-	pattern := fmt.Sprintf("*.%s", outputFormat)
+	pattern := fmt.Sprintf("*.%s", config.OutputFormat)
 	framePaths, err := s.fileManager.ListFiles(ctx, outputDir, pattern)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list frame files: %w", err)
+		return nil, command, fmt.Errorf("failed to list frame files: %w", err)
+	}
+
+	return framePaths, command, nil
+}
+
+// run runs cmd in a span named after the tool and reports the process to the observer once it exited. combined
+// returns stderr with stdout, where ffmpeg writes the details of a failure.
+func (s *FFmpegService) run(ctx context.Context, cmd *exec.Cmd, combined bool) ([]byte, error) {
+	tool := filepath.Base(cmd.Args[0])
+	_, span := tracing.Start(ctx, tool, attribute.StringSlice("process.command_args", cmd.Args[1:]))
+	start := time.Now()
+	var output []byte
+	var err error
+	if combined {
+		output, err = cmd.CombinedOutput()
+	} else {
+		output, err = cmd.Output()
 	}
+	s.observe(tool, cmd, start, err)
+	tracing.End(span, err)
+	return output, err
+}
 
-	return framePaths, nil
+// observe reports a process that exited to the observer; the CPU time is unknown when it could not be started
+func (s *FFmpegService) observe(tool string, cmd *exec.Cmd, start time.Time, err error) {
+	if s.config.Observer == nil {
+		return
+	}
+	var cpu time.Duration
+	if cmd.ProcessState != nil {
+		cpu = cmd.ProcessState.UserTime() + cmd.ProcessState.SystemTime()
+	}
+	s.config.Observer.ObserveCommand(tool, time.Since(start), cpu, err)
 }
+
+// videoFilter samples the frames at the frame rate and scales them when a size is set; -2 keeps the aspect ratio
+// with an even dimension, as most encoders require. A segment first shifts its timestamps back onto the timeline of
+// the whole window, so the same frames are sampled as in a single run, then keeps only its own samples by number.
+func videoFilter(config entity.ProcessingConfig, segment *frameSegment) string {
+	var filters []string
+	if offset := segmentOffset(segment, config.FrameRate); offset > 0 {
+		filters = append(filters, fmt.Sprintf("setpts=PTS+%s/TB", formatSeconds(offset.Seconds())))
+	}
+	filters = append(filters, fmt.Sprintf("fps=%g", config.FrameRate))
+	if segment != nil {
+		// After fps the time base is one sample, so timestamps are sample numbers
+		trim := fmt.Sprintf("trim=start_pts=%d", segment.StartFrame)
+		if segment.FrameCount > 0 {
+			trim += fmt.Sprintf(":end_pts=%d", segment.StartFrame+segment.FrameCount)
+		}
+		filters = append(filters, trim)
+	}
+	if config.Width > 0 || config.Height > 0 {
+		width, height := config.Width, config.Height
+		if width == 0 {
+			width = -2
+		}
+		if height == 0 {
+			height = -2
+		}
+		filters = append(filters, fmt.Sprintf("scale=%d:%d", width, height))
+	}
+	return strings.Join(filters, ",")
+}
+
+// segmentOffset is where a segment starts decoding, relative to the start of the window: segmentSeekLead and one
+// sample before its first sample time. It is zero without a segment.
+func segmentOffset(segment *frameSegment, frameRate float64) time.Duration {
+	if segment == nil {
+		return 0
+	}
+	first := toMicroseconds(float64(segment.StartFrame-1)/frameRate) - segmentSeekLead
+	return max(first, 0)
+}
+
+// toMicroseconds converts seconds to a duration rounded to the microsecond precision of ffmpeg time options, so
+// offsets added up in ffmpeg and here agree exactly
+func toMicroseconds(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds*1e6)) * time.Microsecond
+}
+
+// probeDuration returns the container duration in seconds as reported by ffprobe
+func (s *FFmpegService) probeDuration(ctx context.Context, videoPath string) (float64, error) {
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-show_entries", "format=duration",
+		"-of", "csv=p=0",
+		videoPath,
+	)
+
+	output, err := s.run(ctx, cmd, false)
+	if err != nil {
+		return 0, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	duration, err := strconv.ParseFloat(strings.TrimSpace(string(output)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", strings.TrimSpace(string(output)), err)
+	}
+
+	return duration, nil
+}
+
+// planSegments splits the expected output frames into at most n contiguous segments.
+// The last segment is left unbounded so frames past the probed duration are never lost.
+func planSegments(duration, frameRate float64, n int) []frameSegment {
+	totalFrames := int(math.Ceil(duration * frameRate))
+	if n > totalFrames {
+		n = totalFrames
+	}
+	if n <= 1 {
+		return []frameSegment{{Index: 0}}
+	}
+
+	segments := make([]frameSegment, 0, n)
+	base, remainder := totalFrames/n, totalFrames%n
+	start := 0
+	for i := 0; i < n; i++ {
+		count := base
+		if i < remainder {
+			count++
+		}
+		segment := frameSegment{Index: i, StartFrame: start, FrameCount: count}
+		if i == n-1 {
+			segment.FrameCount = 0
+		}
+		segments = append(segments, segment)
+		start += count
+	}
+
+	return segments
+}
+
+// mergeSegmentFrames flattens segment outputs ordered by frame number; names are compared by number since the
+// counter grows past its padding on long videos
+func mergeSegmentFrames(segments [][]string) []string {
+	var merged []string
+	for _, paths := range segments {
+		merged = append(merged, paths...)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return frameNumber(merged[i]) < frameNumber(merged[j])
+	})
+	return merged
+}
+
+// frameNumber parses the number of a frame_<n>.<ext> file; other names sort first
+func frameNumber(path string) int {
+	name := strings.TrimPrefix(filepath.Base(path), "frame_")
+	n, err := strconv.Atoi(strings.TrimSuffix(name, filepath.Ext(name)))
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// formatSeconds renders a timestamp with microsecond precision for ffmpeg time options
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 6, 64)
+}
+
 func (s *FFmpegService) createZipFromFiles(files []string, outputPath string) error {
 	// Create output file
 	zipFile, err := os.Create(outputPath)
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+)
+
+func TestPlanSegments(t *testing.T) {
+	t.Run("splits_frames_contiguously", func(t *testing.T) {
+		r := require.New(t)
+		segments := planSegments(10, 1.0, 3)
+
+		r.Equal([]frameSegment{
+			{Index: 0, StartFrame: 0, FrameCount: 4},
+			{Index: 1, StartFrame: 4, FrameCount: 3},
+			{Index: 2, StartFrame: 7, FrameCount: 0},
+		}, segments)
+	})
+
+	t.Run("caps_segments_to_frame_count", func(t *testing.T) {
+		r := require.New(t)
+		segments := planSegments(2, 1.0, 8)
+
+		r.Len(segments, 2)
+		r.Equal(1, segments[1].StartFrame)
+		r.Zero(segments[1].FrameCount)
+	})
+
+	t.Run("single_segment_for_tiny_videos", func(t *testing.T) {
+		r := require.New(t)
+		r.Equal([]frameSegment{{Index: 0}}, planSegments(0.2, 1.0, 4))
+	})
+}
+
+func TestMergeSegmentFrames(t *testing.T) {
+	r := require.New(t)
+	merged := mergeSegmentFrames([][]string{
+		{"/a/segment_000/frame_9998.jpg", "/a/segment_000/frame_9999.jpg"},
+		{"/a/segment_001/frame_10000.jpg", "/a/segment_001/frame_10001.jpg"},
+	})
+
+	// The counter outgrows its padding: frames are ordered by number, not by name
+	r.Equal([]string{
+		"/a/segment_000/frame_9998.jpg",
+		"/a/segment_000/frame_9999.jpg",
+		"/a/segment_001/frame_10000.jpg",
+		"/a/segment_001/frame_10001.jpg",
+	}, merged)
+}
+
+func TestVideoFilter(t *testing.T) {
+	r := require.New(t)
+	r.Equal("fps=2", videoFilter(entity.ProcessingConfig{FrameRate: 2}, nil))
+	r.Equal("fps=0.5,scale=640:-2", videoFilter(entity.ProcessingConfig{FrameRate: 0.5, Width: 640}, nil))
+	r.Equal("fps=1,scale=320:240", videoFilter(entity.ProcessingConfig{FrameRate: 1, Width: 320, Height: 240}, nil))
+
+	// Segments sample the timeline of the whole window and keep their own frames
+	r.Equal("fps=2,trim=start_pts=0:end_pts=3", videoFilter(entity.ProcessingConfig{FrameRate: 2}, &frameSegment{FrameCount: 3}))
+	r.Equal("setpts=PTS+8.500000/TB,fps=2,trim=start_pts=20:end_pts=30,scale=640:-2",
+		videoFilter(entity.ProcessingConfig{FrameRate: 2, Width: 640}, &frameSegment{StartFrame: 20, FrameCount: 10}))
+	r.Equal("setpts=PTS+8.500000/TB,fps=2,trim=start_pts=20",
+		videoFilter(entity.ProcessingConfig{FrameRate: 2}, &frameSegment{StartFrame: 20}))
+}
+
+func TestSegmentOffset(t *testing.T) {
+	r := require.New(t)
+	r.Zero(segmentOffset(nil, 2))
+	r.Zero(segmentOffset(&frameSegment{StartFrame: 2}, 2))
+	// One sample and the lead before the first sample at 10s
+	r.Equal(8500*time.Millisecond, segmentOffset(&frameSegment{StartFrame: 20}, 2))
+	r.Equal(time.Duration(2333333)*time.Microsecond, segmentOffset(&frameSegment{StartFrame: 11}, 3))
+}
+
+func TestWindowDuration(t *testing.T) {
+	r := require.New(t)
+	r.Equal(12.0, windowDuration(12, entity.ProcessingConfig{}))
+	r.Equal(8.0, windowDuration(12, entity.ProcessingConfig{StartTime: 4}))
+	r.Equal(3.0, windowDuration(12, entity.ProcessingConfig{StartTime: 2, EndTime: 5}))
+	r.Equal(10.0, windowDuration(12, entity.ProcessingConfig{StartTime: 2, EndTime: 30}))
+	r.Zero(windowDuration(12, entity.ProcessingConfig{StartTime: 20}))
+}
+
+func TestFFmpegService_SegmentedMatchesSingleProcess(t *testing.T) {
+	for _, tool := range []string{"ffmpeg", "ffprobe"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			// CI installs ffmpeg, so this test never goes silently unrun there
+			if os.Getenv("CI") != "" {
+				t.Fatalf("%s not available in CI", tool)
+			}
+			t.Skipf("%s not available", tool)
+		}
+	}
+
+	r := require.New(t)
+	ctx := context.Background()
+
+	videoPath := filepath.Join(t.TempDir(), "input.mp4")
+	gen := exec.CommandContext(ctx, "ffmpeg", "-nostdin", "-loglevel", "error", "-y",
+		"-f", "lavfi", "-i", "testsrc=duration=12:size=160x120:rate=25",
+		"-c:v", "libx264", "-g", "50", "-pix_fmt", "yuv420p", videoPath)
+	out, err := gen.CombinedOutput()
+	r.NoError(err, string(out))
+
+	fm := NewLocalFileService(t.TempDir())
+	single := NewFFmpegService(fm, FFmpegConfig{SegmentConcurrency: 1})
+	segmented := NewFFmpegService(fm, FFmpegConfig{SegmentConcurrency: 4, MinSegmentDuration: 0})
+
+	for name, config := range map[string]entity.ProcessingConfig{
+		"whole_video": {FrameRate: 2.0, OutputFormat: "png"},
+		// A window and a rate that put the segment boundaries between source frames
+		"window": {FrameRate: 3.0, OutputFormat: "png", StartTime: 1.3, EndTime: 10.7},
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			singleRun, err := single.ProcessVideo(ctx, videoPath, config)
+			r.NoError(err)
+			defer func() { _ = fm.DeleteFile(ctx, singleRun.ArchivePath) }()
+
+			segmentedRun, err := segmented.ProcessVideo(ctx, videoPath, config)
+			r.NoError(err)
+			defer func() { _ = fm.DeleteFile(ctx, segmentedRun.ArchivePath) }()
+
+			r.Equal(singleRun.FrameCount, segmentedRun.FrameCount)
+			r.Equal(zipDigests(t, singleRun.ArchivePath), zipDigests(t, segmentedRun.ArchivePath))
+			r.Len(singleRun.Commands, 1)
+			r.Len(segmentedRun.Commands, 4)
+			r.Contains(segmentedRun.Commands[1], "trim=start_pts=")
+			r.True(strings.HasPrefix(singleRun.FFmpegVersion, "ffmpeg version"))
+		})
+	}
+}
+
+// commandRecorder records the processes reported to a CommandObserver
+type commandRecorder struct {
+	tools []string
+	errs  []error
+}
+
+func (c *commandRecorder) ObserveCommand(tool string, wall, cpu time.Duration, err error) {
+	c.tools = append(c.tools, tool)
+	c.errs = append(c.errs, err)
+}
+
+func TestFFmpegService_ObservesCommands(t *testing.T) {
+	r := require.New(t)
+	videoPath := filepath.Join(t.TempDir(), "not-a-video.mp4")
+	r.NoError(os.WriteFile(videoPath, []byte("not a video"), 0o644))
+	recorder := &commandRecorder{}
+	svc := NewFFmpegService(NewLocalFileService(t.TempDir()), FFmpegConfig{Observer: recorder})
+
+	// ffprobe rejects the file, or is not installed: the failed process is reported either way
+	r.Error(svc.ValidateVideo(context.Background(), videoPath))
+	r.Equal([]string{"ffprobe"}, recorder.tools)
+	r.Error(recorder.errs[0])
+}
+
+// zipDigests maps each entry name in a zip archive to the SHA-256 of its content
+func zipDigests(t *testing.T, path string) map[string]string {
+	t.Helper()
+	r := require.New(t)
+
+	zr, err := zip.OpenReader(path)
+	r.NoError(err)
+	defer func() { _ = zr.Close() }()
+
+	digests := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		r.NoError(err)
+		h := sha256.New()
+		_, err = io.Copy(h, rc)
+		r.NoError(err)
+		r.NoError(rc.Close())
+		digests[f.Name] = hex.EncodeToString(h.Sum(nil))
+	}
+	return digests
+}
@@ -6,31 +6,33 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-
-	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
 )
 
-// LocalFileService implements file operations for containerized execution environment
+// LocalFileService implements file operations for containerized execution environment. Temp files are created in
+// the workspace of the context, if any, and in the root directory otherwise.
 type LocalFileService struct {
-	tempDir string
+	root string
 }
 
-// NewLocalFileService creates a new local file service
-func NewLocalFileService() port.FileManager {
-	tempDir := "/tmp" // Container temp directory
-	if runtime := os.Getenv("CONTAINER_RUNTIME"); runtime == "" {
-		// Running locally, use system temp
-		tempDir = os.TempDir()
+// NewLocalFileService creates a new local file service keeping its workspaces under root; an empty root is the
+// system temp directory
+func NewLocalFileService(root string) *LocalFileService {
+	if root == "" {
+		root = "/tmp" // Container temp directory
+		if runtime := os.Getenv("CONTAINER_RUNTIME"); runtime == "" {
+			// Running locally, use system temp
+			root = os.TempDir()
+		}
 	}
 
 	return &LocalFileService{
-		tempDir: tempDir,
+		root: root,
 	}
 }
 
 // CreateTempFile creates a temporary file with given prefix and suffix
 func (s *LocalFileService) CreateTempFile(ctx context.Context, prefix, suffix string) (string, error) {
-	file, err := os.CreateTemp(s.tempDir, prefix+"*"+suffix)
+	file, err := os.CreateTemp(s.dir(ctx), prefix+"*"+suffix)
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -45,7 +47,7 @@ func (s *LocalFileService) CreateTempFile(ctx context.Context, prefix, suffix st
 
 // CreateTempDir creates a temporary directory with given prefix
 func (s *LocalFileService) CreateTempDir(ctx context.Context, prefix string) (string, error) {
-	dir, err := os.MkdirTemp(s.tempDir, prefix+"*")
+	dir, err := os.MkdirTemp(s.dir(ctx), prefix+"*")
 	if err != nil {
 		return "", fmt.Errorf("failed to create temp directory: %w", err)
 	}
@@ -68,6 +70,7 @@ func (s *LocalFileService) WriteToFile(ctx context.Context, filePath string, dat
 		return fmt.Errorf("failed to write to file: %w", err)
 	}
 
+	s.measure(ctx)
 	return nil
 }
 
@@ -83,6 +86,8 @@ func (s *LocalFileService) ReadFile(ctx context.Context, filePath string) (io.Re
 
 // DeleteFile deletes a file
 func (s *LocalFileService) DeleteFile(ctx context.Context, filePath string) error {
+	// The workspace is at its fullest right before a cleanup
+	s.measure(ctx)
 	if err := os.Remove(filePath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
@@ -92,6 +97,7 @@ func (s *LocalFileService) DeleteFile(ctx context.Context, filePath string) erro
 
 // DeleteDir deletes a directory and all its contents
 func (s *LocalFileService) DeleteDir(ctx context.Context, dirPath string) error {
+	s.measure(ctx)
 	if err := os.RemoveAll(dirPath); err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("failed to delete directory: %w", err)
 	}
@@ -116,6 +122,7 @@ func (s *LocalFileService) ListFiles(ctx context.Context, dirPath, pattern strin
 		}
 	}
 
+	s.measure(ctx)
 	return files, nil
 }
 
@@ -126,5 +133,6 @@ func (s *LocalFileService) GetFileSize(ctx context.Context, filePath string) (in
 		return 0, fmt.Errorf("failed to get file size: %w", err)
 	}
 
+	s.measure(ctx)
 	return info.Size(), nil
 }
@@ -12,7 +12,7 @@ import (
 
 func TestLocalFileService(t *testing.T) {
 	r := require.New(t)
-	s := NewLocalFileService().(*LocalFileService)
+	s := NewLocalFileService(t.TempDir())
 
 	// Temp file
 	f, err := s.CreateTempFile(context.Background(), "file_", ".txt")
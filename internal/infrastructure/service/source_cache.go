@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+)
+
+const (
+	cacheVideoSuffix = ".video"
+	cacheEntrySuffix = ".json"
+)
+
+// FileSourceCache is an LRU of original videos in a local directory, bounded by the total size of the videos. Each
+// video is stored next to a JSON entry naming its revision and SHA-256; the modification time of the entry records
+// its last use.
+type FileSourceCache struct {
+	dir      string
+	bucket   string
+	maxBytes int64
+	now      func() time.Time
+
+	// mu serializes the changes to the directory
+	mu sync.Mutex
+}
+
+// cacheEntry describes a cached video
+type cacheEntry struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	ETag   string `json:"etag"`
+	Hash   string `json:"hash"`
+	Size   int64  `json:"size"`
+}
+
+// NewFileSourceCache creates a cache of the videos of bucket in dir, holding at most maxBytes of videos
+func NewFileSourceCache(dir, bucket string, maxBytes int64) (*FileSourceCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create source cache directory: %w", err)
+	}
+	return &FileSourceCache{dir: dir, bucket: bucket, maxBytes: maxBytes, now: time.Now}, nil
+}
+
+// Fetch links, or copies when the cache is on another volume, the cached revision of key to path. Revisions without
+// an ETag are never cached.
+func (c *FileSourceCache) Fetch(ctx context.Context, key string, version entity.ObjectVersion, path string) (string, bool, error) {
+	if version.ETag == "" {
+		return "", false, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.id(key, version.ETag)
+	entry, err := c.readEntry(id)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if entry.Bucket != c.bucket || entry.Key != key || entry.ETag != version.ETag {
+		return "", false, nil
+	}
+	videoPath := c.path(id, cacheVideoSuffix)
+	if info, err := os.Stat(videoPath); err != nil || info.Size() != entry.Size {
+		// A video missing or cut short, e.g. by a crash while it was stored, is dropped
+		c.remove(id)
+		return "", false, nil
+	}
+
+	if err := linkOrCopy(videoPath, path); err != nil {
+		return "", false, fmt.Errorf("failed to copy cached video: %w", err)
+	}
+	now := c.now()
+	_ = os.Chtimes(c.path(id, cacheEntrySuffix), now, now)
+	return entry.Hash, true, nil
+}
+
+// Store adds the video at path to the cache, evicting the least recently used videos to stay within the size limit.
+// Videos larger than the limit, or without an ETag, are not cached.
+func (c *FileSourceCache) Store(ctx context.Context, key string, version entity.ObjectVersion, path, hash string) error {
+	if version.ETag == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to stat video: %w", err)
+	}
+	if info.Size() > c.maxBytes {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.id(key, version.ETag)
+	c.remove(id)
+	if err := c.evict(c.maxBytes - info.Size()); err != nil {
+		return err
+	}
+
+	// The video and then its entry are renamed into place, so an entry always names a complete video
+	tmpVideo := c.path(id, cacheVideoSuffix+".tmp")
+	if err := linkOrCopy(path, tmpVideo); err != nil {
+		_ = os.Remove(tmpVideo)
+		return fmt.Errorf("failed to copy video to the cache: %w", err)
+	}
+	if err := os.Rename(tmpVideo, c.path(id, cacheVideoSuffix)); err != nil {
+		_ = os.Remove(tmpVideo)
+		return fmt.Errorf("failed to store cached video: %w", err)
+	}
+	entry, err := json.Marshal(cacheEntry{Bucket: c.bucket, Key: key, ETag: version.ETag, Hash: hash, Size: info.Size()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	tmpEntry := c.path(id, cacheEntrySuffix+".tmp")
+	if err := os.WriteFile(tmpEntry, entry, 0o644); err != nil {
+		_ = os.Remove(tmpEntry)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmpEntry, c.path(id, cacheEntrySuffix)); err != nil {
+		_ = os.Remove(tmpEntry)
+		return fmt.Errorf("failed to store cache entry: %w", err)
+	}
+	return nil
+}
+
+// evict removes the least recently used videos until the cached videos take at most limit bytes
+func (c *FileSourceCache) evict(limit int64) error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to list source cache: %w", err)
+	}
+
+	type cached struct {
+		id       string
+		size     int64
+		lastUsed time.Time
+	}
+	var entries []cached
+	var total int64
+	for _, file := range files {
+		id, ok := strings.CutSuffix(file.Name(), cacheEntrySuffix)
+		if !ok {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		entry, err := c.readEntry(id)
+		if err != nil {
+			c.remove(id)
+			continue
+		}
+		entries = append(entries, cached{id: id, size: entry.Size, lastUsed: info.ModTime()})
+		total += entry.Size
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].lastUsed.Before(entries[j].lastUsed) })
+	for _, entry := range entries {
+		if total <= limit {
+			break
+		}
+		c.remove(entry.id)
+		total -= entry.size
+	}
+	return nil
+}
+
+// id names the files of a revision after its bucket, key and ETag
+func (c *FileSourceCache) id(key, etag string) string {
+	sum := sha256.Sum256([]byte(c.bucket + "\x00" + key + "\x00" + etag))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *FileSourceCache) path(id, suffix string) string {
+	return filepath.Join(c.dir, id+suffix)
+}
+
+func (c *FileSourceCache) readEntry(id string) (*cacheEntry, error) {
+	data, err := os.ReadFile(c.path(id, cacheEntrySuffix))
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("invalid cache entry %s: %w", id, err)
+	}
+	return &entry, nil
+}
+
+// remove drops a cached video, entry first so a video is never named by an entry while it is being removed
+func (c *FileSourceCache) remove(id string) {
+	_ = os.Remove(c.path(id, cacheEntrySuffix))
+	_ = os.Remove(c.path(id, cacheVideoSuffix))
+}
+
+// linkOrCopy makes dst a hard link to src, or a copy of it when they are on different volumes. An existing dst is
+// replaced.
+func linkOrCopy(src, dst string) error {
+	if err := os.Remove(dst); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+)
+
+func TestFileSourceCache(t *testing.T) {
+	ctx := context.Background()
+	v1 := entity.ObjectVersion{ETag: `"etag-1"`}
+
+	// video writes a local video of the given content
+	video := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "video.mp4")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	t.Run("stored_revision_is_fetched_with_its_hash", func(t *testing.T) {
+		r := require.New(t)
+		cache, err := NewFileSourceCache(t.TempDir(), "raw", 1024)
+		r.NoError(err)
+
+		_, found, err := cache.Fetch(ctx, "a.mp4", v1, filepath.Join(t.TempDir(), "miss.mp4"))
+		r.NoError(err)
+		r.False(found)
+
+		r.NoError(cache.Store(ctx, "a.mp4", v1, video(t, "video a"), "hash-a"))
+
+		dst := filepath.Join(t.TempDir(), "hit.mp4")
+		r.NoError(os.WriteFile(dst, nil, 0o600))
+		hash, found, err := cache.Fetch(ctx, "a.mp4", v1, dst)
+		r.NoError(err)
+		r.True(found)
+		r.Equal("hash-a", hash)
+		content, err := os.ReadFile(dst)
+		r.NoError(err)
+		r.Equal("video a", string(content))
+
+		// Another ETag, key or bucket is another revision
+		_, found, err = cache.Fetch(ctx, "a.mp4", entity.ObjectVersion{ETag: `"etag-2"`}, dst)
+		r.NoError(err)
+		r.False(found)
+		_, found, err = cache.Fetch(ctx, "b.mp4", v1, dst)
+		r.NoError(err)
+		r.False(found)
+		other, err := NewFileSourceCache(cache.dir, "other", 1024)
+		r.NoError(err)
+		_, found, err = other.Fetch(ctx, "a.mp4", v1, dst)
+		r.NoError(err)
+		r.False(found)
+	})
+
+	t.Run("least_recently_used_videos_are_evicted", func(t *testing.T) {
+		r := require.New(t)
+		cache, err := NewFileSourceCache(t.TempDir(), "raw", 20)
+		r.NoError(err)
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		cache.now = func() time.Time { return now }
+
+		r.NoError(cache.Store(ctx, "a.mp4", v1, video(t, strings.Repeat("a", 8)), "hash-a"))
+		r.NoError(cache.Store(ctx, "b.mp4", v1, video(t, strings.Repeat("b", 8)), "hash-b"))
+		// Entries are ordered by their modification time: make a the oldest, then use it again
+		r.NoError(os.Chtimes(cache.path(cache.id("a.mp4", v1.ETag), cacheEntrySuffix), now.Add(-time.Hour), now.Add(-time.Hour)))
+		r.NoError(os.Chtimes(cache.path(cache.id("b.mp4", v1.ETag), cacheEntrySuffix), now.Add(-time.Minute), now.Add(-time.Minute)))
+		_, found, err := cache.Fetch(ctx, "a.mp4", v1, filepath.Join(t.TempDir(), "a.mp4"))
+		r.NoError(err)
+		r.True(found)
+
+		// c needs room: b is now the least recently used
+		r.NoError(cache.Store(ctx, "c.mp4", v1, video(t, strings.Repeat("c", 8)), "hash-c"))
+		for key, cached := range map[string]bool{"a.mp4": true, "b.mp4": false, "c.mp4": true} {
+			_, found, err := cache.Fetch(ctx, key, v1, filepath.Join(t.TempDir(), key))
+			r.NoError(err)
+			r.Equal(cached, found, key)
+		}
+	})
+
+	t.Run("uncacheable_videos_are_skipped", func(t *testing.T) {
+		r := require.New(t)
+		dir := t.TempDir()
+		cache, err := NewFileSourceCache(dir, "raw", 4)
+		r.NoError(err)
+
+		r.NoError(cache.Store(ctx, "large.mp4", v1, video(t, "too large"), "hash"))
+		r.NoError(cache.Store(ctx, "no-etag.mp4", entity.ObjectVersion{VersionId: "v1"}, video(t, "ok"), "hash"))
+		entries, err := os.ReadDir(dir)
+		r.NoError(err)
+		r.Empty(entries)
+	})
+
+	t.Run("truncated_video_is_dropped", func(t *testing.T) {
+		r := require.New(t)
+		cache, err := NewFileSourceCache(t.TempDir(), "raw", 1024)
+		r.NoError(err)
+		r.NoError(cache.Store(ctx, "a.mp4", v1, video(t, "video a"), "hash-a"))
+		id := cache.id("a.mp4", v1.ETag)
+		r.NoError(os.Truncate(cache.path(id, cacheVideoSuffix), 3))
+
+		_, found, err := cache.Fetch(ctx, "a.mp4", v1, filepath.Join(t.TempDir(), "a.mp4"))
+		r.NoError(err)
+		r.False(found)
+		r.NoFileExists(cache.path(id, cacheEntrySuffix))
+	})
+}
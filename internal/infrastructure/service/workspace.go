@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// workspacePrefix names the workspace directories under the root, so orphaned ones can be found
+	workspacePrefix = "workspace-"
+
+	// workspaceLockFile is held locked by the process using the workspace
+	workspaceLockFile = ".lock"
+
+	// workspaceLockGracePeriod is how old a workspace without a lock file must be to be swept. A run locks its
+	// workspace right after creating it, so an older one was left by a run that crashed in between.
+	workspaceLockGracePeriod = 10 * time.Minute
+)
+
+type workspaceKey struct{}
+
+// workspace is the directory holding every temp file of one job
+type workspace struct {
+	dir  string
+	lock *os.File
+
+	mu sync.Mutex
+	// peak is the largest size of the files in the workspace measured so far
+	peak int64
+}
+
+// dir returns the directory temp files of ctx are created in
+func (s *LocalFileService) dir(ctx context.Context) string {
+	if ws := workspaceFromContext(ctx); ws != nil {
+		return ws.dir
+	}
+	return s.root
+}
+
+func workspaceFromContext(ctx context.Context) *workspace {
+	ws, _ := ctx.Value(workspaceKey{}).(*workspace)
+	return ws
+}
+
+// OpenWorkspace creates a workspace under the root, locked until it is closed so a sweep of another process
+// leaves it alone
+func (s *LocalFileService) OpenWorkspace(ctx context.Context) (context.Context, error) {
+	if err := os.MkdirAll(s.root, 0o755); err != nil {
+		return ctx, fmt.Errorf("failed to create workspace root: %w", err)
+	}
+	dir, err := os.MkdirTemp(s.root, workspacePrefix+"*")
+	if err != nil {
+		return ctx, fmt.Errorf("failed to create workspace: %w", err)
+	}
+	lock, err := lockWorkspace(filepath.Join(dir, workspaceLockFile))
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return ctx, fmt.Errorf("failed to lock workspace: %w", err)
+	}
+	return context.WithValue(ctx, workspaceKey{}, &workspace{dir: dir, lock: lock}), nil
+}
+
+// EnsureSpace fails when the volume of the workspace of ctx has fewer than required bytes available. Platforms
+// that cannot tell the available space are not checked.
+func (s *LocalFileService) EnsureSpace(ctx context.Context, required int64) error {
+	dir := s.dir(ctx)
+	available, err := AvailableSpace(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read available space: %w", err)
+	}
+	if available > 0 && available < required {
+		return fmt.Errorf("%d bytes needed in %s, only %d available", required, dir, available)
+	}
+	return nil
+}
+
+// CloseWorkspace removes the workspace of ctx and everything left in it, returning the most bytes it held
+func (s *LocalFileService) CloseWorkspace(ctx context.Context) (int64, error) {
+	ws := workspaceFromContext(ctx)
+	if ws == nil {
+		return 0, nil
+	}
+	s.measure(ctx)
+	_ = ws.lock.Close()
+	if err := os.RemoveAll(ws.dir); err != nil {
+		return ws.peakUsage(), fmt.Errorf("failed to remove workspace: %w", err)
+	}
+	return ws.peakUsage(), nil
+}
+
+// SweepOrphans removes the workspaces under the root left behind by processes that crashed, returning how many
+// were removed
+func (s *LocalFileService) SweepOrphans() (int, error) {
+	entries, err := os.ReadDir(s.root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+
+	removed := 0
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), workspacePrefix) {
+			continue
+		}
+		dir := filepath.Join(s.root, entry.Name())
+		if !isOrphaned(filepath.Join(dir, workspaceLockFile)) && !isStaleWithoutLock(dir, time.Now()) {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove workspace %s: %w", dir, err))
+			continue
+		}
+		removed++
+	}
+	return removed, errors.Join(errs...)
+}
+
+// isStaleWithoutLock reports whether the workspace dir has no lock file and is older than workspaceLockGracePeriod
+func isStaleWithoutLock(dir string, now time.Time) bool {
+	if _, err := os.Stat(filepath.Join(dir, workspaceLockFile)); !errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	info, err := os.Stat(dir)
+	return err == nil && now.Sub(info.ModTime()) > workspaceLockGracePeriod
+}
+
+// Root returns the directory the workspaces are created in
+func (s *LocalFileService) Root() string {
+	return s.root
+}
+
+// measure records the bytes used by the workspace of ctx, if any
+func (s *LocalFileService) measure(ctx context.Context) {
+	ws := workspaceFromContext(ctx)
+	if ws == nil {
+		return
+	}
+	var used int64
+	_ = filepath.WalkDir(ws.dir, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			used += info.Size()
+		}
+		return nil
+	})
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.peak = max(ws.peak, used)
+}
+
+func (ws *workspace) peakUsage() int64 {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return ws.peak
+}
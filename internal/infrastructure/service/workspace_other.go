@@ -0,0 +1,20 @@
+//go:build !unix
+
+package service
+
+import "os"
+
+// AvailableSpace is not supported on this platform; zero means unknown
+func AvailableSpace(dir string) (int64, error) {
+	return 0, nil
+}
+
+// lockWorkspace only creates the lock file: locks are not supported on this platform
+func lockWorkspace(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+}
+
+// isOrphaned is always false: without locks, a workspace in use cannot be told apart from an orphaned one
+func isOrphaned(path string) bool {
+	return false
+}
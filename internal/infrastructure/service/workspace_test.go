@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalFileService_Workspace(t *testing.T) {
+	t.Run("temp_files_live_in_the_workspace_until_it_is_closed", func(t *testing.T) {
+		r := require.New(t)
+		root := t.TempDir()
+		s := NewLocalFileService(root)
+
+		ctx, err := s.OpenWorkspace(context.Background())
+		r.NoError(err)
+		f, err := s.CreateTempFile(ctx, "video_", ".mp4")
+		r.NoError(err)
+		r.NoError(s.WriteToFile(ctx, f, strings.NewReader("0123456789")))
+		dir, err := s.CreateTempDir(ctx, "frames_")
+		r.NoError(err)
+
+		workspaceDir := filepath.Dir(f)
+		r.Equal(root, filepath.Dir(workspaceDir))
+		r.True(strings.HasPrefix(filepath.Base(workspaceDir), workspacePrefix))
+		r.Equal(workspaceDir, filepath.Dir(dir))
+
+		// Files outside the workspace context stay in the root
+		outside, err := s.CreateTempFile(context.Background(), "other_", ".txt")
+		r.NoError(err)
+		r.Equal(root, filepath.Dir(outside))
+
+		r.NoError(s.DeleteFile(ctx, f))
+		peak, err := s.CloseWorkspace(ctx)
+		r.NoError(err)
+		r.Equal(int64(10), peak)
+		_, err = os.Stat(workspaceDir)
+		r.True(os.IsNotExist(err))
+	})
+
+	t.Run("usage_is_sampled_before_cleanup", func(t *testing.T) {
+		r := require.New(t)
+		s := NewLocalFileService(t.TempDir())
+
+		// Frames are written by ffmpeg, not through the service
+		ctx, err := s.OpenWorkspace(context.Background())
+		r.NoError(err)
+		dir, err := s.CreateTempDir(ctx, "frames_")
+		r.NoError(err)
+		r.NoError(os.WriteFile(filepath.Join(dir, "frame_1.png"), []byte("0123456789"), 0o600))
+
+		r.NoError(s.DeleteDir(ctx, dir))
+		peak, err := s.CloseWorkspace(ctx)
+		r.NoError(err)
+		r.Equal(int64(10), peak)
+	})
+
+	t.Run("ensure_space_fails_when_the_volume_is_too_small", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("available space is not known on this platform")
+		}
+		r := require.New(t)
+		s := NewLocalFileService(t.TempDir())
+
+		r.NoError(s.EnsureSpace(context.Background(), 1))
+		err := s.EnsureSpace(context.Background(), math.MaxInt64)
+		r.ErrorContains(err, "only")
+	})
+
+	t.Run("sweep_removes_only_orphaned_workspaces", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("workspace locks are not supported on this platform")
+		}
+		r := require.New(t)
+		root := t.TempDir()
+		s := NewLocalFileService(root)
+
+		// An open workspace is locked; a crashed run leaves an unlocked one behind
+		ctx, err := s.OpenWorkspace(context.Background())
+		r.NoError(err)
+		active := s.dir(ctx)
+		orphan := filepath.Join(root, workspacePrefix+"crashed")
+		r.NoError(os.MkdirAll(filepath.Join(orphan, "frames_1"), 0o755))
+		r.NoError(os.WriteFile(filepath.Join(orphan, workspaceLockFile), nil, 0o600))
+		unrelated := filepath.Join(root, "outbox")
+		r.NoError(os.Mkdir(unrelated, 0o755))
+
+		removed, err := s.SweepOrphans()
+		r.NoError(err)
+		r.Equal(1, removed)
+		r.NoDirExists(orphan)
+		r.DirExists(active)
+		r.DirExists(unrelated)
+
+		_, err = s.CloseWorkspace(ctx)
+		r.NoError(err)
+	})
+
+	t.Run("sweep_removes_stale_workspaces_without_a_lock", func(t *testing.T) {
+		r := require.New(t)
+		root := t.TempDir()
+		s := NewLocalFileService(root)
+
+		// A run that crashed before locking its workspace leaves no lock file behind
+		stale := filepath.Join(root, workspacePrefix+"stale")
+		r.NoError(os.Mkdir(stale, 0o755))
+		old := time.Now().Add(-2 * workspaceLockGracePeriod)
+		r.NoError(os.Chtimes(stale, old, old))
+		fresh := filepath.Join(root, workspacePrefix+"fresh")
+		r.NoError(os.Mkdir(fresh, 0o755))
+
+		removed, err := s.SweepOrphans()
+		r.NoError(err)
+		r.Equal(1, removed)
+		r.NoDirExists(stale)
+		r.DirExists(fresh)
+	})
+
+	t.Run("sweep_of_a_missing_root_is_a_no_op", func(t *testing.T) {
+		r := require.New(t)
+		s := NewLocalFileService(filepath.Join(t.TempDir(), "missing"))
+		removed, err := s.SweepOrphans()
+		r.NoError(err)
+		r.Zero(removed)
+	})
+}
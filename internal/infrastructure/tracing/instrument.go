@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port"
+)
+
+// TraceStorageDataSource records a client span for every storage call
+func TraceStorageDataSource(next port.StorageDataSource) port.StorageDataSource {
+	return &storageDataSource{next: next}
+}
+
+type storageDataSource struct {
+	next port.StorageDataSource
+}
+
+func startStorage(ctx context.Context, operation, key string) (context.Context, trace.Span) {
+	return otelTracer().Start(ctx, "storage."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("storage.key", key)))
+}
+
+func (s *storageDataSource) DownloadVideo(ctx context.Context, key string, version entity.ObjectVersion) (io.ReadCloser, entity.ObjectVersion, error) {
+	ctx, span := startStorage(ctx, "DownloadVideo", key)
+	body, read, err := s.next.DownloadVideo(ctx, key, version)
+	End(span, err)
+	return body, read, err
+}
+
+func (s *storageDataSource) UploadProcessedFile(ctx context.Context, key string, data io.Reader, contentType string, size int64) (string, error) {
+	ctx, span := startStorage(ctx, "UploadProcessedFile", key)
+	span.SetAttributes(attribute.Int64("storage.size", size))
+	location, err := s.next.UploadProcessedFile(ctx, key, data, contentType, size)
+	End(span, err)
+	return location, err
+}
+
+func (s *storageDataSource) StatProcessedFile(ctx context.Context, key string) (int64, error) {
+	ctx, span := startStorage(ctx, "StatProcessedFile", key)
+	size, err := s.next.StatProcessedFile(ctx, key)
+	End(span, err)
+	return size, err
+}
+
+func (s *storageDataSource) DeleteProcessedFile(ctx context.Context, key string) error {
+	ctx, span := startStorage(ctx, "DeleteProcessedFile", key)
+	err := s.next.DeleteProcessedFile(ctx, key)
+	End(span, err)
+	return err
+}
+
+func (s *storageDataSource) DeleteVideo(ctx context.Context, key string, version entity.ObjectVersion) error {
+	ctx, span := startStorage(ctx, "DeleteVideo", key)
+	err := s.next.DeleteVideo(ctx, key, version)
+	End(span, err)
+	return err
+}
+
+func (s *storageDataSource) CopyVideo(ctx context.Context, key string, version entity.ObjectVersion, destinationBucket, destinationKey string) error {
+	ctx, span := startStorage(ctx, "CopyVideo", key)
+	err := s.next.CopyVideo(ctx, key, version, destinationBucket, destinationKey)
+	End(span, err)
+	return err
+}
+
+func (s *storageDataSource) TagVideo(ctx context.Context, key string, version entity.ObjectVersion, tags map[string]string) error {
+	ctx, span := startStorage(ctx, "TagVideo", key)
+	err := s.next.TagVideo(ctx, key, version, tags)
+	End(span, err)
+	return err
+}
+
+func (s *storageDataSource) StatVideo(ctx context.Context, key string) (*entity.StorageObject, error) {
+	ctx, span := startStorage(ctx, "StatVideo", key)
+	object, err := s.next.StatVideo(ctx, key)
+	End(span, err)
+	return object, err
+}
+
+func (s *storageDataSource) ListVideos(ctx context.Context, prefix string) ([]entity.StorageObject, error) {
+	ctx, span := startStorage(ctx, "ListVideos", prefix)
+	objects, err := s.next.ListVideos(ctx, prefix)
+	End(span, err)
+	return objects, err
+}
+
+// TraceMessageBroker records a producer span for every message published to destination, and adds the trace
+// context to the message attributes so consumers can continue the trace. A message published without a trace in
+// ctx, e.g. replayed from the outbox, continues the trace recorded in its attributes.
+func TraceMessageBroker(next port.MessageBroker, destination string) port.MessageBroker {
+	return &messageBroker{next: next, destination: destination}
+}
+
+type messageBroker struct {
+	next        port.MessageBroker
+	destination string
+}
+
+func (b *messageBroker) PublishMessage(ctx context.Context, message entity.Message) error {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		ctx = WithTraceParent(ctx, message.Attributes[TraceParentAttribute])
+	}
+	ctx, span := otelTracer().Start(ctx, "publish "+b.destination,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.destination.name", b.destination),
+			attribute.String("messaging.message.id", message.ID),
+		))
+	message.Attributes = injectAttributes(ctx, message.Attributes)
+	err := b.next.PublishMessage(ctx, message)
+	End(span, err)
+	return err
+}
@@ -0,0 +1,192 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans of this module
+const instrumentationName = "github.com/FIAP-SOAT-G20/hackathon-video-processor-job"
+
+const (
+	// ExporterNone records spans, so logs and messages carry trace IDs, without exporting them
+	ExporterNone = "none"
+	// ExporterOTLP sends spans to an OTLP/HTTP collector
+	ExporterOTLP = "otlp"
+	// ExporterStdout writes spans as JSON on stdout, next to the logs
+	ExporterStdout = "stdout"
+	// ExporterFile writes spans as JSON to a file
+	ExporterFile = "file"
+)
+
+// TraceParentAttribute is the message attribute carrying the W3C trace context of a published event
+const TraceParentAttribute = "traceparent"
+
+// propagator reads and writes W3C trace context headers
+var propagator = propagation.TraceContext{}
+
+// Config selects where spans are exported
+type Config struct {
+	// Exporter is ExporterNone (default), ExporterOTLP, ExporterStdout or ExporterFile
+	Exporter string
+	// Endpoint is the base URL of the OTLP/HTTP collector, e.g. http://collector:4318; empty uses the OTLP exporter
+	// defaults
+	Endpoint string
+	// File is the path spans are appended to with ExporterFile
+	File string
+	// Writer receives the spans of ExporterStdout; nil is os.Stdout
+	Writer         io.Writer
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Provider is the tracer provider installed by Setup
+type Provider struct {
+	provider *sdktrace.TracerProvider
+	// closer releases the trace file, if any
+	closer io.Closer
+}
+
+// Setup installs the global tracer provider and the W3C trace context propagator
+func Setup(ctx context.Context, config Config) (*Provider, error) {
+	exporter, closer, err := newExporter(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName(config.ServiceName),
+			semconv.ServiceVersion(config.ServiceVersion),
+		)),
+	}
+	if exporter != nil {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	provider := sdktrace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+	return &Provider{provider: provider, closer: closer}, nil
+}
+
+// Flush exports the ended spans, e.g. before a Lambda environment is frozen
+func (p *Provider) Flush(ctx context.Context) error {
+	return p.provider.ForceFlush(ctx)
+}
+
+// Shutdown exports the pending spans and releases the exporter
+func (p *Provider) Shutdown(ctx context.Context) error {
+	err := p.provider.Shutdown(ctx)
+	if p.closer != nil {
+		err = errors.Join(err, p.closer.Close())
+	}
+	return err
+}
+
+// newExporter creates the span exporter; the closer, if any, is released after the exporter is shut down
+func newExporter(ctx context.Context, config Config) (sdktrace.SpanExporter, io.Closer, error) {
+	switch config.Exporter {
+	case "", ExporterNone:
+		return nil, nil, nil
+	case ExporterOTLP:
+		var options []otlptracehttp.Option
+		if config.Endpoint != "" {
+			// Like OTEL_EXPORTER_OTLP_ENDPOINT, the endpoint is the base URL of the collector
+			options = append(options, otlptracehttp.WithEndpointURL(strings.TrimSuffix(config.Endpoint, "/")+"/v1/traces"))
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+		}
+		return exporter, nil, nil
+	case ExporterStdout:
+		writer := config.Writer
+		if writer == nil {
+			writer = os.Stdout
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(writer))
+		return exporter, nil, err
+	case ExporterFile:
+		f, err := os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, nil, err
+		}
+		return exporter, f, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported trace exporter %q", config.Exporter)
+	}
+}
+
+// Start starts a span named name, child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otelTracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// otelTracer returns the tracer of the global provider, so spans follow the provider installed by Setup
+func otelTracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// End records err, if any, on span and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// WithTraceParent returns ctx continuing the remote trace of a W3C traceparent value; an empty or malformed value
+// leaves ctx unchanged
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	if traceParent == "" {
+		return ctx
+	}
+	return propagator.Extract(ctx, propagation.MapCarrier{TraceParentAttribute: traceParent})
+}
+
+// TraceID returns the trace ID of the span in ctx, empty when there is none
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+	return spanContext.TraceID().String()
+}
+
+// injectAttributes returns a copy of attributes carrying the trace context of ctx
+func injectAttributes(ctx context.Context, attributes map[string]string) map[string]string {
+	carrier := propagation.MapCarrier{}
+	propagator.Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return attributes
+	}
+	out := make(map[string]string, len(attributes)+len(carrier))
+	for name, value := range attributes {
+		out[name] = value
+	}
+	for name, value := range carrier {
+		out[name] = value
+	}
+	return out
+}
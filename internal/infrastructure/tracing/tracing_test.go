@@ -0,0 +1,154 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+
+	"github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/domain/entity"
+	pmocks "github.com/FIAP-SOAT-G20/hackathon-video-processor-job/internal/core/port/mocks"
+)
+
+const (
+	upstreamTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	upstreamParent  = "00-" + upstreamTraceID + "-00f067aa0ba902b7-01"
+)
+
+// recordSpans installs a provider recording the ended spans for the duration of the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestWithTraceParent(t *testing.T) {
+	t.Run("continues_the_upstream_trace", func(t *testing.T) {
+		r := require.New(t)
+		recorder := recordSpans(t)
+
+		ctx, span := Start(WithTraceParent(context.Background(), upstreamParent), "process")
+		span.End()
+
+		r.Equal(upstreamTraceID, TraceID(ctx))
+		r.Len(recorder.Ended(), 1)
+		r.Equal("00f067aa0ba902b7", recorder.Ended()[0].Parent().SpanID().String())
+		r.True(recorder.Ended()[0].Parent().IsRemote())
+	})
+
+	t.Run("ignores_a_malformed_value", func(t *testing.T) {
+		r := require.New(t)
+		recordSpans(t)
+
+		r.Empty(TraceID(WithTraceParent(context.Background(), "not-a-traceparent")))
+		ctx, span := Start(WithTraceParent(context.Background(), ""), "process")
+		defer span.End()
+		r.Len(TraceID(ctx), 32, "a new trace is started")
+	})
+}
+
+func TestTraceMessageBroker(t *testing.T) {
+	t.Run("injects_the_trace_context_into_the_attributes", func(t *testing.T) {
+		r := require.New(t)
+		recorder := recordSpans(t)
+		ctrl := gomock.NewController(t)
+		broker := pmocks.NewMockMessageBroker(ctrl)
+
+		var published entity.Message
+		broker.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, m entity.Message) error {
+			published = m
+			return nil
+		})
+
+		ctx, span := Start(WithTraceParent(context.Background(), upstreamParent), "process")
+		message := entity.Message{ID: "m-1", Attributes: map[string]string{"event_type": "video.status"}}
+		r.NoError(TraceMessageBroker(broker, "sns").PublishMessage(ctx, message))
+		span.End()
+
+		r.Equal("video.status", published.Attributes["event_type"])
+		r.Empty(message.Attributes[TraceParentAttribute], "the caller's attributes are not modified")
+		r.Len(recorder.Ended(), 2)
+		producer := recorder.Ended()[0]
+		r.Equal("publish sns", producer.Name())
+		r.Equal(trace.SpanKindProducer, producer.SpanKind())
+		r.Equal("00-"+upstreamTraceID+"-"+producer.SpanContext().SpanID().String()+"-01", published.Attributes[TraceParentAttribute])
+	})
+
+	t.Run("continues_the_trace_of_a_replayed_message", func(t *testing.T) {
+		r := require.New(t)
+		recorder := recordSpans(t)
+		ctrl := gomock.NewController(t)
+		broker := pmocks.NewMockMessageBroker(ctrl)
+
+		var published entity.Message
+		broker.EXPECT().PublishMessage(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, m entity.Message) error {
+			published = m
+			return errors.New("broker down")
+		})
+
+		message := entity.Message{ID: "m-1", Attributes: map[string]string{TraceParentAttribute: upstreamParent}}
+		r.Error(TraceMessageBroker(broker, "sqs").PublishMessage(context.Background(), message))
+
+		r.Len(recorder.Ended(), 1)
+		producer := recorder.Ended()[0]
+		r.Equal(upstreamTraceID, producer.SpanContext().TraceID().String())
+		r.Equal("00f067aa0ba902b7", producer.Parent().SpanID().String())
+		r.Equal(codes.Error, producer.Status().Code)
+		r.Contains(published.Attributes[TraceParentAttribute], producer.SpanContext().SpanID().String())
+	})
+}
+
+func TestTraceStorageDataSource(t *testing.T) {
+	r := require.New(t)
+	recorder := recordSpans(t)
+	ctrl := gomock.NewController(t)
+	storage := pmocks.NewMockStorageDataSource(ctrl)
+	storage.EXPECT().StatVideo(gomock.Any(), "uploads/a.mp4").Return(nil, errors.New("access denied"))
+
+	_, err := TraceStorageDataSource(storage).StatVideo(context.Background(), "uploads/a.mp4")
+	r.Error(err)
+
+	r.Len(recorder.Ended(), 1)
+	span := recorder.Ended()[0]
+	r.Equal("storage.StatVideo", span.Name())
+	r.Equal(trace.SpanKindClient, span.SpanKind())
+	r.Equal(codes.Error, span.Status().Code)
+	r.Contains(span.Attributes(), attribute.String("storage.key", "uploads/a.mp4"))
+}
+
+func TestSetup(t *testing.T) {
+	t.Run("file_exporter_writes_the_spans", func(t *testing.T) {
+		r := require.New(t)
+		previous := otel.GetTracerProvider()
+		t.Cleanup(func() { otel.SetTracerProvider(previous) })
+		path := filepath.Join(t.TempDir(), "spans.jsonl")
+
+		provider, err := Setup(context.Background(), Config{Exporter: ExporterFile, File: path, ServiceName: "video-processor-job"})
+		r.NoError(err)
+		_, span := Start(context.Background(), "process")
+		span.End()
+		r.NoError(provider.Shutdown(context.Background()))
+
+		data, err := os.ReadFile(path)
+		r.NoError(err)
+		r.Contains(string(data), `"Name":"process"`)
+		r.Contains(string(data), "video-processor-job")
+	})
+
+	t.Run("rejects_an_unknown_exporter", func(t *testing.T) {
+		_, err := Setup(context.Background(), Config{Exporter: "jaeger"})
+		require.ErrorContains(t, err, `unsupported trace exporter "jaeger"`)
+	})
+}